@@ -0,0 +1,175 @@
+// Package canvas sends quiz scores to an assignment in a Canvas course
+// with the Canvas REST API, so they don't have to be typed into the
+// gradebook by hand.
+package canvas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// Client posts grades to one assignment of a Canvas course.
+type Client struct {
+	URL          string //Base URL of the Canvas instance, e.g. "https://school.instructure.com"
+	Token        string //API access token of a teacher in the course
+	CourseID     string //ID of the course
+	AssignmentID string //ID of the assignment the grades are for
+	client       *http.Client
+}
+
+// NewClient creates a client for the assignment of a course on the Canvas
+// instance at baseURL.
+func NewClient(baseURL, token, courseID, assignmentID string) *Client {
+	return &Client{
+		URL:          strings.TrimSuffix(baseURL, "/"),
+		Token:        token,
+		CourseID:     courseID,
+		AssignmentID: assignmentID,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// User is a user enrolled in the course.
+type User struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	SortableName string `json:"sortable_name"`
+	LoginID      string `json:"login_id"`
+}
+
+// FindUser finds the student in the course called name.  Canvas matches
+// the name, login ID or email, so the one with exactly that name is picked
+// when there are several.
+func (c *Client) FindUser(name string) (User, error) {
+	query := url.Values{"search_term": {name}, "enrollment_type[]": {"student"}, "per_page": {"50"}}
+	var users []User
+	if err := c.do(http.MethodGet, "/api/v1/courses/"+url.PathEscape(c.CourseID)+"/users?"+query.Encode(), nil, &users); err != nil {
+		return User{}, err
+	}
+
+	var matches []User
+	for _, u := range users {
+		if strings.EqualFold(u.Name, name) || strings.EqualFold(u.LoginID, name) {
+			matches = append(matches, u)
+		}
+	}
+	if len(matches) == 0 && len(users) == 1 {
+		matches = users
+	}
+	switch len(matches) {
+	case 0:
+		return User{}, fmt.Errorf("there is no student called %q in the course", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return User{}, fmt.Errorf("there are %v students called %q in the course", len(matches), name)
+	}
+}
+
+// PostGrade sets the grade of the user with the Canvas ID userID to
+// percent of the assignment's points, with a comment if it isn't empty.
+// userID can also be an SIS ID, e.g. "sis_login_id:jsmith".
+func (c *Client) PostGrade(userID string, percent float64, comment string) error {
+	form := url.Values{"submission[posted_grade]": {fmt.Sprintf("%.2f%%", percent)}}
+	if comment != "" {
+		form.Set("comment[text_comment]", comment)
+	}
+	path := fmt.Sprintf("/api/v1/courses/%s/assignments/%s/submissions/%s",
+		url.PathEscape(c.CourseID), url.PathEscape(c.AssignmentID), url.PathEscape(userID))
+	return c.do(http.MethodPut, path, form, nil)
+}
+
+// do sends a request to the API, with form as the body if it isn't nil,
+// and decodes the JSON response into v if it isn't nil.
+func (c *Client) do(method, path string, form url.Values, v any) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequest(method, c.URL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("canvas returned %s: %s", resp.Status, errorMessage(resp.Body))
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// errorMessage returns the messages of a Canvas error response, or the
+// body itself if it isn't one.
+func errorMessage(r io.Reader) string {
+	body, _ := io.ReadAll(io.LimitReader(r, 4096))
+	var e struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &e) == nil && len(e.Errors) > 0 {
+		var msgs []string
+		for _, m := range e.Errors {
+			msgs = append(msgs, m.Message)
+		}
+		return strings.Join(msgs, "; ")
+	}
+	return string(bytes.TrimSpace(body))
+}
+
+// Record sends the score of a to c when the test finishes.  The student
+// is found in the course by the name they gave, unless userID is given.
+// Only the first attempt is sent, so playing the test again doesn't
+// overwrite the grade.
+func Record(a *quiz.Assessment, c *Client, userID string) {
+	a.OnSubmit(func(a *quiz.Assessment) {
+		if a.TotalCorrect+a.TotalIncorrect == 0 {
+			return
+		}
+		if err := push(a, c, userID); err != nil {
+			fmt.Fprintln(a.Out, "Unable to send your grade to Canvas:", err)
+			return
+		}
+		fmt.Fprintln(a.Out, "Your grade has been sent to Canvas.")
+	})
+}
+
+// push sends the score of a finished test to Canvas.
+func push(a *quiz.Assessment, c *Client, userID string) error {
+	if userID == "" {
+		if a.Name == "" {
+			return errors.New("you didn't give your name")
+		}
+		u, err := c.FindUser(a.Name)
+		if err != nil {
+			return err
+		}
+		userID = fmt.Sprint(u.ID)
+	}
+	score, err := a.Score()
+	if err != nil {
+		return err
+	}
+	comment := fmt.Sprintf("%v of %v questions correct", a.TotalCorrect, a.Total())
+	return c.PostGrade(userID, score, comment)
+}
@@ -0,0 +1,112 @@
+// Package certificate makes completion certificates for people who pass a
+// quiz, as HTML pages to print or PDF files to keep with training records.
+package certificate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Certificate is the record that someone passed a quiz.
+type Certificate struct {
+	Name  string    //Name of the person who passed
+	Title string    //Title of the quiz or exam
+	Score float64   //Percentage score
+	Pass  float64   //Percentage needed to pass, 0 if there is no pass mark
+	Date  time.Time //When the quiz was passed
+	Logo  string    //PNG, JPEG or GIF image shown at the top, empty for none
+}
+
+// Write writes the certificate to path, as PDF for a .pdf file or else
+// as HTML.
+func (c Certificate) Write(path string) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	if strings.EqualFold(filepath.Ext(path), ".pdf") {
+		return c.WritePDF(file)
+	}
+	return c.WriteHTML(file)
+}
+
+// page is the HTML certificate, laid out to print on one landscape page.
+var page = template.Must(template.New("certificate").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Certificate of Completion - {{.Name}}</title>
+<style>
+@page { size: A4 landscape; margin: 0; }
+body { margin: 0; font-family: Helvetica, Arial, sans-serif; color: #222; }
+.certificate { box-sizing: border-box; width: 297mm; height: 210mm; padding: 20mm; text-align: center; border: 3mm double #2c4a7a; }
+.logo { max-height: 30mm; max-width: 80mm; }
+h1 { font-size: 34pt; margin: 8mm 0; color: #2c4a7a; }
+.name { font-size: 28pt; font-weight: bold; margin: 6mm 0; }
+.title { font-size: 20pt; font-style: italic; margin: 6mm 0; }
+p { font-size: 14pt; margin: 4mm 0; }
+</style>
+</head>
+<body>
+<div class="certificate">
+{{if .Logo}}<img class="logo" src="{{.Logo}}" alt="">{{end}}
+<h1>Certificate of Completion</h1>
+<p>This certifies that</p>
+<div class="name">{{.Name}}</div>
+<p>has completed</p>
+<div class="title">{{.Title}}</div>
+<p>{{.Result}}</p>
+<p>{{.Date}}</p>
+</div>
+</body>
+</html>
+`))
+
+// WriteHTML writes the certificate as an HTML page, with the logo in it
+// so the page stands on its own.
+func (c Certificate) WriteHTML(w io.Writer) error {
+	var logo template.URL
+	if c.Logo != "" {
+		data, err := os.ReadFile(c.Logo)
+		if err != nil {
+			return err
+		}
+		logo = template.URL("data:" + http.DetectContentType(data) + ";base64," + base64.StdEncoding.EncodeToString(data))
+	}
+	var buf bytes.Buffer
+	err := page.Execute(&buf, struct {
+		Name, Title, Result, Date string
+		Logo                      template.URL
+	}{c.Name, c.Title, c.result(), c.date(), logo})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// result returns the line giving the score.
+func (c Certificate) result() string {
+	if c.Pass > 0 {
+		return fmt.Sprintf("with a score of %.0f%%, passing the mark of %.0f%%", c.Score, c.Pass)
+	}
+	return fmt.Sprintf("with a score of %.0f%%", c.Score)
+}
+
+// date returns the date the quiz was passed, in words.
+func (c Certificate) date() string {
+	return c.Date.Format("2 January 2006")
+}
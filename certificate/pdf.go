@@ -0,0 +1,188 @@
+package certificate
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"strings"
+)
+
+// The page is A4 landscape, in points.
+const (
+	pageWidth  = 842
+	pageHeight = 595
+)
+
+// helvetica is the width of each character from ' ' to '~' in Helvetica,
+// in thousandths of the font size, for centring the text.
+var helvetica = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// The fonts on the page, by their resource names.
+const (
+	regular = "F1" //Helvetica
+	bold    = "F2" //Helvetica-Bold
+	italic  = "F3" //Helvetica-Oblique
+)
+
+// WritePDF writes the certificate as a one page PDF file.  The text is
+// in the standard Helvetica fonts, so characters outside Latin-1 are
+// shown as question marks.
+func (c Certificate) WritePDF(w io.Writer) error {
+	var logo *pdfImage
+	if c.Logo != "" {
+		var err error
+		if logo, err = loadImage(c.Logo); err != nil {
+			return err
+		}
+	}
+
+	var page bytes.Buffer
+	// A double border
+	fmt.Fprintf(&page, "0.17 0.29 0.48 RG 4 w 20 20 %d %d re S 1 w 30 30 %d %d re S\n", pageWidth-40, pageHeight-40, pageWidth-60, pageHeight-60)
+	if logo != nil {
+		// Scaled to fit a box at the top of the page
+		width, height := float64(logo.width), float64(logo.height)
+		scale := min(180/width, 70/height)
+		width, height = width*scale, height*scale
+		fmt.Fprintf(&page, "q %.2f 0 0 %.2f %.2f %.2f cm /Logo Do Q\n", width, height, (pageWidth-width)/2, 480+(70-height)/2)
+	}
+	centre(&page, bold, 34, 425, "0.17 0.29 0.48", "Certificate of Completion")
+	centre(&page, regular, 14, 380, "0.13 0.13 0.13", "This certifies that")
+	centre(&page, bold, 28, 335, "0.13 0.13 0.13", c.Name)
+	centre(&page, regular, 14, 295, "0.13 0.13 0.13", "has completed")
+	centre(&page, italic, 20, 255, "0.13 0.13 0.13", c.Title)
+	centre(&page, regular, 14, 205, "0.13 0.13 0.13", c.result())
+	centre(&page, regular, 14, 175, "0.13 0.13 0.13", c.date())
+
+	resources := fmt.Sprintf("/Font << /%s 4 0 R /%s 5 0 R /%s 6 0 R >>", regular, bold, italic)
+	if logo != nil {
+		resources += " /XObject << /Logo 8 0 R >>"
+	}
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << %s >> /Contents 7 0 R >>", pageWidth, pageHeight, resources),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Oblique /Encoding /WinAnsiEncoding >>",
+		stream(fmt.Sprintf("<< /Length %d >>", page.Len()), page.Bytes()),
+	}
+	if logo != nil {
+		objects = append(objects, stream(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>", logo.width, logo.height, len(logo.data)), logo.data))
+	}
+
+	// The cross reference table gives the offset of each object in the file
+	var file bytes.Buffer
+	file.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = file.Len()
+		fmt.Fprintf(&file, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := file.Len()
+	fmt.Fprintf(&file, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&file, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&file, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// centre writes the commands that show text centred across the page at
+// height y, in font at size and the RGB colour.
+func centre(page *bytes.Buffer, font string, size, y float64, colour, text string) {
+	encoded := winAnsi(text)
+	width := 0
+	for _, b := range encoded {
+		if b >= ' ' && int(b-' ') < len(helvetica) {
+			width += helvetica[b-' ']
+		} else {
+			width += 556
+		}
+	}
+	// Bold characters are a little wider than the regular ones measured
+	if font == bold {
+		width = width * 105 / 100
+	}
+	x := (pageWidth - float64(width)*size/1000) / 2
+	fmt.Fprintf(page, "%s rg BT /%s %.0f Tf %.2f %.2f Td (%s) Tj ET\n", colour, font, size, x, y, escape(encoded))
+}
+
+// winAnsi encodes text in the fonts' WinAnsi encoding, with a question
+// mark for each character it doesn't have.
+func winAnsi(text string) []byte {
+	var b []byte
+	for _, r := range text {
+		switch {
+		case r >= ' ' && r <= '~', r >= 0xA0 && r <= 0xFF:
+			b = append(b, byte(r))
+		default:
+			b = append(b, '?')
+		}
+	}
+	return b
+}
+
+// escape escapes the characters with special meanings in PDF strings.
+func escape(b []byte) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(string(b))
+}
+
+// stream returns a stream object with the dictionary dict.
+func stream(dict string, data []byte) string {
+	return fmt.Sprintf("%s\nstream\n%s\nendstream", dict, data)
+}
+
+// pdfImage is an image as RGB samples compressed for a PDF.
+type pdfImage struct {
+	width, height int
+	data          []byte
+}
+
+// loadImage reads the image at path for a PDF, with any transparent parts
+// on white.
+func loadImage(path string) (*pdfImage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	bounds := img.Bounds()
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	row := make([]byte, 0, bounds.Dx()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row = row[:0]
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			white := 0xffff - a
+			row = append(row, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+		if _, err = zw.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+	return &pdfImage{width: bounds.Dx(), height: bounds.Dy(), data: buf.Bytes()}, nil
+}
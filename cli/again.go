@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// againAnswers are what can be answered to "Play again?", for each way of
+// playing again, with "" to quit.
+var againAnswers = map[string]string{
+	"same": quiz.ReplaySame, "s": quiz.ReplaySame,
+	"reshuffled": quiz.ReplayShuffled, "r": quiz.ReplayShuffled,
+	"missed": quiz.ReplayMissed, "m": quiz.ReplayMissed,
+	"quit": "", "q": "", "": "",
+}
+
+// playRounds runs test, then asks whether to play it again with the same
+// questions, reshuffled or only the ones missed in the last round, and runs
+// each round asked for until the player quits.  The player's name carries over and the
+// total over the rounds is shown after each one.
+func playRounds(ctx context.Context, test *quiz.Assessment) error {
+	in := test.In
+	if in == nil {
+		in = os.Stdin
+	}
+	input := quiz.NewInput(in)
+	defer input.Close()
+	quiz.WithSharedInput(input)(test)
+	out := test.Out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	rounds, correct, asked, best := 0, 0, 0, 0.0
+	for round := test; ; {
+		// Stopping the test with Ctrl+C or a command ends the rounds too
+		if err := round.StartTest(ctx); err != nil && !errors.Is(err, quiz.ErrTimeExpired) {
+			return err
+		}
+		score, _ := round.Score()
+		rounds, correct, asked, best = rounds+1, correct+round.TotalCorrect, asked+round.TotalCorrect+round.TotalIncorrect, max(best, score)
+		if rounds > 1 {
+			fmt.Fprintf(out, "Over %v rounds you got %v of %v questions right. Your best round was %.2f%%.\n", rounds, correct, asked, best)
+		}
+
+		for {
+			how, err := askPlayAgain(ctx, input, out)
+			if err != nil || how == "" {
+				return err
+			}
+			// The missed questions are the last round's, but playing the
+			// same questions again is all of them after a round of missed ones
+			from := round
+			if how != quiz.ReplayMissed {
+				from = test
+			}
+			next, err := from.Replay(how)
+			if errors.Is(err, quiz.ErrNoQuestions) {
+				fmt.Fprintln(out, "You didn't miss any questions.")
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			round = next
+			break
+		}
+	}
+}
+
+// askPlayAgain asks whether to play again, and how, until it gets an
+// answer it knows.  It returns "" to quit, including at the end of the
+// input.
+func askPlayAgain(ctx context.Context, input *quiz.Input, out io.Writer) (string, error) {
+	fmt.Fprint(out, "\nPlay again? [same questions / reshuffled / missed only / quit] ")
+	for {
+		answer, err := input.ReadLine(ctx)
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(out)
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		word, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(answer)), " ")
+		if how, ok := againAnswers[word]; ok {
+			return how, nil
+		}
+		fmt.Fprint(out, "Please answer same, reshuffled, missed or quit: ")
+	}
+}
@@ -0,0 +1,318 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/llm"
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/quiz"
+	"github.com/rastewart/go-quiz-game/results"
+)
+
+// autograde grades a student's answers file against a question bank
+// without asking anything, for GitHub Classroom and other CI graders.  It
+// fails when the score is below the pass mark, so the exit code can be
+// the grade.
+func autograde(ctx context.Context, args []string) (err error) {
+	flags := newFlagSet("autograde")
+	junit := flags.String("junit", "", "File to write the results to as JUnit XML, one test case for each question")
+	jsonPath := flags.String("json", "", "File to write the results to as JSON for GitHub Classroom's autograding reporter")
+	pass := flags.Float64("pass", 100, "Score in percent needed to pass. A lower score exits with an error.")
+	points := flags.Int("points", 1, "Points each correct answer is worth in the JSON results")
+	showAnswers := flags.Bool("showanswers", false, "Show the correct answers to the questions that were got wrong")
+	template := flags.String("template", "", "Write a blank answers file for the bank to this file instead of grading")
+	ordered := flags.Bool("ordered", false, "Read the answers file as one answer on each line, in the order of the questions,\ninstead of \"<question number>: <answer>\" on each line")
+	opts := &Options{}
+	flags.StringVar(&opts.Name, "name", "", "Name of the participant the answers are from, for the -results")
+	flags.StringVar(&opts.ResultsFile, "results", "", "File to save the result in, with the results of tests played, for \"quiz results\" and \"quiz stats\"")
+	flags.StringVar(&opts.Script, "script", "", "A Starlark script whose grade() function decides whether the answers are correct\nand whose score() function calculates the score")
+	flags.BoolVar(&opts.LLMGrade, "llmgrade", false, "Ask a large language model whether free answers mean the same as the correct answer")
+	flags.StringVar(&opts.LLMCache, "llmcache", llm.DefaultCachePath(), "File to cache the model's grades in, so the same answer is only graded once")
+	opts.llmFlags(flags)
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *template != "" {
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return errors.New("autograde -template needs the question bank to read")
+		}
+		return writeAnswersTemplate(*template, flags.Arg(0))
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errors.New("autograde needs the question bank and the answers file")
+	}
+	bank, answersPath := flags.Arg(0), flags.Arg(1)
+
+	// The answers are graded like answers in a test of the whole bank, in
+	// its order, so the question numbers match the -template
+	opts.FilePath, opts.OnDuplicate = bank, ""
+	test, err := opts.newAssessment(ctx)
+	if err != nil {
+		return err
+	}
+	if test.Source != nil {
+		return errors.New("autograde grades the questions in the bank, so the -script can't generate() them")
+	}
+	questions := test.Questions
+	answers, err := readAnswers(answersPath, questions, *ordered)
+	if err != nil {
+		return err
+	}
+
+	test.Name, test.TimeStart = opts.Name, time.Now()
+	cases := make([]gradedAnswer, len(questions))
+	for i := range questions {
+		q := &questions[i]
+		answer, answered := answers[i]
+		if answered {
+			if err = test.Grade(ctx, q, answer); err != nil {
+				return err
+			}
+		}
+		g := gradedAnswer{Name: fmt.Sprintf("%v. %s", i+1, q.QText), Correct: answered && q.Correct}
+		switch {
+		case g.Correct:
+			test.TotalCorrect++
+		case !answered:
+			test.TotalIncorrect++
+			g.Message = "not answered"
+		default:
+			test.TotalIncorrect++
+			g.Message = fmt.Sprintf("answered %q", answer)
+		}
+		if !g.Correct && *showAnswers {
+			g.Message += fmt.Sprintf(", the answer is %q", q.Answer)
+		}
+		cases[i] = g
+		status := "PASS"
+		if !g.Correct {
+			status = "FAIL: " + g.Message
+		}
+		fmt.Printf("%s %s\n", g.Name, status)
+	}
+	score, err := test.Score()
+	if err != nil {
+		return err
+	}
+	passed := score >= *pass
+	fmt.Printf("%v of %v questions correct, a score of %.2f%%\n", test.TotalCorrect, len(questions), score)
+	if opts.ResultsFile != "" {
+		if err = results.Append(opts.ResultsFile, results.FromAssessment(test)); err != nil {
+			return err
+		}
+	}
+
+	suite := filepath.Base(bank)
+	if *junit != "" {
+		if err = writeJUnit(*junit, suite, cases); err != nil {
+			return err
+		}
+	}
+	if *jsonPath != "" {
+		if err = writeClassroomJSON(*jsonPath, cases, *points, passed); err != nil {
+			return err
+		}
+	}
+	if !passed {
+		return fmt.Errorf("the score of %.2f%% is below the pass mark of %.2f%%", score, *pass)
+	}
+	return nil
+}
+
+// gradedAnswer is the grade of the answer to one question.
+type gradedAnswer struct {
+	Name    string //The question's number and text
+	Correct bool
+	Message string //Why the answer is wrong
+}
+
+// readAnswers reads an answers file, returning the answers by the index of
+// their question.  Each line is "<key>: <answer>", where the key is the
+// question's number or ID, and blank lines are skipped.  With ordered there
+// is one answer on each line instead, in the order of the questions, and a
+// blank line leaves the question unanswered.  Either way lines starting
+// with # are skipped, and a line with no answer after the key leaves the
+// question unanswered.
+func readAnswers(path string, questions []quiz.Question, ordered bool) (map[int]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	byID := make(map[string]int, len(questions))
+	for i := range questions {
+		byID[questions[i].StableID()] = i
+	}
+	question := func(key string) (int, bool) {
+		if i, ok := byID[key]; ok {
+			return i, true
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(key, "."))
+		return n - 1, err == nil && n >= 1 && n <= len(questions)
+	}
+
+	answers := make(map[int]string)
+	i := 0
+	for n, text := range lines {
+		line := n + 1
+		text = strings.TrimSpace(text)
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+		if ordered {
+			// One answer to each question in turn
+			if i >= len(questions) {
+				if text != "" {
+					return nil, fmt.Errorf("%s:%v: there are more answers than the %v questions", path, line, len(questions))
+				}
+				continue
+			}
+			if text != "" {
+				answers[i] = text
+			}
+			i++
+			continue
+		}
+
+		if text == "" {
+			continue
+		}
+		key, answer, ok := strings.Cut(text, ":")
+		if !ok {
+			return nil, fmt.Errorf("%s:%v: %q isn't \"<question number>: <answer>\", give -ordered for one answer on each line", path, line, text)
+		}
+		i, found := question(strings.TrimSpace(key))
+		if !found {
+			return nil, fmt.Errorf("%s:%v: there is no question %q", path, line, strings.TrimSpace(key))
+		}
+		if _, dup := answers[i]; dup {
+			return nil, fmt.Errorf("%s:%v: question %v is answered twice", path, line, i+1)
+		}
+		if answer = strings.TrimSpace(answer); answer != "" {
+			answers[i] = answer
+		}
+	}
+	return answers, nil
+}
+
+// writeAnswersTemplate writes an answers file for the questions in bank,
+// with each question and its choices in a comment above its blank answer.
+func writeAnswersTemplate(path, bank string) (err error) {
+	questions, err := loader.Load(bank)
+	if err != nil {
+		return err
+	}
+	if len(questions) == 0 {
+		return fmt.Errorf("%w in %s", quiz.ErrNoQuestions, bank)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Answers for %s.  Write each answer after the question's number.\n", filepath.Base(bank))
+	for i := range questions {
+		q := &questions[i]
+		fmt.Fprintf(&b, "\n# %v. %s\n", i+1, q.QText)
+		if options := q.Options(); options != nil {
+			// The answer is first in the options, so they're sorted to hide it
+			options = slices.Clone(options)
+			slices.Sort(options)
+			fmt.Fprintf(&b, "# Choose from: %s\n", strings.Join(options, " | "))
+		}
+		fmt.Fprintf(&b, "%v: \n", i+1)
+	}
+	if err = os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote an answers file for the %v questions in %s to %s\n", len(questions), bank, path)
+	return nil
+}
+
+// junitSuites is the JUnit XML report CI systems read test results from.
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+// writeJUnit writes the graded answers to path as a JUnit XML test suite.
+func writeJUnit(path, suite string, cases []gradedAnswer) error {
+	s := junitSuite{Name: suite, Tests: len(cases)}
+	for _, g := range cases {
+		c := junitCase{Name: g.Name, ClassName: suite}
+		if !g.Correct {
+			c.Failure = &junitFailure{Message: g.Message}
+			s.Failures++
+		}
+		s.Cases = append(s.Cases, c)
+	}
+	data, err := xml.MarshalIndent(junitSuites{Suites: []junitSuite{s}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), append(data, '\n')...), 0644)
+}
+
+// classroomResult is the result format of GitHub Classroom's autograding
+// reporter.
+type classroomResult struct {
+	Version  int             `json:"version"`
+	Status   string          `json:"status"`
+	MaxScore int             `json:"max_score"`
+	Tests    []classroomTest `json:"tests"`
+}
+
+type classroomTest struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Score   int    `json:"score"`
+	Message string `json:"message,omitempty"`
+}
+
+// writeClassroomJSON writes the graded answers to path for GitHub
+// Classroom, with points for each correct answer.
+func writeClassroomJSON(path string, cases []gradedAnswer, points int, passed bool) error {
+	r := classroomResult{Version: 1, Status: "fail", MaxScore: points * len(cases)}
+	if passed {
+		r.Status = "pass"
+	}
+	for _, g := range cases {
+		t := classroomTest{Name: g.Name, Status: "fail", Message: g.Message}
+		if g.Correct {
+			t.Status, t.Score = "pass", points
+		}
+		r.Tests = append(r.Tests, t)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
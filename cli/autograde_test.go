@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+func TestReadAnswers(t *testing.T) {
+	questions := []quiz.Question{
+		{QText: "When does the shop open?", Answer: "3:00"},
+		{QText: "5+5", Answer: "10"},
+		{QText: "Capital of France?", Answer: "Paris"},
+	}
+	id := questions[2].StableID()
+
+	tests := []struct {
+		name    string
+		ordered bool
+		file    string
+		want    map[int]string
+		wantErr string
+	}{
+		{name: "keyed", file: "# Answers\n\n1: 3:00\n2: 10\n3: Paris\n", want: map[int]string{0: "3:00", 1: "10", 2: "Paris"}},
+		{name: "keyed in any order", file: "3. : Paris\r\n1: 3:00\r\n", want: map[int]string{0: "3:00", 2: "Paris"}},
+		{name: "keyed by ID", file: id + ": Paris\n", want: map[int]string{2: "Paris"}},
+		{name: "keyed with a blank answer", file: "1: \n2: 10\n", want: map[int]string{1: "10"}},
+		{name: "keyed without a key", file: "3:00\n10\nParis\n", wantErr: "sam.txt:2: \"10\" isn't \"<question number>: <answer>\", give -ordered"},
+		{name: "keyed with an unknown key", file: "4: Rome\n", wantErr: "sam.txt:1: there is no question \"4\""},
+		{name: "keyed twice", file: "2: 10\n2: 11\n", wantErr: "sam.txt:2: question 2 is answered twice"},
+		{name: "ordered", ordered: true, file: "# Sam's answers\n3:00\n10\nParis\n", want: map[int]string{0: "3:00", 1: "10", 2: "Paris"}},
+		{name: "ordered that look keyed", ordered: true, file: "2: 10\n3: Paris\n", want: map[int]string{0: "2: 10", 1: "3: Paris"}},
+		{name: "ordered with a blank line", ordered: true, file: "3:00\n\nParis\n\n", want: map[int]string{0: "3:00", 2: "Paris"}},
+		{name: "ordered with too many", ordered: true, file: "3:00\n10\nParis\nRome\n", wantErr: "sam.txt:4: there are more answers than the 3 questions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "sam.txt")
+			if err := os.WriteFile(path, []byte(tt.file), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readAnswers(path, questions, tt.ordered)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readAnswers() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readAnswers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
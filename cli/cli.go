@@ -0,0 +1,321 @@
+// Package cli is the command line interface for the quiz game.  The quiz
+// is split into subcommands, e.g. "quiz play" or "quiz serve", each with
+// its own flags.  Running quiz without a subcommand plays the quiz.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/llm"
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/lti"
+	"github.com/rastewart/go-quiz-game/quiz"
+	"github.com/rastewart/go-quiz-game/results"
+	"github.com/rastewart/go-quiz-game/script"
+)
+
+// Options are the command line options: the settings for the test and
+// the options that choose how the quiz is run.  Each subcommand only
+// has flags for the options it uses.
+type Options struct {
+	quiz.Config                   //Settings for the test
+	TelegramToken   string        //Telegram bot token. When set the quiz is run as a Telegram bot
+	TelegramWindow  time.Duration //How long each question stays open in Telegram chats
+	TeamPool        string        //How the answers of a team's members are pooled, "first" or "majority"
+	SSHAddr         string        //Address for the ssh server. When set the quiz is served over ssh
+	SSHHostKey      string        //Path to the ssh server's host key
+	SSHMaxSessions  int           //Most ssh sessions played at once
+	SSHIdleTimeout  time.Duration //How long an ssh player can go without typing before they are disconnected
+	LeaderboardAddr string        //Address for the leaderboard server. When set the leaderboard server is run
+	LeaderboardFile string        //JSON file the leaderboard server saves scores in
+	ObserveAddr     string        //Address for the ssh server's observer view
+	ObserveToken    string        //Token required to see the observer view
+	MetricsAddr     string        //Address for the Prometheus metrics of the servers
+	LTIAddr         string        //Address for the LTI 1.3 tool. When set the quiz can be launched from an LMS
+	LTIURL          string        //Public URL of the LTI tool
+	LTIKey          string        //Path to the LTI tool's private key
+	LTIPlatform     lti.Platform  //The LMS that launches the LTI tool
+	TournamentFile  string        //File the tournament bracket is saved in. When set a tournament is played
+	Players         string        //Comma separated players for a new tournament
+	Name            string        //Name of the player, empty to ask for it
+	NoGreeting      bool          //Start the test without the welcome or waiting for ENTER
+	FullText        bool          //Cut long text in the score table short and list it in full below, instead of wrapping it
+	Demo            bool          //Play the demo quiz built into the program instead of the question file
+	SaveFile        string        //File the test is saved in with /save, to finish later
+	Resume          bool          //Carry on with the test saved in SaveFile instead of starting a new one
+	Once            bool          //Quit after the test instead of asking whether to play again
+	SkipBadRows     bool          //Load the rows of the question file that can be read, skipping the rest
+	Stratify        string        //Fields the questions are sampled in proportion to, empty to take the first ones
+	Quotas          string        //Comma separated category=count quotas of questions, empty for no quotas
+	Script          string        //Starlark script with custom grading, question generation or scoring
+	ResultsFile     string        //File the results of each test are saved in, empty to not save them
+	CanvasURL       string        //Base URL of a Canvas instance. When set scores are sent to its gradebook
+	CanvasToken     string        //Canvas API access token
+	CanvasCourse    string        //ID of the Canvas course
+	CanvasAssign    string        //ID of the Canvas assignment the scores are for
+	CanvasUser      string        //Canvas ID of the player, empty to find them in the course by name
+	Speak           bool          //Read each question aloud
+	Voice           string        //Voice or language of the speech synthesizer, empty for its default
+	SpeakCmd        string        //Command that reads the questions aloud, empty for the system's speech synthesizer
+	ListenCmd       string        //Command that records and transcribes a spoken answer, empty to only type answers
+	Mode            string        //How the quiz is played, one of modes, empty for a normal test
+	Focus           bool          //Pick the questions the player gets wrong more often
+	Penalty         float64       //Points lost for each wrong answer in a blitz
+	ExamFile        string        //Exam manifest. When set the exam is sat instead of a quiz
+	BookmarksFile   string        //File the bookmarked questions are added to, empty to not save them
+	SignKey         string        //Ed25519 private key the result is signed with
+	SignedResult    string        //File the signed result is written to, empty to not sign it
+	Certificate     string        //File a completion certificate is written to on passing, empty for none
+	Pass            float64       //Score in percent needed for a certificate
+	Title           string        //Title of the quiz on the certificate, empty for the name of the question file
+	Logo            string        //Image shown on the certificate, empty for none
+	LLMURL          string        //Base URL of an OpenAI compatible API
+	LLMKey          string        //Key for the LLM API, $OPENAI_API_KEY if empty
+	LLMModel        string        //Name of the model to use
+	LLMGrade        bool          //Grade free answers with the LLM
+	LLMCache        string        //File the LLM's grades are cached in, empty to not cache them
+}
+
+// command is a subcommand of quiz.
+type command struct {
+	name    string                                         //Name used on the command line
+	args    string                                         //Arguments after the flags, for the usage line
+	summary string                                         //One line description for the help
+	run     func(ctx context.Context, args []string) error //Parses the command's flags from args and runs it
+}
+
+// commands returns the subcommands in the order they are listed in the help.
+func commands() []command {
+	return []command{
+		{"play", "", "Play a quiz in the terminal (the default)", play},
+		{"daily", "", "Play today's quiz and keep up a streak of days in a row", daily},
+		{"serve", "", "Run the leaderboard server, Telegram bot, ssh server or LTI tool", serve},
+		{"create", "<file>", "Write a new question file by answering prompts", create},
+		{"generate", "", "Draft questions with a large language model or from Wikidata", generate},
+		{"edit", "<file>", "Browse, search and change the questions in a file", edit},
+		{"preview", "<file>", "Show the questions in a file as they are asked", preview},
+		{"validate", "<file>...", "Check question files for problems", validate},
+		{"lint", "<file>...", "Check question files for authoring mistakes and fix them", lint},
+		{"convert", "", "Convert a question file to another format", convert},
+		{"cloze", "<document>", "Make fill in the blank questions from a text document", clozeCmd},
+		{"exam", "<bank>", "Pick questions from a bank for an exam, with an answer key", exam},
+		{"autograde", "<bank> <answers>", "Grade a file of answers without playing, for GitHub Classroom", autograde},
+		{"diff", "<old> <new>", "Show the questions that changed between two versions of a file", diff},
+		{"merge", "<file>...", "Merge question files, leaving out duplicates", merge},
+		{"stats", "<file>...", "Show statistics about question files", stats},
+		{"results", "[<file>...]", "List the saved results of tests or export them as grades", resultsCmd},
+		{"verify", "<signed result>...", "Check signed results weren't changed after the test", verify},
+		{"version", "", "Show the version, commit and build date", version},
+	}
+}
+
+// Run runs the subcommand named by the first argument with the rest of
+// the arguments.  If the first argument is a flag, or there are no
+// arguments, the quiz is played.
+// The quiz stops cleanly when ctx is cancelled.
+func Run(ctx context.Context, args []string) (err error) {
+	name := "play"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	// "quiz help" lists the subcommands and "quiz help play" shows the flags for play
+	if name == "help" {
+		if len(args) == 0 {
+			usage(os.Stdout)
+			return nil
+		}
+		name, args = args[0], []string{"-help"}
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			err = cmd.run(ctx, args)
+			if perr := stopProfile(); err == nil {
+				err = perr
+			}
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			return err
+		}
+	}
+	usage(os.Stderr)
+	return fmt.Errorf("unknown command %q", name)
+}
+
+// usage lists the subcommands.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "------------------------")
+	fmt.Fprintln(w, "quiz - play a quiz game")
+	fmt.Fprintln(w, "** syntax quiz <command> -var=Value **")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "Run \"quiz help <command>\" to see the flags for a command.")
+	fmt.Fprintln(w, "------------------------")
+}
+
+// newFlagSet returns the flag set for the named subcommand.  The usage
+// shown for -help lists the command's flags.
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		var cmd command
+		for _, c := range commands() {
+			if c.name == name {
+				cmd = c
+			}
+		}
+		syntax := "quiz " + cmd.name + " -var=Value"
+		if cmd.args != "" {
+			syntax += " " + cmd.args
+		}
+
+		w := flags.Output()
+		fmt.Fprintln(w, "------------------------")
+		fmt.Fprintf(w, "quiz %s - %s\n", cmd.name, cmd.summary)
+		fmt.Fprintf(w, "** syntax %s **\n", syntax)
+		flags.PrintDefaults()
+		fmt.Fprintf(w, "Flags can also be set with %s environment variables, e.g. %sTIMELIMIT=60s\n", EnvPrefix, EnvPrefix)
+		fmt.Fprintln(w, "------------------------")
+	}
+	return flags
+}
+
+// testFlags adds the flags for the settings of the test to flags.
+func (opts *Options) testFlags(flags *flag.FlagSet) {
+	def := quiz.DefaultConfig()
+
+	//These variables are the commandline flags which are parsed by the flags module
+	flags.StringVar(&opts.FilePath, "filepath", def.FilePath, "A file (.csv, .json, .yaml, .gift, .aiken, Anki .txt or Kahoot .xlsx) or URL containing quiz questions")
+	flags.BoolVar(&opts.Shuffle, "shuffle", def.Shuffle, "When set to True, the quiz questions are shuffled. (default \"false\")")
+	flags.Int64Var(&opts.Seed, "seed", def.Seed, "Seed for shuffling the questions. The same seed gives the same order every time.\nIf no seed is provided the order is different every time.")
+	flags.IntVar(&opts.TotalQuestions, "totalquestions", def.TotalQuestions, "Number of questions in the test.\nIf no count is provided then all questions in the file will be used.")
+	flags.BoolVar(&opts.Demo, "demo", false, "Play the short demo quiz built into the program, to try the game without a question file")
+	flags.BoolVar(&opts.SkipBadRows, "skipbadrows", false, "Ask the questions in the rows of the file that can be read, listing the rows skipped,\ninstead of refusing to start when a row can't be read")
+	flags.StringVar(&opts.Language, "lang", def.Language, "Language code of the translations in the question file to play in, e.g. \"fr\" or \"pt-BR\".\nIf no language is provided the questions are asked as they are written.")
+	flags.StringVar(&opts.OnDuplicate, "onduplicate", def.OnDuplicate, "What to do with questions that repeat an earlier one, ignoring case and extra spaces:\n\"warn\" to ask them and warn, \"skip\" to leave them out or \"fail\" to refuse to start.\nIf none is provided they are asked without looking for them.")
+	flags.IntVar(&opts.MaxPerCategory, "maxpercategory", def.MaxPerCategory, "Most questions to pick from any one category, so a file with many questions on one topic\ndoesn't crowd out the rest. If no number is provided there is no limit.")
+	flags.StringVar(&opts.Stratify, "stratify", "", "Pick the -totalquestions in proportion to the file by \"category\", \"difficulty\" or \"category,difficulty\",\nso every topic is covered. If no fields are provided the first questions in the file are used.")
+	flags.StringVar(&opts.Quotas, "quotas", "", "Number of questions to pick from each category, e.g. \"Capitals=5,Rivers=3\", in place of -totalquestions")
+	flags.DurationVar(&opts.TimeLimit, "timelimit", def.TimeLimit, "Time limit for the test")
+	flags.DurationVar(&opts.QuestionLimit, "questionlimit", def.QuestionLimit, "Time limit for each question. A question that isn't answered in time is marked wrong.\nIf no limit is provided there is only the limit for the test.")
+	flags.DurationVar(&opts.WarnBefore, "warn", def.WarnBefore, "How long before the time for the test or a question runs out to warn you, e.g. 10s.\nIf none is provided there is no warning.")
+	flags.BoolVar(&opts.Bell, "bell", def.Bell, "Ring the terminal bell with the -warn warning that the time is nearly up")
+	flags.IntVar(&opts.Lives, "lives", def.Lives, "Number of wrong answers that end the test, for survival mode.\nIf no number is provided the test carries on after wrong answers.")
+	flags.StringVar(&opts.LeaderboardURL, "leaderboardurl", def.LeaderboardURL, "URL of a leaderboard server, e.g. \"http://quiz.example.com:8080\".\nWhen provided your score is submitted after the test and the top scores are shown.")
+	flags.StringVar(&opts.LeaderboardToken, "leaderboardtoken", def.LeaderboardToken, "Token the leaderboard server needs to accept scores.\nWith \"quiz serve -leaderboard\" it is the token the server needs.")
+	flags.IntVar(&opts.LeaderboardTop, "leaderboardtop", def.LeaderboardTop, "Number of top scores to show from the leaderboard server")
+	flags.StringVar(&opts.ResultsFile, "results", results.DefaultPath(), "File to save the results of each test in, for \"quiz stats -item-analysis\".\nSet it to \"\" to not save results.")
+	flags.StringVar(&opts.CanvasURL, "canvasurl", "", "URL of a Canvas instance, e.g. \"https://school.instructure.com\".\nWhen provided your score is sent to an assignment in the Canvas gradebook.")
+	flags.StringVar(&opts.CanvasToken, "canvastoken", "", "Canvas API access token of a teacher in the course")
+	flags.StringVar(&opts.CanvasCourse, "canvascourse", "", "ID of the Canvas course")
+	flags.StringVar(&opts.CanvasAssign, "canvasassignment", "", "ID of the Canvas assignment the scores are for")
+	flags.StringVar(&opts.CanvasUser, "canvasuser", "", "Canvas ID of the player, e.g. \"1234\" or \"sis_login_id:jsmith\".\nIf no ID is provided the player is found in the course by their name.")
+	flags.BoolVar(&opts.LLMGrade, "llmgrade", false, "Ask a large language model whether free answers mean the same as the correct answer,\nso answers in other words can be right. Answers must match exactly if the model can't be reached.")
+	flags.StringVar(&opts.LLMCache, "llmcache", llm.DefaultCachePath(), "File to cache the model's grades in, so the same answer is only graded once")
+	opts.llmFlags(flags)
+	flags.StringVar(&opts.Script, "script", "", "A Starlark script that can define grade(), generate() and score() functions\nto customise grading, generate questions or calculate the score.")
+}
+
+// llmFlags adds the flags for the LLM API to flags.
+func (opts *Options) llmFlags(flags *flag.FlagSet) {
+	flags.StringVar(&opts.LLMURL, "llmurl", llm.DefaultURL, "Base URL of an OpenAI compatible API, e.g. \"http://localhost:11434/v1\" for Ollama")
+	flags.StringVar(&opts.LLMKey, "llmkey", "", "Key for the API. If no key is provided $OPENAI_API_KEY is used.")
+	flags.StringVar(&opts.LLMModel, "llmmodel", "gpt-4o-mini", "Name of the model")
+}
+
+// llmClient returns the client for the LLM API set by the llm flags.
+func (opts *Options) llmClient() *llm.Client {
+	key := opts.LLMKey
+	if key == "" {
+		key = os.Getenv("OPENAI_API_KEY")
+	}
+	return llm.NewClient(opts.LLMURL, key, opts.LLMModel)
+}
+
+// newAssessment makes the test described by the options and loads its
+// questions, unless a script generates them.
+func (opts *Options) newAssessment(ctx context.Context) (*quiz.Assessment, error) {
+	var extras []quiz.Option
+	var grader quiz.Grader
+	if opts.Script != "" {
+		s, err := script.Load(opts.Script)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Seed != 0 {
+			s.Seed(opts.Seed)
+		}
+		grader = s.Grader()
+		extras = append(extras, quiz.WithScorer(s.Scorer()), quiz.WithSource(s.Source()))
+	}
+	if opts.LLMGrade {
+		if grader != nil {
+			return nil, errors.New("-llmgrade and the script's grade() can't both grade the answers")
+		}
+		judge := &llm.Judge{Client: opts.llmClient(), CachePath: opts.LLMCache, Warn: os.Stderr}
+		grader = judge.Grade
+	}
+	extras = append(extras, quiz.WithGrader(grader))
+	strata, err := strataFunc(opts.Stratify)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Quotas != "" {
+		if opts.Stratify != "" && opts.Stratify != "category" {
+			return nil, fmt.Errorf("-quotas are for categories, so can't be used with -stratify %s", opts.Stratify)
+		}
+		quotas, err := parseQuotas(opts.Quotas)
+		if err != nil {
+			return nil, err
+		}
+		extras = append(extras, quiz.WithStrata(func(q quiz.Question) string { return q.Category }, quotas))
+	} else if strata != nil {
+		extras = append(extras, quiz.WithStrata(strata, nil))
+	}
+	if opts.Demo {
+		opts.FilePath = loader.DemoSource
+	}
+	test := quiz.NewAssessment(opts.Config, extras...)
+
+	// Questions from a script's generate() replace the question file
+	if test.Source == nil {
+		l := loader.Default
+		if opts.SkipBadRows {
+			l = loader.SkipBadRows(l, os.Stderr)
+		}
+		if err := test.LoadQuestions(ctx, l); err != nil {
+			if errors.Is(err, fs.ErrNotExist) && opts.FilePath == quiz.DefaultConfig().FilePath {
+				return nil, fmt.Errorf("%w\nGive your question file with -filepath, or try the demo quiz with -demo", err)
+			}
+			return nil, err
+		}
+	}
+	return test, nil
+}
+
+// parseQuotas parses -quotas, e.g. "Capitals=5,Rivers=3", into the number
+// of questions for each category.
+func parseQuotas(list string) (map[string]int, error) {
+	quotas := make(map[string]int)
+	for _, part := range strings.Split(list, ",") {
+		category, count, ok := strings.Cut(part, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if !ok || err != nil || n < 0 {
+			return nil, fmt.Errorf("the quota %q should be a category, = and a number of questions", strings.TrimSpace(part))
+		}
+		quotas[strings.TrimSpace(category)] = n
+	}
+	return quotas, nil
+}
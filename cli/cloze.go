@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rastewart/go-quiz-game/cloze"
+	"github.com/rastewart/go-quiz-game/loader"
+)
+
+// clozeCmd makes fill in the blank questions from a plain text study
+// document and writes them to a question file to be reviewed.
+func clozeCmd(ctx context.Context, args []string) (err error) {
+	def := cloze.DefaultOptions()
+	var opts cloze.Options
+	flags := newFlagSet("cloze")
+	out := flags.String("o", "", "Question file to write the questions to, e.g. cloze.json")
+	force := flags.Bool("force", false, "Overwrite the file if it already exists")
+	category := flags.String("category", "", "Category for the questions")
+	flags.IntVar(&opts.MinWords, "minwords", def.MinWords, "Sentences with fewer words are skipped")
+	flags.IntVar(&opts.MaxWords, "maxwords", def.MaxWords, "Sentences with more words are skipped, 0 for no limit")
+	flags.BoolVar(&opts.AllTerms, "all", def.AllTerms, "Make a question for every key term in a sentence rather than only the best one")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *out == "" {
+		flags.Usage()
+		return errors.New("cloze needs the document to read and -o")
+	}
+	if _, err = loader.LookupExporter(*out); err != nil {
+		return err
+	}
+	if _, err = os.Stat(*out); err == nil && !*force {
+		return fmt.Errorf("%s already exists, use -force to overwrite it", *out)
+	}
+
+	text, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	questions := cloze.Generate(string(text), opts)
+	if len(questions) == 0 {
+		return fmt.Errorf("no key terms were found in %s", flags.Arg(0))
+	}
+	for i := range questions {
+		questions[i].Category = *category
+	}
+
+	if err = loader.Export(*out, questions); err != nil {
+		return err
+	}
+	warnUnsaved(*out, questions)
+	fmt.Printf("Wrote %v questions to %s.  Review them with \"quiz edit %s\" before they are used.\n", len(questions), *out, *out)
+	return nil
+}
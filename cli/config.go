@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigPath returns the config file read when -config isn't given,
+// $XDG_CONFIG_HOME/quiz/config.yaml or ~/.config/quiz/config.yaml.
+func ConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "quiz", "config.yaml")
+}
+
+// EnvPrefix is the prefix of the environment variables that set flags,
+// e.g. QUIZ_TIMELIMIT sets -timelimit.
+const EnvPrefix = "QUIZ_"
+
+// parseFlags parses args into flags, after setting the flags from the
+// config file and then the environment, so the command line overrides the
+// environment and the environment overrides the config file.
+//
+// The config file is YAML with the flag names as keys.  Top level keys set
+// the flag for every command that has it, and keys under a command's name
+// only set it for that command, e.g.
+//
+//	timelimit: 60s
+//	shuffle: true
+//	serve:
+//	  sshaddr: ":2222"
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	path := flags.String("config", ConfigPath(), "YAML file with default values for the flags")
+	profileFlags(flags)
+	explicit := false
+	if v, ok := os.LookupEnv(EnvPrefix + "CONFIG"); ok {
+		*path, explicit = v, true
+	}
+	if v, ok := flagValue(args, "config"); ok {
+		*path, explicit = v, true
+	}
+
+	if *path != "" {
+		settings, err := readConfig(*path)
+		if errors.Is(err, fs.ErrNotExist) && !explicit {
+			err = nil // there doesn't have to be a config file
+		}
+		if err != nil {
+			return err
+		}
+		if err = applyConfig(flags, settings, *path); err != nil {
+			return err
+		}
+	}
+	if err := applyEnv(flags); err != nil {
+		return err
+	}
+	if err := flags.Parse(flagsFirst(flags, args)); err != nil {
+		return err
+	}
+	return startProfile()
+}
+
+// flagsFirst moves the flags in args before the other arguments, so flags
+// can be given after file names, e.g. "quiz merge a.csv b.csv -o merged.csv".
+// Everything after "--" is left as it is.
+func flagsFirst(flags *flag.FlagSet, args []string) []string {
+	var named, rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			rest = append(rest, arg)
+			continue
+		}
+		named = append(named, arg)
+
+		// The value of a flag that isn't boolean may be the next argument
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		f := flags.Lookup(name)
+		if f == nil {
+			continue
+		}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			continue
+		}
+		if i+1 < len(args) {
+			i++
+			named = append(named, args[i])
+		}
+	}
+	return append(named, rest...)
+}
+
+// applyEnv sets each flag that has an environment variable set, named
+// EnvPrefix followed by the flag name in upper case.
+func applyEnv(flags *flag.FlagSet) (err error) {
+	flags.VisitAll(func(f *flag.Flag) {
+		name := EnvPrefix + strings.ToUpper(f.Name)
+		v, ok := os.LookupEnv(name)
+		if !ok || f.Name == "config" || err != nil {
+			return
+		}
+		if serr := flags.Set(f.Name, v); serr != nil {
+			err = fmt.Errorf("%s: %w", name, serr)
+		}
+	})
+	return err
+}
+
+// readConfig reads the settings in a config file.
+func readConfig(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	settings := make(map[string]any)
+	if err = yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// applyConfig sets flags from the top level settings and then from the
+// settings for the command, so those win.  Top level settings for flags
+// the command doesn't have are ignored, since they are for other commands.
+func applyConfig(flags *flag.FlagSet, settings map[string]any, path string) error {
+	var section map[string]any
+	for name, value := range settings {
+		if name == flags.Name() {
+			m, ok := value.(map[string]any)
+			if !ok {
+				return fmt.Errorf("%s: %s should be a map of flags", path, name)
+			}
+			section = m
+			continue
+		}
+		if _, nested := value.(map[string]any); nested || flags.Lookup(name) == nil {
+			continue
+		}
+		if err := setFlag(flags, name, value, path); err != nil {
+			return err
+		}
+	}
+
+	for name, value := range section {
+		if flags.Lookup(name) == nil {
+			return fmt.Errorf("%s: %s has no -%s flag", path, flags.Name(), name)
+		}
+		if err := setFlag(flags, name, value, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setFlag sets the flag name from a value in the config file.  Lists are
+// joined with commas, e.g. for -players.
+func setFlag(flags *flag.FlagSet, name string, value any, path string) error {
+	var s string
+	switch v := value.(type) {
+	case []any:
+		parts := make([]string, len(v))
+		for i, p := range v {
+			parts[i] = fmt.Sprint(p)
+		}
+		s = strings.Join(parts, ",")
+	case nil:
+		s = ""
+	default:
+		s = fmt.Sprint(v)
+	}
+	if err := flags.Set(name, s); err != nil {
+		return fmt.Errorf("%s: %s: %w", path, name, err)
+	}
+	return nil
+}
+
+// flagValue returns the value of the flag name in args, given as either
+// -name=value or -name value.
+func flagValue(args []string, name string) (string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		arg = strings.TrimLeft(arg, "-")
+		if v, ok := strings.CutPrefix(arg, name+"="); ok {
+			return v, true
+		}
+		if arg == name && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
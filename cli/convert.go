@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// convert reads a question file in one format and writes it in another,
+// each chosen by the file's extension: .csv, .json, .yaml, .gift, .aiken
+// or .txt for Anki.  -choices makes the free answer questions multiple
+// choice on the way.
+func convert(ctx context.Context, args []string) (err error) {
+	flags := newFlagSet("convert")
+	in := flags.String("in", "", "Question file or URL to read, e.g. problems.csv")
+	out := flags.String("out", "", "Question file to write, e.g. problems.json")
+	choices := flags.Int("choices", 0, "Number of wrong choices to give each free answer question, taken from the answers\nto the other questions in its category that are like its answer, e.g. dates for a date.\nIf no number is provided the questions are left as they are.")
+	seed := flags.Int64("seed", 0, "Seed for picking the -choices, or a different pick every time if not set")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *in == "" || *out == "" {
+		flags.Usage()
+		return errors.New("convert needs both -in and -out")
+	}
+
+	questions, err := loader.Load(*in)
+	if err != nil {
+		return err
+	}
+	if *choices > 0 {
+		if *seed == 0 {
+			*seed = time.Now().UnixNano()
+		}
+		quiz.AddChoices(questions, *choices, rand.New(rand.NewSource(*seed)))
+	}
+	if err = loader.Export(*out, questions); err != nil {
+		return err
+	}
+	warnUnsaved(*out, questions)
+	fmt.Printf("Converted %v questions from %s to %s\n", len(questions), *in, *out)
+	return nil
+}
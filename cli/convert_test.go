@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// TestConvertChoicesPlay converts a bank with -choices and plays it, so the
+// choices made are the ones the player is asked with.
+func TestConvertChoicesPlay(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "bank.csv"), filepath.Join(dir, "bank-mc.csv")
+	bank := "question,answer,category\nCapital of France?,Paris,Capitals\nCapital of Spain?,Madrid,Capitals\nCapital of Italy?,Rome,Capitals\n"
+	if err := os.WriteFile(in, []byte(bank), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := convert(context.Background(), []string{"-in", in, "-out", out, "-choices", "2", "-seed", "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	questions, err := loader.Load(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test := quiz.NewAssessment(quiz.Config{}, quiz.WithQuestions(questions))
+	var output bytes.Buffer
+	s := test.NewSession(strings.NewReader("Paris\nMadrid\nRome\n"), &output)
+	s.Name, s.NoGreeting = "Ann", true
+	if err = s.StartTest(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"1. Capital of France?\n    Choices:  Madrid | Paris | Rome\n",
+		"2. Capital of Spain?\n    Choices:  Madrid | Paris | Rome\n",
+		"3. Capital of Italy?\n    Choices:  Madrid | Paris | Rome\n",
+	} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("the output doesn't have %q:\n%s", want, output.String())
+		}
+	}
+	if s.TotalCorrect != 3 {
+		t.Errorf("%v answers were right, want 3", s.TotalCorrect)
+	}
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// create asks for questions one at a time and writes them to a new
+// question file, in the format given by its extension.
+func create(ctx context.Context, args []string) (err error) {
+	flags := newFlagSet("create")
+	force := flags.Bool("force", false, "Overwrite the file if it already exists")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("create needs the file to write")
+	}
+	path := flags.Arg(0)
+	if _, err = loader.LookupExporter(path); err != nil {
+		return err
+	}
+	if _, err = os.Stat(path); err == nil && !*force {
+		return fmt.Errorf("%s already exists, use -force to overwrite it", path)
+	}
+
+	p := newPrompter(ctx)
+	fmt.Println("Enter each question and its answer.  Leave the question blank when you have finished.")
+	var questions []quiz.Question
+	category := ""
+	for {
+		text, err := p.ask(fmt.Sprintf("Question %v: ", len(questions)+1))
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if text == "" {
+			break
+		}
+
+		q := quiz.Question{QText: text}
+		for q.Answer == "" {
+			if q.Answer, err = p.ask("Answer: "); err != nil {
+				return err
+			}
+		}
+
+		fmt.Println("For a multiple choice question enter the wrong choices, or leave blank for none.")
+		for {
+			choice, err := p.ask("Wrong choice: ")
+			if err != nil && !errors.Is(err, io.EOF) {
+				return err
+			}
+			if choice == "" {
+				break
+			}
+			if choice == q.Answer {
+				fmt.Println("That's the answer, enter a wrong choice.")
+				continue
+			}
+			q.Choices = append(q.Choices, choice)
+		}
+
+		if q.Hint, err = p.ask("Hint (optional): "); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		// Questions are usually written a topic at a time, so the last
+		// category is offered again
+		if category, err = p.askDefault("Category (optional)", category); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		q.Category = category
+
+		questions = append(questions, q)
+		fmt.Println()
+	}
+
+	if len(questions) == 0 {
+		return quiz.ErrNoQuestions
+	}
+	if err = loader.Export(path, questions); err != nil {
+		return err
+	}
+	warnUnsaved(path, questions)
+	fmt.Printf("Wrote %v questions to %s\n", len(questions), path)
+	return nil
+}
+
+// hasComments reports whether the CSV file at path has comment lines,
+// which are lost when the file is written again.
+func hasComments(path string) bool {
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+	return false
+}
+
+// warnUnsaved warns when questions have fields the format of path can't hold.
+func warnUnsaved(path string, questions []quiz.Question) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if !slices.Contains([]string{".csv", ".json", ".yaml", ".yml"}, ext) {
+		for _, q := range questions {
+			if len(q.Translations) > 0 {
+				fmt.Println("Only CSV, JSON and YAML files hold translations, so the translations weren't saved.")
+				break
+			}
+		}
+	}
+	switch ext {
+	case ".aiken":
+		for _, q := range questions {
+			if q.Hint != "" || q.Explanation != "" || q.Points != 0 || q.Category != "" || q.Difficulty != "" || q.ID != "" || q.TimeLimit != 0 {
+				fmt.Println("Aiken files only hold the questions, answers and choices, so the hints, explanations, points, categories, difficulties, time limits and IDs weren't saved.  Use a .csv or .json file to keep them.")
+				return
+			}
+		}
+	case ".tsv":
+		for _, q := range questions {
+			if q.Explanation != "" || q.Points != 0 || q.Difficulty != "" || q.TimeLimit != 0 {
+				fmt.Println("Anki cards only hold the questions, answers, choices, hints and categories, so the explanations, points, difficulties and time limits weren't saved.")
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/quiz"
+	"github.com/rastewart/go-quiz-game/results"
+)
+
+// daily plays the day's quiz, the same few questions for everyone, once a
+// day, keeping a streak of the days in a row it has been played.
+func daily(ctx context.Context, args []string) (err error) {
+	opts := &Options{}
+	def := quiz.DefaultConfig()
+	flags := newFlagSet("daily")
+	flags.StringVar(&opts.FilePath, "filepath", def.FilePath, "A file or URL containing the questions to pick the daily quiz from")
+	flags.IntVar(&opts.TotalQuestions, "n", 5, "Number of questions in the daily quiz")
+	flags.DurationVar(&opts.TimeLimit, "timelimit", 2*time.Minute, "Time limit for the daily quiz")
+	flags.DurationVar(&opts.QuestionLimit, "questionlimit", 0, "Time limit for each question.\nIf no limit is provided there is only the limit for the quiz.")
+	flags.StringVar(&opts.ResultsFile, "results", results.DefaultPath(), "File the daily results, and so the streaks, are saved in")
+	flags.StringVar(&opts.Name, "name", "", "Your name, which your streak is kept under, so it isn't asked for")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if opts.ResultsFile == "" {
+		return errors.New("the daily quiz needs a -results file to keep the streaks in")
+	}
+	if opts.TotalQuestions < 1 {
+		return fmt.Errorf("-n should be at least 1, not %v", opts.TotalQuestions)
+	}
+
+	// The questions are sampled as the file is read, so a large bank
+	// isn't held in memory
+	today := time.Now()
+	date := today.Format(time.DateOnly)
+	r := rand.New(rand.NewSource(dailySeed(date)))
+	pick := quiz.NewReservoir(opts.TotalQuestions, r)
+	err = loader.Scan(opts.FilePath, func(q quiz.Question) bool {
+		pick.Add(q)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if pick.Seen() == 0 {
+		return fmt.Errorf("%w in %s", quiz.ErrNoQuestions, opts.FilePath)
+	}
+	picked := pick.Sample()
+	for i := range picked {
+		if err = picked[i].ExpandTemplates(r); err != nil {
+			return fmt.Errorf("%s:%d: %w", opts.FilePath, picked[i].Line, err)
+		}
+	}
+
+	// The name is needed to find the streak before the quiz starts, so it
+	// is asked for here, unless -name gives it, and the test shares the input
+	input := quiz.NewInput(os.Stdin)
+	defer input.Close()
+	fmt.Printf("Daily quiz for %s\n", today.Format("Monday 2 January 2006"))
+	name := strings.TrimSpace(opts.Name)
+	if name == "" {
+		fmt.Print("Please enter your name: ")
+		name, err = input.ReadLine(ctx)
+		name = strings.TrimSpace(name)
+		if err != nil {
+			return err
+		}
+	}
+	if name == "" {
+		return errors.New("the daily quiz needs your name to keep your streak")
+	}
+
+	history, err := results.Read(opts.ResultsFile)
+	if err != nil {
+		return err
+	}
+	streak := results.Streak(history, name, opts.FilePath, today)
+	if played, ok := results.DailyResult(history, name, opts.FilePath, date); ok {
+		fmt.Printf("You have already played today's quiz, %s, and scored %.2f%%.\n", name, played.Score)
+		fmt.Printf("You have played %s in a row. Come back tomorrow!\n", days(streak))
+		return nil
+	}
+	if streak > 0 {
+		fmt.Printf("You have played %s in a row. Keep it going!\n", days(streak))
+	}
+
+	test := quiz.NewAssessment(opts.Config, quiz.WithQuestions(picked), quiz.WithSharedInput(input))
+	test.Name, test.NoGreeting = name, true
+	// A daily quiz counts once a question is answered, so it can't be
+	// stopped and played again for a better score
+	test.OnSubmit(func(a *quiz.Assessment) {
+		if a.TotalCorrect+a.TotalIncorrect == 0 {
+			return
+		}
+		r := results.FromAssessment(a)
+		r.Daily = date
+		if err := results.Append(opts.ResultsFile, r); err != nil {
+			fmt.Fprintln(a.Out, "Unable to save your result, so your streak isn't kept:", err)
+			return
+		}
+		fmt.Fprintf(a.Out, "You have played %s in a row. See you tomorrow!\n", days(streak+1))
+	})
+
+	err = test.StartTest(ctx)
+	if errors.Is(err, quiz.ErrTimeExpired) || errors.Is(err, context.Canceled) || errors.Is(err, quiz.ErrQuit) {
+		return nil
+	}
+	return err
+}
+
+// dailySeed returns the seed that picks the questions for the quiz on
+// date, so everyone gets the same questions that day.
+func dailySeed(date string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(date))
+	return int64(h.Sum64())
+}
+
+// days returns n days in words, e.g. "1 day" or "3 days".
+func days(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%v days", n)
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// diff shows which questions were added, removed or changed between two
+// versions of a question file.  Questions are matched by their stable ID,
+// so moving questions around isn't a change.
+func diff(ctx context.Context, args []string) (err error) {
+	flags := newFlagSet("diff")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errors.New("diff needs the old and new files")
+	}
+
+	old, err := loader.Load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	changed, err := loader.Load(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	before := make(map[string]quiz.Question)
+	for _, q := range old {
+		before[q.StableID()] = q
+	}
+	added, removed, edited := 0, 0, 0
+	for _, q := range changed {
+		id := q.StableID()
+		prev, ok := before[id]
+		if !ok {
+			fmt.Printf("+ %s %s\n", id, q.QText)
+			added++
+			continue
+		}
+		delete(before, id)
+		if changes := questionChanges(prev, q); len(changes) > 0 {
+			fmt.Printf("~ %s %s\n", id, q.QText)
+			for _, c := range changes {
+				fmt.Printf("    %s\n", c)
+			}
+			edited++
+		}
+	}
+	for _, q := range old {
+		if _, ok := before[q.StableID()]; ok {
+			fmt.Printf("- %s %s\n", q.StableID(), q.QText)
+			removed++
+		}
+	}
+	fmt.Printf("%v added, %v removed, %v changed, %v unchanged\n", added, removed, edited, len(changed)-added-edited)
+	return nil
+}
+
+// questionChanges describes how the fields of a question changed.
+func questionChanges(old, q quiz.Question) []string {
+	var changes []string
+	field := func(name, was, now string) {
+		if was != now {
+			changes = append(changes, fmt.Sprintf("%s: %q -> %q", name, was, now))
+		}
+	}
+	field("question", old.QText, q.QText)
+	field("answer", old.Answer, q.Answer)
+	if !slices.Equal(old.Choices, q.Choices) {
+		changes = append(changes, fmt.Sprintf("choices: %q -> %q", old.Choices, q.Choices))
+	}
+	field("hint", old.Hint, q.Hint)
+	field("explanation", old.Explanation, q.Explanation)
+	if old.Points != q.Points {
+		changes = append(changes, fmt.Sprintf("points: %v -> %v", old.Points, q.Points))
+	}
+	field("category", old.Category, q.Category)
+	field("difficulty", old.Difficulty, q.Difficulty)
+	return changes
+}
@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// editHelp lists the editor's commands.
+const editHelp = `Commands:
+  list [page]     list the questions, a page at a time
+  show <n>        show every field of question n
+  find <text>     list the questions, answers and categories containing text
+  edit <n>        change question n, leaving a field blank keeps it and "-" clears it
+  add             add a question at the end
+  delete <n>      delete question n
+  move <n> <to>   move question n to position to
+  save            save the file
+  quit            leave the editor, asking to save any changes
+  help            show this help`
+
+// editPageSize is how many questions list shows at a time.
+const editPageSize = 20
+
+// edit browses and changes the questions in an existing question file,
+// then saves them back in the same format.
+func edit(ctx context.Context, args []string) (err error) {
+	flags := newFlagSet("edit")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("edit needs the file to edit")
+	}
+	path := flags.Arg(0)
+	if _, err = loader.LookupExporter(path); err != nil {
+		return err
+	}
+	questions, err := loader.Load(path)
+	if err != nil {
+		return err
+	}
+
+	e := &editor{path: path, questions: questions, p: newPrompter(ctx)}
+	fmt.Printf("Editing %v questions in %s.  Type help for the commands.\n", len(questions), path)
+	return e.run()
+}
+
+// editor holds the questions being edited.
+type editor struct {
+	path      string
+	questions []quiz.Question
+	changed   bool //Whether there are changes that haven't been saved
+	p         *prompter
+}
+
+// run reads commands until the user quits.
+func (e *editor) run() error {
+	for {
+		line, err := e.p.ask("> ")
+		if errors.Is(err, io.EOF) {
+			return e.quit()
+		}
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		cmd, rest := fields[0], strings.TrimSpace(strings.TrimPrefix(line, fields[0]))
+		switch cmd {
+		case "list", "l":
+			page, _ := strconv.Atoi(rest)
+			e.list(page)
+		case "show", "s":
+			if i, ok := e.index(rest); ok {
+				e.show(i)
+			}
+		case "find", "f", "/":
+			e.find(rest)
+		case "edit", "e":
+			if i, ok := e.index(rest); ok {
+				err = e.edit(&e.questions[i])
+			}
+		case "add", "a":
+			var q quiz.Question
+			if len(e.questions) > 0 {
+				q.Category = e.questions[len(e.questions)-1].Category
+			}
+			if err = e.edit(&q); err == nil && q.QText != "" && q.Answer != "" {
+				e.questions = append(e.questions, q)
+				fmt.Printf("Added question %v.\n", len(e.questions))
+			}
+		case "delete", "d":
+			if i, ok := e.index(rest); ok {
+				e.questions = append(e.questions[:i], e.questions[i+1:]...)
+				e.changed = true
+				fmt.Printf("Deleted question %v.\n", i+1)
+			}
+		case "move", "m":
+			args := strings.Fields(rest)
+			if len(args) != 2 {
+				fmt.Println("Use move <n> <to>.")
+				continue
+			}
+			i, ok := e.index(args[0])
+			to, ok2 := e.index(args[1])
+			if ok && ok2 {
+				q := e.questions[i]
+				e.questions = append(e.questions[:i], e.questions[i+1:]...)
+				e.questions = append(e.questions[:to], append([]quiz.Question{q}, e.questions[to:]...)...)
+				e.changed = true
+			}
+		case "save", "w":
+			err = e.save()
+		case "quit", "q", "exit":
+			return e.quit()
+		case "help", "h", "?":
+			fmt.Println(editHelp)
+		default:
+			fmt.Printf("Unknown command %q.  Type help for the commands.\n", cmd)
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+}
+
+// index parses a question number and returns its index in questions.
+func (e *editor) index(s string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 1 || n > len(e.questions) {
+		fmt.Printf("Give a question number from 1 to %v.\n", len(e.questions))
+		return 0, false
+	}
+	return n - 1, true
+}
+
+// list shows a page of questions, starting from 1.
+func (e *editor) list(page int) {
+	if page < 1 {
+		page = 1
+	}
+	start := (page - 1) * editPageSize
+	end := min(start+editPageSize, len(e.questions))
+	if start >= len(e.questions) {
+		fmt.Println("There are no questions on that page.")
+		return
+	}
+
+	var rows []int
+	for i := start; i < end; i++ {
+		rows = append(rows, i)
+	}
+	e.table(rows)
+	if pages := (len(e.questions) + editPageSize - 1) / editPageSize; pages > 1 {
+		fmt.Printf("Page %v of %v.  Use list <page> to see another page.\n", page, pages)
+	}
+}
+
+// find lists the questions containing text, ignoring case.
+func (e *editor) find(text string) {
+	text = strings.ToLower(text)
+	var rows []int
+	for i, q := range e.questions {
+		for _, field := range []string{q.QText, q.Answer, q.Category} {
+			if strings.Contains(strings.ToLower(field), text) {
+				rows = append(rows, i)
+				break
+			}
+		}
+	}
+	if len(rows) == 0 {
+		fmt.Println("No questions match.")
+		return
+	}
+	e.table(rows)
+}
+
+// table shows the questions at rows.
+func (e *editor) table(rows []int) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"#", "Question", "Answer", "Choices", "Category"})
+	for _, i := range rows {
+		q := e.questions[i]
+		table.Append([]string{strconv.Itoa(i + 1), q.QText, q.Answer, strconv.Itoa(len(q.Choices)), q.Category})
+	}
+	table.Render()
+}
+
+// show prints every field of a question.
+func (e *editor) show(i int) {
+	q := e.questions[i]
+	fmt.Printf("Question %v: %s\n", i+1, q.QText)
+	fmt.Printf("Answer:     %s\n", q.Answer)
+	for _, c := range q.Choices {
+		fmt.Printf("Choice:     %s\n", c)
+	}
+	fmt.Printf("Hint:       %s\n", q.Hint)
+	fmt.Printf("Category:   %s\n", q.Category)
+	fmt.Printf("ID:         %s\n", q.StableID())
+}
+
+// edit asks for each field of q in turn, showing its current value.
+// A question that is given new text keeps the ID it had, so its history
+// still matches it.
+func (e *editor) edit(q *quiz.Question) (err error) {
+	before := fmt.Sprint(*q)
+	id := ""
+	if q.ID == "" && q.QText != "" {
+		id = q.StableID()
+	}
+	if q.QText, err = e.p.askDefault("Question", q.QText); err != nil {
+		return err
+	}
+	if q.Answer, err = e.p.askDefault("Answer", q.Answer); err != nil {
+		return err
+	}
+	if q.QText == "" || q.Answer == "" {
+		fmt.Println("A question needs both the question and its answer.")
+		return nil
+	}
+	choices := strings.Join(q.Choices, " | ")
+	if choices, err = e.p.askDefault("Wrong choices, separated by |", choices); err != nil {
+		return err
+	}
+	q.Choices = nil
+	for _, c := range strings.Split(choices, "|") {
+		if c = strings.TrimSpace(c); c != "" {
+			q.Choices = append(q.Choices, c)
+		}
+	}
+	if q.Hint, err = e.p.askDefault("Hint", q.Hint); err != nil {
+		return err
+	}
+	if q.Category, err = e.p.askDefault("Category", q.Category); err != nil {
+		return err
+	}
+	if fmt.Sprint(*q) != before {
+		e.changed = true
+	}
+	if id != "" && q.StableID() != id {
+		q.ID = id
+	}
+	return nil
+}
+
+// save writes the questions back to the file.
+func (e *editor) save() error {
+	comments := hasComments(e.path)
+	if err := loader.Export(e.path, e.questions); err != nil {
+		return err
+	}
+	e.changed = false
+	warnUnsaved(e.path, e.questions)
+	if comments {
+		fmt.Println("The comments in the file weren't kept.")
+	}
+	fmt.Printf("Saved %v questions to %s.\n", len(e.questions), e.path)
+	return nil
+}
+
+// quit asks whether to save any changes before leaving.
+func (e *editor) quit() error {
+	if !e.changed {
+		return nil
+	}
+	answer, err := e.p.ask("Save your changes? [Y/n] ")
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if strings.HasPrefix(strings.ToLower(answer), "n") {
+		return nil
+	}
+	return e.save()
+}
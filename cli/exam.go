@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// exam samples questions from a bank into a fixed exam file, and writes
+// an answer key recording how the exam was built so it can be built again.
+func exam(ctx context.Context, args []string) (err error) {
+	flags := newFlagSet("exam")
+	out := flags.String("o", "", "Question file to write the exam to, e.g. section1.json")
+	keyPath := flags.String("key", "", "File to write the answer key to (default the exam file with .key.md in place of its extension)")
+	count := flags.Int("n", 0, "Number of questions in the exam, 0 for all of them")
+	seed := flags.Int64("seed", 0, "Seed for picking the questions. The same seed and bank give the same exam every time.\nIf no seed is provided one is chosen and written in the answer key.")
+	stratify := flags.String("stratify", "", "Pick questions in proportion to the bank by \"category\", \"difficulty\" or \"category,difficulty\"")
+	force := flags.Bool("force", false, "Overwrite the exam and answer key if they already exist")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *out == "" {
+		flags.Usage()
+		return errors.New("exam needs the question bank to read and -o")
+	}
+	bank := flags.Arg(0)
+	if *keyPath == "" {
+		*keyPath = strings.TrimSuffix(*out, filepath.Ext(*out)) + ".key.md"
+	}
+	strata, err := strataFunc(*stratify)
+	if err != nil {
+		return err
+	}
+	if _, err = loader.LookupExporter(*out); err != nil {
+		return err
+	}
+	for _, path := range []string{*out, *keyPath} {
+		if _, err = os.Stat(path); err == nil && !*force {
+			return fmt.Errorf("%s already exists, use -force to overwrite it", path)
+		}
+	}
+
+	questions, err := loader.Load(bank)
+	if err != nil {
+		return err
+	}
+	if len(questions) == 0 {
+		return fmt.Errorf("%w in %s", quiz.ErrNoQuestions, bank)
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	picked := quiz.Sample(questions, *count, strata, rand.New(rand.NewSource(*seed)))
+
+	if err = loader.Export(*out, picked); err != nil {
+		return err
+	}
+	warnUnsaved(*out, picked)
+	if err = writeKey(*keyPath, bank, *out, picked, len(questions), *seed, *stratify); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %v of the %v questions in %s to %s, with the answer key in %s\n", len(picked), len(questions), bank, *out, *keyPath)
+	return nil
+}
+
+// strataFunc returns the function that groups questions for -stratify,
+// or nil when they aren't grouped.
+func strataFunc(stratify string) (func(quiz.Question) string, error) {
+	if stratify == "" {
+		return nil, nil
+	}
+	var fields []func(quiz.Question) string
+	for _, name := range strings.Split(stratify, ",") {
+		switch strings.TrimSpace(name) {
+		case "category":
+			fields = append(fields, func(q quiz.Question) string { return q.Category })
+		case "difficulty":
+			fields = append(fields, func(q quiz.Question) string { return q.Difficulty })
+		default:
+			return nil, fmt.Errorf("can't stratify by %q, only by category and difficulty", name)
+		}
+	}
+	return func(q quiz.Question) string {
+		parts := make([]string, len(fields))
+		for i, f := range fields {
+			parts[i] = f(q)
+		}
+		return strings.Join(parts, "\x00")
+	}, nil
+}
+
+// writeKey writes the answer key for an exam as a Markdown file, with the
+// details needed to build the same exam again.
+func writeKey(path, bank, exam string, questions []quiz.Question, total int, seed int64, stratify string) (err error) {
+	// A bank loaded from a URL has no checksum
+	sum := ""
+	if data, err := os.ReadFile(bank); err == nil {
+		sum = fmt.Sprintf(", sha256 %x", sha256.Sum256(data))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	w := bufio.NewWriter(file)
+	fmt.Fprintf(w, "# Answer key for %s\n\n", exam)
+	fmt.Fprintf(w, "- Bank: %s (%v questions%s)\n", bank, total, sum)
+	fmt.Fprintf(w, "- Questions: %v\n", len(questions))
+	fmt.Fprintf(w, "- Seed: %v\n", seed)
+	if stratify != "" {
+		fmt.Fprintf(w, "- Stratified by: %s\n", stratify)
+	}
+	fmt.Fprintf(w, "- Built: %s\n\n", time.Now().UTC().Format(time.RFC3339))
+
+	rebuild := fmt.Sprintf("quiz exam -n %v -seed %v", len(questions), seed)
+	if stratify != "" {
+		rebuild += " -stratify " + stratify
+	}
+	fmt.Fprintf(w, "The same bank gives the same exam again with:\n\n    %s -o %s %s\n\n", rebuild, exam, bank)
+
+	cell := strings.NewReplacer("|", `\|`, "\n", " ").Replace
+	fmt.Fprintln(w, "| # | Question | Answer | Category | Difficulty |")
+	fmt.Fprintln(w, "|---|----------|--------|----------|------------|")
+	for i, q := range questions {
+		fmt.Fprintf(w, "| %v | %s | %s | %s | %s |\n", i+1, cell(q.QText), cell(q.Answer), cell(q.Category), cell(q.Difficulty))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/llm"
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/quiz"
+	"github.com/rastewart/go-quiz-game/wikidata"
+)
+
+// generate drafts questions about a topic with an LLM, or makes them from
+// facts in Wikidata, and writes them to a question file to be reviewed.
+func generate(ctx context.Context, args []string) (err error) {
+	opts := &Options{}
+	flags := newFlagSet("generate")
+	opts.llmFlags(flags)
+	var g llm.GenerateOptions
+	flags.StringVar(&g.Topic, "topic", "", "What the questions are about, e.g. \"Go concurrency\"")
+	flags.IntVar(&g.Count, "count", 10, "Number of questions to write")
+	flags.IntVar(&g.Choices, "choices", 3, "Number of wrong choices for each question, 0 for free answer questions")
+	flags.StringVar(&g.Difficulty, "difficulty", "", "How hard the questions should be, e.g. \"easy\" or \"hard\", or a mix if not set")
+	out := flags.String("o", "", "Question file to write the questions to, e.g. concurrency.json")
+	force := flags.Bool("force", false, "Overwrite the file if it already exists")
+	var presets []string
+	for _, p := range wikidata.Presets {
+		presets = append(presets, fmt.Sprintf("%s (%s)", p.Name, p.Summary))
+	}
+	preset := flags.String("wikidata", "", "Make questions from facts in Wikidata instead, one of:\n"+strings.Join(presets, "\n"))
+	queryFile := flags.String("query", "", "SPARQL query file selecting ?subjectLabel and ?answerLabel, to make questions from other facts")
+	template := flags.String("template", "", "Question for -query, with {subject} where the subject goes, or to reword a -wikidata question")
+	lang := flags.String("lang", "en", "Language of the Wikidata questions' subjects and answers")
+	sparqlURL := flags.String("sparqlurl", wikidata.DefaultURL, "SPARQL query service for -wikidata and -query")
+	seed := flags.Int64("seed", 0, "Seed for picking the facts, or a different pick every time if not set")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	facts := *preset != "" || *queryFile != ""
+	if (g.Topic == "" && !facts) || *out == "" {
+		flags.Usage()
+		return errors.New("generate needs a -topic, -wikidata or -query and the -o file to write")
+	}
+	if g.Count < 1 {
+		return fmt.Errorf("-count should be at least 1, not %v", g.Count)
+	}
+	if _, err = loader.LookupExporter(*out); err != nil {
+		return err
+	}
+	if _, err = os.Stat(*out); err == nil && !*force {
+		return fmt.Errorf("%s already exists, use -force to overwrite it", *out)
+	}
+
+	var questions []quiz.Question
+	if facts {
+		questions, err = generateFacts(ctx, *preset, *queryFile, *template, *lang, *sparqlURL, g, *seed)
+	} else {
+		fmt.Printf("Asking %s for %v questions about %s...\n", opts.LLMModel, g.Count, g.Topic)
+		questions, err = opts.llmClient().Generate(ctx, g)
+	}
+	if len(questions) == 0 {
+		if err == nil {
+			err = quiz.ErrNoQuestions
+		}
+		return err
+	}
+	// The questions made before an error are still worth keeping
+	if err != nil {
+		fmt.Println("Unable to get all the questions:", err)
+	}
+	if err = loader.Export(*out, questions); err != nil {
+		return err
+	}
+	warnUnsaved(*out, questions)
+	fmt.Printf("Wrote %v questions to %s.  Check every question and answer before using them, with \"quiz preview %s\" and \"quiz edit %s\".\n", len(questions), *out, *out, *out)
+	return nil
+}
+
+// generateFacts makes questions from the facts found by a Wikidata preset
+// or the query in queryFile.
+func generateFacts(ctx context.Context, preset, queryFile, template, lang, sparqlURL string, g llm.GenerateOptions, seed int64) ([]quiz.Question, error) {
+	var p wikidata.Preset
+	switch {
+	case preset != "" && queryFile != "":
+		return nil, errors.New("use either -wikidata or -query, not both")
+	case preset != "":
+		var err error
+		if p, err = wikidata.LookupPreset(preset); err != nil {
+			return nil, err
+		}
+	default:
+		query, err := os.ReadFile(queryFile)
+		if err != nil {
+			return nil, err
+		}
+		if template == "" {
+			return nil, errors.New("-query needs a -template for the questions, e.g. \"What is the capital of {subject}?\"")
+		}
+		p = wikidata.Preset{Query: string(query), Category: g.Topic}
+	}
+	if template != "" {
+		p.Template = template
+	}
+	if g.Topic != "" {
+		p.Category = g.Topic
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	fmt.Printf("Asking %s for the facts...\n", sparqlURL)
+	facts, err := wikidata.NewClient(sparqlURL).Facts(ctx, p.Query, lang)
+	if err != nil {
+		return nil, err
+	}
+	if len(facts) < g.Count {
+		fmt.Printf("Only found %v facts, so there are only %v questions.\n", len(facts), len(facts))
+	}
+	return wikidata.Questions(facts, p.Template, p.Category, g.Count, g.Choices, rand.New(rand.NewSource(seed))), nil
+}
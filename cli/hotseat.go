@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// hotSeat sets up test for players taking turns at the same terminal,
+// asking for their names unless they are given.  Each player answers
+// every len(players)'th question, and their scores are shown head to
+// head at the end.
+func hotSeat(ctx context.Context, test *quiz.Assessment, players []string) error {
+	input := quiz.NewInput(os.Stdin)
+	quiz.WithSharedInput(input)(test)
+	test.OnFinished(func(a *quiz.Assessment) { input.Close() })
+
+	if len(players) == 0 {
+		fmt.Println("Welcome to the Quiz Game")
+		for i := 1; i <= 2; i++ {
+			fmt.Printf("Player %v, please enter your name: ", i)
+			name, err := input.ReadLine(ctx)
+			if err != nil {
+				return err
+			}
+			if name = strings.TrimSpace(name); name == "" {
+				name = fmt.Sprintf("Player %v", i)
+			}
+			players = append(players, name)
+		}
+	}
+	if len(players) < 2 {
+		return errors.New("taking turns needs at least two players")
+	}
+
+	// Everyone gets the same number of questions
+	n := len(test.Questions) / len(players) * len(players)
+	if n == 0 {
+		return fmt.Errorf("there are %v questions, too few for %v players to take turns", len(test.Questions), len(players))
+	}
+	test.Questions, test.TotalQuestions = test.Questions[:n], n
+	test.Name, test.NoGreeting = strings.Join(players, " vs "), true
+
+	correct := make([]int, len(players))
+	test.OnQuestionAsked(func(a *quiz.Assessment, qnum int, q *quiz.Question) {
+		var scores []string
+		for i, p := range players {
+			scores = append(scores, fmt.Sprintf("%s %v", p, correct[i]))
+		}
+		fmt.Fprintf(a.Out, "\n%s's turn (%s)\n", players[(qnum-1)%len(players)], strings.Join(scores, ", "))
+	})
+	test.OnAnswered(func(a *quiz.Assessment, qnum int, q *quiz.Question) {
+		if q.Correct {
+			correct[(qnum-1)%len(players)]++
+		}
+	})
+	test.OnFinished(func(a *quiz.Assessment) { showHeadToHead(a, players, correct) })
+	return nil
+}
+
+// showHeadToHead writes each player's score and who won.
+func showHeadToHead(a *quiz.Assessment, players []string, correct []int) {
+	asked := make([]int, len(players))
+	for i := 0; i < a.TotalCorrect+a.TotalIncorrect; i++ {
+		asked[i%len(players)]++
+	}
+
+	fmt.Fprintln(a.Out)
+	table := tablewriter.NewWriter(a.Out)
+	table.SetHeader([]string{"Player", "Right", "Wrong", "Score"})
+	best, winners := -1, []string(nil)
+	for i, p := range players {
+		score := 0.0
+		if perPlayer := a.Total() / len(players); perPlayer > 0 {
+			score = float64(correct[i]) / float64(perPlayer) * 100
+		}
+		table.Append([]string{p, strconv.Itoa(correct[i]), strconv.Itoa(asked[i] - correct[i]), fmt.Sprintf("%.2f%%", score)})
+		switch {
+		case correct[i] > best:
+			best, winners = correct[i], []string{p}
+		case correct[i] == best:
+			winners = append(winners, p)
+		}
+	}
+	table.Render()
+
+	if len(winners) == 1 {
+		fmt.Fprintf(a.Out, "%s wins with %v right!\n", winners[0], best)
+	} else {
+		last := len(winners) - 1
+		fmt.Fprintf(a.Out, "It's a draw between %s and %s, with %v right each.\n", strings.Join(winners[:last], ", "), winners[last], best)
+	}
+}
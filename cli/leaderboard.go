@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/rastewart/go-quiz-game/leaderboard"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// syncLeaderboard pushes the result of the test to the leaderboard server
+// and prints the global top scores.  recordScores registers it to be
+// called when the test is submitted if a leaderboard URL has been provided.
+func syncLeaderboard(a *quiz.Assessment) {
+	client := leaderboard.NewClient(a.LeaderboardURL)
+	client.Token = a.LeaderboardToken
+	name := filepath.Base(a.FilePath)
+
+	score := leaderboard.Score{
+		Name:    a.Name,
+		Quiz:    name,
+		Correct: a.TotalCorrect,
+		Total:   a.Total(),
+		Seconds: time.Since(a.TimeStart).Seconds(),
+	}
+	if a.TimeLimit > 0 && score.Seconds > a.TimeLimit.Seconds() {
+		score.Seconds = a.TimeLimit.Seconds()
+	}
+
+	if err := client.Push(score); err != nil {
+		fmt.Fprintln(a.Out, "Unable to submit your score to the leaderboard:", err)
+		return
+	}
+
+	scores, err := client.Top(name, a.LeaderboardTop)
+	if err != nil {
+		fmt.Fprintln(a.Out, "Unable to fetch the leaderboard:", err)
+		return
+	}
+
+	fmt.Fprintf(a.Out, "Top %v scores for %s\n", len(scores), name)
+	table := tablewriter.NewWriter(a.Out)
+	table.SetHeader([]string{"#", "Name", "Score", "Seconds", "Date"})
+	for i, v := range scores {
+		table.Append([]string{strconv.Itoa(i + 1), v.Name, fmt.Sprintf("%.2f%%", v.Percent()),
+			fmt.Sprintf("%.2f", v.Seconds), v.Submitted.Format("2006-01-02")})
+	}
+	table.Render()
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// htmlEntity matches an HTML entity such as &amp; or &#39;.
+var htmlEntity = regexp.MustCompile(`&(#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z][a-zA-Z0-9]*);`)
+
+// lint checks question files for authoring mistakes that still load, and
+// fixes the ones that can be fixed without changing what is meant.
+func lint(ctx context.Context, args []string) (err error) {
+	flags := newFlagSet("lint")
+	fix := flags.Bool("fix", false, "Fix whitespace, HTML entities and non-printable characters and save the files")
+	maxAnswer := flags.Int("maxanswer", 60, "Answers with more characters than this are reported as suspiciously long")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("lint needs at least one file to check")
+	}
+
+	failed := 0
+	for _, path := range flags.Args() {
+		questions, err := loader.Load(path)
+		if err != nil {
+			// Saving a file with rows that couldn't be read would lose them
+			fmt.Printf("%s: %v\n", path, err)
+			fmt.Printf("%s: fix the rows that can't be read before linting, \"quiz validate\" lists them\n", path)
+			failed++
+			continue
+		}
+
+		problems, fixable, fixes := lintQuestions(path, questions, *maxAnswer)
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		if *fix && len(fixes) > 0 {
+			for _, f := range fixes {
+				f.apply()
+			}
+			comments := hasComments(path)
+			if err = loader.Export(path, questions); err != nil {
+				return err
+			}
+			fmt.Printf("%s: fixed %v problems\n", path, fixable)
+			if comments {
+				fmt.Printf("%s: the comments in the file weren't kept\n", path)
+			}
+		}
+
+		left := len(problems)
+		if *fix {
+			left -= fixable
+		}
+		if left > 0 {
+			fmt.Printf("%s: %v problems", path, left)
+			if !*fix && fixable > 0 {
+				fmt.Printf(", %v can be fixed with -fix", fixable)
+			}
+			fmt.Println()
+			failed++
+		} else {
+			fmt.Printf("%s: %v questions OK\n", path, len(questions))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%v of %v files have problems", failed, flags.NArg())
+	}
+	return nil
+}
+
+// lintFix is a safe fix for a problem found by lint.
+type lintFix struct {
+	field *string //Text of the question to fix
+	fixed string  //What it is fixed to
+}
+
+func (f lintFix) apply() {
+	*f.field = f.fixed
+}
+
+// lintQuestions returns the authoring problems with questions, how many
+// of them are safe to fix, and the fixes.  The fixes change questions in
+// place when they are applied.
+func lintQuestions(path string, questions []quiz.Question, maxAnswer int) (problems []*loader.RowError, fixable int, fixes []lintFix) {
+	problem := func(q *quiz.Question, format string, args ...any) {
+		problems = append(problems, &loader.RowError{Path: path, Line: q.Line, Err: fmt.Errorf(format, args...)})
+	}
+
+	answers := make(map[string]*quiz.Question)
+	for i := range questions {
+		q := &questions[i]
+		fields := []struct {
+			name string
+			text *string
+		}{{"question", &q.QText}, {"answer", &q.Answer}, {"hint", &q.Hint}, {"category", &q.Category}}
+		for j := range q.Choices {
+			fields = append(fields, struct {
+				name string
+				text *string
+			}{fmt.Sprintf("choice %q", q.Choices[j]), &q.Choices[j]})
+		}
+
+		for _, f := range fields {
+			found := len(problems)
+			text := *f.text
+			fixed := text
+			if e := htmlEntity.FindString(fixed); e != "" {
+				problem(q, "the %s has the HTML entity %s", f.name, e)
+				fixed = html.UnescapeString(fixed)
+			}
+			if strings.IndexFunc(fixed, nonPrintable) >= 0 {
+				problem(q, "the %s has non-printable characters", f.name)
+				fixed = strings.Map(func(r rune) rune {
+					switch {
+					case r == '\t' || r == '\n' || r == '\r' || r == ' ':
+						return ' '
+					case nonPrintable(r):
+						return -1
+					}
+					return r
+				}, fixed)
+			}
+			if trimmed := strings.TrimSpace(fixed); trimmed != fixed {
+				problem(q, "the %s has whitespace at the start or end", f.name)
+				fixed = trimmed
+			}
+			if fixed != text {
+				fixable += len(problems) - found
+				fixes = append(fixes, lintFix{f.text, fixed})
+			}
+		}
+
+		if n := utf8.RuneCountInString(q.Answer); n > maxAnswer {
+			problem(q, "the answer is suspiciously long, %v characters", n)
+		}
+
+		key := normalize(q.QText)
+		if first, ok := answers[key]; ok && first.Answer != q.Answer && strings.EqualFold(first.Answer, q.Answer) {
+			problem(q, "the answer %q differs only in case from %q for the same question on line %v", q.Answer, first.Answer, first.Line)
+		} else if !ok {
+			answers[key] = q
+		}
+	}
+	return problems, fixable, fixes
+}
+
+// nonPrintable reports whether r is a character that can't be seen or
+// typed, such as a control character, zero width space or non-breaking space.
+func nonPrintable(r rune) bool {
+	return r == ' ' || r != ' ' && !unicode.IsPrint(r) || unicode.Is(unicode.Cf, r)
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// merge combines question files into one, leaving out duplicate questions
+// and reporting questions that are asked more than once with different
+// answers.
+func merge(ctx context.Context, args []string) (err error) {
+	flags := newFlagSet("merge")
+	out := flags.String("o", "", "Question file to write the merged questions to, e.g. merged.csv")
+	force := flags.Bool("force", false, "Overwrite the file if it already exists")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 || *out == "" {
+		flags.Usage()
+		return errors.New("merge needs the files to merge and -o")
+	}
+	if _, err = loader.LookupExporter(*out); err != nil {
+		return err
+	}
+	if _, err = os.Stat(*out); err == nil && !*force {
+		return fmt.Errorf("%s already exists, use -force to overwrite it", *out)
+	}
+
+	type origin struct {
+		path string
+		q    quiz.Question
+	}
+	var merged []quiz.Question
+	seen := make(map[string]origin)
+	duplicates, conflicts := 0, 0
+	for _, path := range flags.Args() {
+		questions, err := loader.Load(path)
+		if err != nil {
+			return err
+		}
+		for _, q := range questions {
+			key := mergeKey(q.QText)
+			first, ok := seen[key]
+			if !ok {
+				seen[key] = origin{path, q}
+				merged = append(merged, q)
+				continue
+			}
+			if normalize(q.Answer) != normalize(first.q.Answer) {
+				fmt.Printf("%s:%v: conflict: the answer is %q but it is %q in %s:%v\n", path, q.Line, q.Answer, first.q.Answer, first.path, first.q.Line)
+				conflicts++
+			} else {
+				fmt.Printf("%s:%v: duplicate of %s:%v\n", path, q.Line, first.path, first.q.Line)
+				duplicates++
+			}
+		}
+	}
+
+	if err = loader.Export(*out, merged); err != nil {
+		return err
+	}
+	warnUnsaved(*out, merged)
+	fmt.Printf("Wrote %v questions to %s, leaving out %v duplicates", len(merged), *out, duplicates)
+	if conflicts > 0 {
+		fmt.Printf(" and %v conflicting questions.  The first answer was kept for each conflict, check them before using %s", conflicts, *out)
+	}
+	fmt.Println()
+	return nil
+}
+
+// mergeKey returns the text of a question normalized so that questions
+// that only differ in case, spacing or punctuation are near duplicates.
+// Only punctuation that doesn't change the question is ignored, so 5+5
+// and 5-5 are still different questions.
+func mergeKey(text string) string {
+	return normalize(strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`?!.,;:"'`, r) {
+			return ' '
+		}
+		return r
+	}, text))
+}
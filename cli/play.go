@@ -0,0 +1,431 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/canvas"
+	"github.com/rastewart/go-quiz-game/certificate"
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/manifest"
+	"github.com/rastewart/go-quiz-game/quiz"
+	"github.com/rastewart/go-quiz-game/results"
+	"github.com/rastewart/go-quiz-game/speech"
+	"github.com/rastewart/go-quiz-game/tournament"
+)
+
+// modes are the values of play's -mode flag, with modeHelp describing them.
+var (
+	modes    = []string{"survival", "endless", "hotseat", "review", "blitz"}
+	modeHelp = []string{
+		"survival (play until the first wrong answer, or until -lives run out, and try to beat the best run)",
+		"endless (practise the questions over and over, with no time limit, until you press Ctrl+C)",
+		"hotseat (two players, or the -players, take turns at the questions on this terminal)",
+		"review (read through the questions and their answers before taking the test)",
+		"blitz (answer as many questions as you can in the -timelimit, losing -penalty for each wrong one)",
+	}
+)
+
+// seedOrNow returns seed, or a seed from the time if it is 0.
+func seedOrNow(seed int64) int64 {
+	if seed == 0 {
+		return time.Now().UnixNano()
+	}
+	return seed
+}
+
+// play runs the quiz in the terminal, or the next matches of a tournament.
+func play(ctx context.Context, args []string) (err error) {
+	opts := &Options{}
+	flags := newFlagSet("play")
+	opts.testFlags(flags)
+	flags.StringVar(&opts.TournamentFile, "tournament", "", "File to save a tournament bracket in.\nWhen provided the next matches in the tournament are played.")
+	flags.StringVar(&opts.Players, "players", "", "Comma separated list of players, in seeded order, to start a new tournament with,\nor to take turns with -mode=hotseat")
+	flags.BoolVar(&opts.Speak, "speak", false, "Read each question aloud")
+	flags.StringVar(&opts.Voice, "voice", "", "Voice or language to read the questions in, e.g. \"Amelie\" on macOS or \"fr\" with espeak")
+	flags.StringVar(&opts.ListenCmd, "listencmd", "", "Command that records a spoken answer and prints what was said.\nWhen provided pressing ENTER without typing an answer records one.")
+	flags.StringVar(&opts.SpeakCmd, "speakcmd", "", "Command to read the questions aloud with instead of the system's speech synthesizer.\nThe question is given on its input, or in place of {text} in the command.")
+	flags.StringVar(&opts.Mode, "mode", "", "How to play, one of:\n"+strings.Join(modeHelp, "\n"))
+	flags.BoolVar(&opts.Focus, "focus", false, "Pick the questions you have got wrong most often, and not seen for longest, more often,\nfrom your saved -results")
+	flags.Float64Var(&opts.Penalty, "penalty", 0, "Points lost for each wrong answer in -mode=blitz")
+	flags.StringVar(&opts.BookmarksFile, "bookmarks", "", "File to add the questions you bookmark by answering !b to, to study later")
+	flags.StringVar(&opts.SignedResult, "signedresult", "", "File to write your result to, signed with -signkey, for the instructor to check with \"quiz verify\"")
+	flags.StringVar(&opts.SignKey, "signkey", "", "Ed25519 private key, made with \"quiz verify -genkey\", to sign the -signedresult with.\nThe instructor installs it on the exam machine where students can't read it; it is never given to students.")
+	flags.StringVar(&opts.Certificate, "certificate", "", "File to write a completion certificate to if you pass, a .pdf file or else an HTML page to print")
+	flags.Float64Var(&opts.Pass, "pass", 80, "Score in percent needed for a -certificate. An -exam's pass mark is used if it has one.")
+	flags.StringVar(&opts.Title, "title", "", "Title of the quiz on the -certificate. If no title is provided the name of the question file is used.")
+	flags.StringVar(&opts.Logo, "logo", "", "PNG, JPEG or GIF image to show at the top of the -certificate")
+	flags.StringVar(&opts.Name, "name", "", "Your name, so it isn't asked for, for scripts, autograding and kiosks")
+	flags.BoolVar(&opts.NoGreeting, "nogreeting", false, "Start the test straight away, without the welcome or waiting for ENTER.\nThe name is the -name, or empty if it isn't provided.")
+	flags.BoolVar(&opts.FullText, "fulltext", false, "Cut long questions and answers short in the score table so it fits the terminal,\nand list them in full below it, instead of wrapping them")
+	flags.StringVar(&opts.SaveFile, "savefile", defaultSavePath(), "File to save the test in when you answer /save, to finish it later with -resume")
+	flags.BoolVar(&opts.Resume, "resume", false, "Carry on with the test saved in the -savefile with /save, instead of starting a new one")
+	flags.BoolVar(&opts.Once, "once", false, "Quit after the test instead of asking whether to play again")
+	flags.StringVar(&opts.ExamFile, "exam", "", "YAML exam manifest with a title page, instructions, a declaration and sections.\nWhen provided the exam is sat in place of the -filepath quiz.")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if opts.ExamFile != "" {
+		return sitExam(ctx, opts)
+	}
+	switch opts.Mode {
+	case "":
+	case "survival":
+		if opts.Lives == 0 {
+			opts.Lives = 1
+		}
+	case "endless":
+		opts.TimeLimit = 0
+	case "hotseat", "review", "blitz":
+	default:
+		return fmt.Errorf("unknown mode %q, it should be one of %s", opts.Mode, strings.Join(modes, ", "))
+	}
+
+	// Focusing picks from all the questions once the player's name is known
+	count := opts.TotalQuestions
+	if opts.Focus {
+		if opts.ResultsFile == "" {
+			return errors.New("-focus needs the -results file to know which questions you get wrong")
+		}
+		opts.TotalQuestions = 0
+	}
+
+	test, err := opts.newAssessment(ctx)
+	if err != nil {
+		return err
+	}
+	test.Name, test.NoGreeting, test.FullText = opts.Name, opts.NoGreeting, opts.FullText
+
+	// A resumed test asks the questions it was saved with, which were
+	// already picked
+	if opts.Focus && !opts.Resume {
+		if err = focus(test, count, opts.ResultsFile, seedOrNow(opts.Seed)); err != nil {
+			return err
+		}
+	}
+
+	if opts.Mode == "review" {
+		return review(ctx, test)
+	}
+	if opts.Mode == "endless" {
+		// The loaded questions are asked over and over instead of once
+		drill := quiz.NewDrill(test.Questions, rand.New(rand.NewSource(seedOrNow(opts.Seed))))
+		test.Questions, test.TotalQuestions, test.Source = nil, 0, drill
+		drill.Follow(test)
+	}
+	if opts.Mode == "blitz" {
+		// The questions keep coming, reshuffled each time round, until the time is up
+		blitz := quiz.NewDrill(test.Questions, rand.New(rand.NewSource(seedOrNow(opts.Seed))))
+		test.Questions, test.TotalQuestions, test.Source = nil, 0, blitz
+		test.OnFinished(func(a *quiz.Assessment) { showBlitzScore(a, opts.Penalty) })
+	}
+	if opts.Mode == "hotseat" && opts.TournamentFile == "" {
+		if err = hotSeat(ctx, test, splitPlayers(opts.Players)); err != nil {
+			return err
+		}
+	}
+
+	// Only a test of the loaded questions taken by one player can be saved
+	// to finish later
+	switch {
+	case opts.TournamentFile != "" && opts.Resume:
+		return errors.New("-resume can't carry on with a tournament match")
+	case opts.TournamentFile == "" && (opts.Mode == "" || opts.Mode == "survival"):
+		test.SavePath = opts.SaveFile
+		if opts.Resume {
+			if err = test.Restore(opts.SaveFile); errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("there is no test saved in %s to carry on with", opts.SaveFile)
+			} else if err != nil {
+				return err
+			}
+		}
+	case opts.Resume:
+		return fmt.Errorf("-resume can't carry on with a test in -mode=%s", opts.Mode)
+	}
+
+	// Recording the score is layered on with event handlers so StartTest
+	// doesn't need to know about it
+	if err = opts.recordScores(test); err != nil {
+		return err
+	}
+	if opts.ResultsFile != "" && test.Lives > 0 {
+		showBestRun(test, opts.ResultsFile)
+	}
+	if opts.SignedResult != "" {
+		if err = signResult(test, opts.SignKey, opts.SignedResult); err != nil {
+			return err
+		}
+	}
+	if opts.Certificate != "" {
+		title := opts.Title
+		if title == "" {
+			title = strings.TrimSuffix(filepath.Base(test.FilePath), filepath.Ext(test.FilePath))
+		}
+		test.OnSubmit(func(a *quiz.Assessment) {
+			score, _ := a.Score()
+			writeCertificate(a.Out, opts, a.Name, title, score, opts.Pass)
+		})
+	}
+	if opts.BookmarksFile != "" {
+		saveBookmarks(test, opts.BookmarksFile)
+	}
+	if opts.Speak || opts.SpeakCmd != "" {
+		var s speech.Speaker
+		if opts.SpeakCmd != "" {
+			s, err = speech.ParseCommand(opts.SpeakCmd)
+		} else {
+			s, err = speech.System(opts.Voice)
+		}
+		if err != nil {
+			return err
+		}
+		speech.ReadAloud(test, s)
+	}
+	if opts.ListenCmd != "" {
+		c, err := speech.ParseCommand(opts.ListenCmd)
+		if err != nil {
+			return err
+		}
+		test.Transcriber = c.Listen
+	}
+
+	// Each player in a tournament takes their own session of the test
+	if opts.TournamentFile != "" {
+		if err = playTournament(ctx, opts, test); err != nil && !errors.Is(err, context.Canceled) {
+			return fmt.Errorf("unable to play the tournament: %w", err)
+		}
+		return nil
+	}
+
+	// Running out of time, pressing Ctrl+C or stopping or saving the test
+	// with a command ends it normally
+	if opts.Once || test.Source != nil || opts.Mode != "" && opts.Mode != "survival" {
+		err = test.StartTest(ctx)
+	} else {
+		err = playRounds(ctx, test)
+	}
+	if errors.Is(err, quiz.ErrTimeExpired) || errors.Is(err, context.Canceled) || errors.Is(err, quiz.ErrQuit) || errors.Is(err, quiz.ErrSaved) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to administer test: %w", err)
+	}
+	return nil
+}
+
+// recordScores registers the handlers that submit the score of test to the
+// leaderboard, the -results file and Canvas, as the test flags say.
+func (opts *Options) recordScores(test *quiz.Assessment) error {
+	if test.LeaderboardURL != "" {
+		test.OnSubmit(syncLeaderboard)
+	}
+	if opts.ResultsFile != "" {
+		results.Record(test, opts.ResultsFile)
+	}
+	if opts.CanvasURL != "" {
+		if opts.CanvasToken == "" || opts.CanvasCourse == "" || opts.CanvasAssign == "" {
+			return errors.New("sending scores to Canvas needs -canvastoken, -canvascourse and -canvasassignment")
+		}
+		canvas.Record(test, canvas.NewClient(opts.CanvasURL, opts.CanvasToken, opts.CanvasCourse, opts.CanvasAssign), opts.CanvasUser)
+	}
+	return nil
+}
+
+// defaultSavePath returns the file a test is saved in with /save when none
+// is given, next to the default results file.
+func defaultSavePath() string {
+	if path := results.DefaultPath(); path != "" {
+		return filepath.Join(filepath.Dir(path), "saved.json")
+	}
+	return ""
+}
+
+// showBestRun registers a handler that says whether a survival run beat
+// the best one saved in the results file.
+func showBestRun(test *quiz.Assessment, resultsFile string) {
+	history, err := results.Read(resultsFile)
+	if err != nil {
+		fmt.Println("Unable to read the best run:", err)
+		return
+	}
+	best, found := results.BestRun(history, test.FilePath, test.Lives)
+	test.OnFinished(func(a *quiz.Assessment) {
+		if a.TotalCorrect+a.TotalIncorrect == 0 {
+			return
+		}
+		switch {
+		case !found:
+			fmt.Fprintf(a.Out, "You survived %v questions, the first run to beat.\n", a.TotalCorrect)
+		case a.TotalCorrect > best.Correct:
+			fmt.Fprintf(a.Out, "You survived %v questions, a new record! The last best was %v by %s.\n", a.TotalCorrect, best.Correct, best.Name)
+		default:
+			fmt.Fprintf(a.Out, "You survived %v questions. The best run is %v by %s on %s.\n", a.TotalCorrect, best.Correct, best.Name, best.Started.Format("2 Jan 2006"))
+		}
+	})
+}
+
+// focus makes test ask count of its questions, or all of them if count is
+// 0, picked with the weights from results.MistakeWeights for the player
+// once their name is known.
+func focus(test *quiz.Assessment, count int, resultsFile string, seed int64) error {
+	if test.Source != nil {
+		return errors.New("-focus can't pick from questions the script generates as they are asked")
+	}
+	history, err := results.Read(resultsFile)
+	if err != nil {
+		return err
+	}
+	all := test.Questions
+	if count <= 0 || count > len(all) {
+		count = len(all)
+	}
+	test.TotalQuestions = count
+	test.OnQuizStart(func(a *quiz.Assessment) {
+		weights := results.MistakeWeights(history, a.Name, a.FilePath, time.Now())
+		a.Questions = quiz.WeightedSample(all, count, weights, rand.New(rand.NewSource(seed)))
+	})
+	return nil
+}
+
+// writeCertificate writes a certificate for name passing the quiz called
+// title to the -certificate file if score is at least pass.
+func writeCertificate(out io.Writer, opts *Options, name, title string, score, pass float64) {
+	if score < pass {
+		return
+	}
+	c := certificate.Certificate{Name: name, Title: title, Score: score, Pass: pass, Date: time.Now(), Logo: opts.Logo}
+	if err := c.Write(opts.Certificate); err != nil {
+		fmt.Fprintln(out, "Unable to write your certificate:", err)
+		return
+	}
+	fmt.Fprintf(out, "Congratulations, you passed! Your certificate is in %s.\n", opts.Certificate)
+}
+
+// signResult registers a handler that writes the result of test to path,
+// signed with the key at keyPath.
+func signResult(test *quiz.Assessment, keyPath, path string) error {
+	if keyPath == "" {
+		return errors.New("signing the result needs the -signkey the instructor installed on this machine")
+	}
+	key, err := results.LoadSigningKey(keyPath)
+	if err != nil {
+		return fmt.Errorf("unable to load the signing key: %w", err)
+	}
+	test.OnSubmit(func(a *quiz.Assessment) {
+		if a.TotalCorrect+a.TotalIncorrect == 0 {
+			return
+		}
+		if err := results.WriteSigned(path, results.FromAssessment(a), key); err != nil {
+			fmt.Fprintln(a.Out, "Unable to write your signed result:", err)
+			return
+		}
+		fmt.Fprintf(a.Out, "Your signed result is in %s.\n", path)
+	})
+	return nil
+}
+
+// saveBookmarks registers a handler that adds the questions bookmarked in
+// test to the question file at path, leaving out those already in it.
+func saveBookmarks(test *quiz.Assessment, path string) {
+	test.OnFinished(func(a *quiz.Assessment) {
+		bookmarks := a.Bookmarks()
+		if len(bookmarks) == 0 {
+			return
+		}
+		saved, err := loader.Load(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintln(a.Out, "Unable to save your bookmarks:", err)
+			return
+		}
+		seen := make(map[string]bool, len(saved))
+		for _, q := range saved {
+			seen[q.StableID()] = true
+		}
+		for _, q := range bookmarks {
+			if !seen[q.StableID()] {
+				saved = append(saved, quiz.Question{ID: q.ID, QText: q.QText, Answer: q.Answer, Choices: q.Choices, Hint: q.Hint, Explanation: q.Explanation, Points: q.Points, Category: q.Category, Difficulty: q.Difficulty, TimeLimit: q.TimeLimit, Translations: q.Translations})
+			}
+		}
+		if err = loader.Export(path, saved); err != nil {
+			fmt.Fprintln(a.Out, "Unable to save your bookmarks:", err)
+			return
+		}
+		warnUnsaved(path, saved)
+		fmt.Fprintf(a.Out, "Your bookmarks are saved in %s.\n", path)
+	})
+}
+
+// showBlitzScore writes the score for a blitz: the number of questions
+// answered correctly, less penalty for each wrong answer.
+func showBlitzScore(a *quiz.Assessment, penalty float64) {
+	score := float64(a.TotalCorrect) - penalty*float64(a.TotalIncorrect)
+	fmt.Fprintf(a.Out, "Your blitz score is %g: %v right", score, a.TotalCorrect)
+	if penalty != 0 {
+		fmt.Fprintf(a.Out, " and %v wrong at -%g each", a.TotalIncorrect, penalty)
+	}
+	fmt.Fprintf(a.Out, " in %s.\n", a.TimeLimit)
+}
+
+// sitExam sits the exam in the manifest opts.ExamFile, saving the results
+// of each section and writing a certificate if the candidate passed.
+func sitExam(ctx context.Context, opts *Options) error {
+	e, err := manifest.Load(ctx, opts.ExamFile, loader.Default, os.Stdout)
+	if err != nil {
+		return err
+	}
+	for _, test := range e.Tests() {
+		if opts.ResultsFile != "" {
+			results.RecordExam(test, opts.ResultsFile, e.Name())
+		}
+	}
+	err = e.Run(ctx, os.Stdin, os.Stdout)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	if err == nil && opts.Certificate != "" {
+		pass := e.Pass
+		if pass <= 0 {
+			pass = opts.Pass
+		}
+		title := opts.Title
+		if title == "" {
+			title = e.Name()
+		}
+		writeCertificate(os.Stdout, opts, e.Tests()[0].Name, title, e.Score(), pass)
+	}
+	return err
+}
+
+// playTournament starts a new tournament when players are given, or
+// carries on with the saved one otherwise.
+func playTournament(ctx context.Context, opts *Options, test *quiz.Assessment) (err error) {
+	var t *tournament.Tournament
+	if opts.Players != "" {
+		t, err = tournament.New(opts.TournamentFile, splitPlayers(opts.Players))
+	} else {
+		t, err = tournament.Load(opts.TournamentFile)
+	}
+	if err != nil {
+		return err
+	}
+	return t.Play(ctx, test)
+}
+
+// splitPlayers returns the names in a comma separated list of players.
+func splitPlayers(list string) []string {
+	var players []string
+	for _, p := range strings.Split(list, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			players = append(players, p)
+		}
+	}
+	return players
+}
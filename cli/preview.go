@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// preview shows each question of a file as it is asked, with its category,
+// points and answer, so authors can proof a file without playing it.
+func preview(ctx context.Context, args []string) (err error) {
+	flags := newFlagSet("preview")
+	answers := flags.Bool("answers", true, "Show the answers")
+	seed := flags.Int64("seed", 1, "Seed for the values of templates")
+	lang := flags.String("lang", "", "Language code of the translations to show, e.g. \"fr\".\nIf no language is provided the questions are shown as they are written.")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("preview needs the file to show")
+	}
+
+	questions, err := loader.Load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	r := rand.New(rand.NewSource(*seed))
+	for i, q := range questions {
+		if *lang != "" {
+			var ok bool
+			if q, ok = q.Translate(*lang); !ok {
+				fmt.Printf("%s:%v: there is no %q translation\n", flags.Arg(0), q.Line, *lang)
+			}
+		}
+		if err = q.ExpandTemplates(r); err != nil {
+			fmt.Printf("%s:%v: %v\n\n", flags.Arg(0), q.Line, err)
+			continue
+		}
+
+		showQuestion(os.Stdout, &q, i+1, *answers)
+	}
+	return nil
+}
+
+// showQuestion writes q as it is asked, with Prompt so it can't differ
+// from play, followed by its category and points, and its answer and
+// explanation if answer is true.
+func showQuestion(w io.Writer, q *quiz.Question, qnum int, answer bool) {
+	q.Prompt(w, qnum)
+	fmt.Fprintln(w)
+	if q.Category != "" {
+		fmt.Fprintf(w, "    Category: %s\n", q.Category)
+	}
+	if q.Points > 0 {
+		fmt.Fprintf(w, "    Points:   %v\n", q.Points)
+	}
+	if answer {
+		fmt.Fprintf(w, "    Answer:   %s\n", q.Answer)
+		if q.Explanation != "" {
+			fmt.Fprintf(w, "    Why:      %s\n", q.Explanation)
+		}
+	}
+	fmt.Fprintln(w)
+}
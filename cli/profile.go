@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// profile is where the -cpuprofile and -memprofile flags every command has
+// write the command's profiles, for go tool pprof.
+var profile struct {
+	cpuPath string   //File the CPU profile is written to, empty for none
+	memPath string   //File the heap profile is written to when the command ends, empty for none
+	cpu     *os.File //The CPU profile being written, nil when it isn't
+}
+
+// profileFlags adds the -cpuprofile and -memprofile flags to flags.
+func profileFlags(flags *flag.FlagSet) {
+	flags.StringVar(&profile.cpuPath, "cpuprofile", "", "Write a CPU profile of the command to this file, for go tool pprof")
+	flags.StringVar(&profile.memPath, "memprofile", "", "Write a memory profile to this file when the command ends, for go tool pprof")
+}
+
+// startProfile starts the CPU profile if -cpuprofile was given.
+func startProfile() error {
+	if profile.cpuPath == "" || profile.cpu != nil {
+		return nil
+	}
+	file, err := os.Create(profile.cpuPath)
+	if err != nil {
+		return err
+	}
+	if err = pprof.StartCPUProfile(file); err != nil {
+		file.Close()
+		return fmt.Errorf("unable to start the CPU profile: %w", err)
+	}
+	profile.cpu = file
+	return nil
+}
+
+// stopProfile finishes the CPU profile and writes the memory profile, if
+// they were asked for.
+func stopProfile() (err error) {
+	if profile.cpu != nil {
+		pprof.StopCPUProfile()
+		err = profile.cpu.Close()
+		profile.cpu = nil
+		if err != nil {
+			return err
+		}
+	}
+	if profile.memPath == "" {
+		return nil
+	}
+	file, err := os.Create(profile.memPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	runtime.GC() // so the profile shows the memory still in use
+	if err = pprof.WriteHeapProfile(file); err != nil {
+		return fmt.Errorf("unable to write the memory profile: %w", err)
+	}
+	return nil
+}
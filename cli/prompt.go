@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// prompter asks the user questions in the terminal, for the commands that
+// build question files.
+type prompter struct {
+	ctx context.Context
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPrompter(ctx context.Context) *prompter {
+	return &prompter{ctx: ctx, in: bufio.NewReader(os.Stdin), out: os.Stdout}
+}
+
+// ask shows text and returns the line the user types, trimmed.
+// io.EOF is only returned once there is no more input at all.
+func (p *prompter) ask(text string) (string, error) {
+	fmt.Fprint(p.out, text)
+	line, err := p.in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), p.ctx.Err()
+}
+
+// askDefault is like ask but shows the current value, which is kept if
+// the user leaves the line blank.  Typing "-" clears the value.
+func (p *prompter) askDefault(label, current string) (string, error) {
+	text := label + ": "
+	if current != "" {
+		text = fmt.Sprintf("%s [%s]: ", label, current)
+	}
+	v, err := p.ask(text)
+	switch {
+	case err != nil:
+		return current, err
+	case v == "-":
+		return "", nil
+	case v == "":
+		return current, nil
+	}
+	return v, nil
+}
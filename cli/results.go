@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/rastewart/go-quiz-game/results"
+)
+
+// resultsCmd lists the saved results of tests, or exports them as grades.
+func resultsCmd(ctx context.Context, args []string) (err error) {
+	flags := newFlagSet("results")
+	resultsFile := flags.String("results", results.DefaultPath(), "File the results of tests are saved in")
+	attempt := flags.String("attempt", "latest", "Which attempt to use for people who took a test more than once: "+strings.Join(results.Attempts, ", "))
+	out := flags.String("o", "", "File to export the grades to. A .xml file is written for Moodle's gradebook.")
+	assignment := flags.String("assignment", "", "ID number of the grade item in Moodle, for a .xml export")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	all, err := results.Read(*resultsFile)
+	if err != nil {
+		return err
+	}
+	picked, err := results.PickAttempt(results.ForBanks(all, flags.Args()), *attempt)
+	if err != nil {
+		return err
+	}
+	if len(picked) == 0 {
+		return fmt.Errorf("there are no results in %s", *resultsFile)
+	}
+
+	if *out == "" {
+		// Exam answers that may not be the candidate's own are flagged
+		suspicions := make([]string, len(picked))
+		flagged := false
+		for i, r := range picked {
+			suspicions[i] = strings.Join(results.Suspicions(r), "; ")
+			flagged = flagged || suspicions[i] != ""
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		header := []string{"Name", "Bank", "Finished", "Correct", "Total", "Score"}
+		if flagged {
+			header = append(header, "Flags")
+		}
+		table.SetHeader(header)
+		for i, r := range picked {
+			row := []string{r.Name, filepath.Base(r.Bank), r.Finished.Format("2006-01-02 15:04"), strconv.Itoa(r.Correct), strconv.Itoa(r.Total), fmt.Sprintf("%.2f%%", r.Score)}
+			if flagged {
+				row = append(row, suspicions[i])
+			}
+			table.Append(row)
+		}
+		table.Render()
+		return nil
+	}
+
+	if !strings.EqualFold(filepath.Ext(*out), ".xml") {
+		return fmt.Errorf("can't export grades to %s, only to a .xml file for Moodle", *out)
+	}
+	if *assignment == "" {
+		flags.Usage()
+		return errors.New("a Moodle export needs the -assignment ID number of the grade item")
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err = results.WriteMoodleXML(file, *assignment, picked); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote the grades of %v people to %s\n", len(picked), *out)
+	return nil
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// review steps through the questions of test showing their answers, to
+// read through a bank before taking the test.  Nothing is answered or
+// graded; ENTER shows the next question and q stops.
+func review(ctx context.Context, test *quiz.Assessment) error {
+	if len(test.Questions) == 0 {
+		return errors.New("there are no questions to review, since the script generates them as they are asked")
+	}
+	input := quiz.NewInput(os.Stdin)
+	defer input.Close()
+
+	fmt.Printf("Reviewing %v questions from %s. Press ENTER for the next question, or type q to stop.\n\n", len(test.Questions), test.FilePath)
+	wait := true
+	for i := range test.Questions {
+		showQuestion(os.Stdout, &test.Questions[i], i+1, true)
+		if !wait || i == len(test.Questions)-1 {
+			continue
+		}
+		line, err := input.ReadLine(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case errors.Is(err, io.EOF):
+			// With no one to press ENTER the rest are shown at once
+			wait = false
+		case err != nil:
+			return err
+		case strings.EqualFold(strings.TrimSpace(line), "q"):
+			return nil
+		}
+	}
+	fmt.Println("That's all the questions. Good luck in the test!")
+	return nil
+}
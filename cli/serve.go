@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/leaderboard"
+	"github.com/rastewart/go-quiz-game/lti"
+	"github.com/rastewart/go-quiz-game/metrics"
+	"github.com/rastewart/go-quiz-game/observer"
+	"github.com/rastewart/go-quiz-game/quiz"
+	"github.com/rastewart/go-quiz-game/results"
+	"github.com/rastewart/go-quiz-game/sshserver"
+	"github.com/rastewart/go-quiz-game/telegram"
+)
+
+// serve runs each of the servers that has an address or token given,
+// until one of them stops or ctx is cancelled.
+func serve(ctx context.Context, args []string) (err error) {
+	opts := &Options{}
+	flags := newFlagSet("serve")
+	opts.testFlags(flags)
+	flags.StringVar(&opts.LeaderboardAddr, "leaderboard", "", "Address to run a leaderboard server on, e.g. \":8080\"")
+	flags.StringVar(&opts.LeaderboardFile, "leaderboardfile", "leaderboard.json", "File the leaderboard server saves scores in")
+	flags.StringVar(&opts.TelegramToken, "telegramtoken", "", "Telegram Bot API token.\nWhen provided the quiz runs as a Telegram bot.")
+	flags.DurationVar(&opts.TelegramWindow, "telegramwindow", time.Second*20, "How long each question stays open for answers in Telegram chats")
+	flags.StringVar(&opts.TeamPool, "teampool", telegram.TeamPoolFirst, "How the answers of a team's members are pooled in Telegram chats.\n\"first\" counts the first answer and \"majority\" counts the most common answer.")
+	flags.StringVar(&opts.SSHAddr, "sshaddr", "", "Address to serve the quiz over ssh, e.g. \":2222\".\nWhen provided remote users can play with ssh.")
+	flags.StringVar(&opts.SSHHostKey, "sshhostkey", "quiz_host_key", "Host key for the ssh server. A new key is generated if the file doesn't exist.")
+	flags.IntVar(&opts.SSHMaxSessions, "sshmaxsessions", sshserver.DefaultMaxSessions, "Most ssh sessions played at once. Players who connect when it is full are turned away.")
+	flags.DurationVar(&opts.SSHIdleTimeout, "sshidletimeout", sshserver.DefaultIdleTimeout, "How long an ssh player can go without typing anything before they are disconnected")
+	flags.StringVar(&opts.ObserveAddr, "observeaddr", "", "Address to serve a live view of the ssh sessions on, e.g. \":8081\"")
+	flags.StringVar(&opts.ObserveToken, "observetoken", "", "Token an observer must pass as ?token= to see the live view")
+	flags.StringVar(&opts.MetricsAddr, "metricsaddr", "", "Address to serve Prometheus metrics for the Telegram bot and ssh server on, e.g. \":9090\"")
+	flags.StringVar(&opts.LTIAddr, "ltiaddr", "", "Address to run an LTI 1.3 tool on, e.g. \":8443\".\nWhen provided the quiz can be launched from an LMS, which is sent the grades.")
+	flags.StringVar(&opts.LTIURL, "ltiurl", "", "Public URL of the LTI tool, e.g. \"https://quiz.example.com\"")
+	flags.StringVar(&opts.LTIKey, "ltikey", "lti_key.pem", "Private key of the LTI tool. A new key is generated if the file doesn't exist.")
+	flags.StringVar(&opts.LTIPlatform.Issuer, "ltiissuer", "", "Issuer of the LMS that launches the LTI tool, e.g. \"https://moodle.example.com\"")
+	flags.StringVar(&opts.LTIPlatform.ClientID, "lticlientid", "", "Client ID the LMS gave the LTI tool")
+	flags.StringVar(&opts.LTIPlatform.AuthURL, "ltiauthurl", "", "The LMS's OpenID Connect authentication URL")
+	flags.StringVar(&opts.LTIPlatform.TokenURL, "ltitokenurl", "", "The LMS's access token URL, for sending grades")
+	flags.StringVar(&opts.LTIPlatform.JWKSURL, "ltijwksurl", "", "The LMS's public keyset URL")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	var servers []func() error
+	var m *metrics.Metrics
+	if opts.MetricsAddr != "" {
+		m = metrics.New(opts.MetricsAddr)
+		servers = append(servers, func() error {
+			return fmt.Errorf("metrics server stopped: %w", m.ListenAndServe())
+		})
+	}
+
+	if opts.LeaderboardAddr != "" {
+		server := leaderboard.Server{Addr: opts.LeaderboardAddr, File: opts.LeaderboardFile, Token: opts.LeaderboardToken}
+		servers = append(servers, func() error {
+			return fmt.Errorf("leaderboard server stopped: %w", server.ListenAndServe())
+		})
+	}
+
+	if opts.TelegramToken != "" {
+		test, err := opts.newAssessment(ctx)
+		if err != nil {
+			return err
+		}
+		bot := telegram.New(opts.TelegramToken, test.Questions)
+		bot.Window = opts.TelegramWindow
+		bot.TeamPool = opts.TeamPool
+		bot.Metrics = m
+		servers = append(servers, func() error {
+			return fmt.Errorf("telegram bot stopped: %w", bot.Run(ctx))
+		})
+	}
+
+	if opts.SSHAddr != "" {
+		// The questions are loaded once and each player takes a session of them
+		test, err := opts.newAssessment(ctx)
+		if err != nil {
+			return err
+		}
+		if err = opts.recordScores(test); err != nil {
+			return err
+		}
+		server := sshserver.Server{
+			Addr:        opts.SSHAddr,
+			HostKey:     opts.SSHHostKey,
+			Test:        test,
+			Play:        playRounds,
+			Metrics:     m,
+			MaxSessions: opts.SSHMaxSessions,
+			IdleTimeout: opts.SSHIdleTimeout,
+		}
+		if opts.ObserveAddr != "" {
+			server.Observer = observer.New(opts.ObserveAddr, opts.ObserveToken)
+		}
+		servers = append(servers, func() error {
+			return fmt.Errorf("ssh server stopped: %w", server.ListenAndServe())
+		})
+	}
+
+	if opts.LTIAddr != "" {
+		p := opts.LTIPlatform
+		if opts.LTIURL == "" || p.Issuer == "" || p.ClientID == "" || p.AuthURL == "" || p.TokenURL == "" || p.JWKSURL == "" {
+			return errors.New("the LTI tool needs -ltiurl, -ltiissuer, -lticlientid, -ltiauthurl, -ltitokenurl and -ltijwksurl")
+		}
+		test, err := opts.newAssessment(ctx)
+		if err != nil {
+			return err
+		}
+		tool := &lti.Tool{
+			Addr:     opts.LTIAddr,
+			URL:      strings.TrimSuffix(opts.LTIURL, "/"),
+			KeyFile:  opts.LTIKey,
+			Platform: p,
+			Test:     test,
+		}
+		if opts.ResultsFile != "" {
+			tool.OnFinished = func(a *quiz.Assessment) {
+				if err := results.Append(opts.ResultsFile, results.FromAssessment(a)); err != nil {
+					log.Printf("unable to save the results of %s: %v", a.Name, err)
+				}
+			}
+		}
+		servers = append(servers, func() error {
+			return fmt.Errorf("LTI tool stopped: %w", tool.ListenAndServe())
+		})
+	}
+
+	if len(servers) == 0 || (len(servers) == 1 && m != nil) {
+		return errors.New("there is nothing to serve, give -leaderboard, -telegramtoken, -sshaddr or -ltiaddr")
+	}
+
+	stopped := make(chan error, len(servers))
+	for _, s := range servers {
+		go func() { stopped <- s() }()
+	}
+	select {
+	case err = <-stopped:
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		return nil
+	}
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/results"
+)
+
+// stats shows how many questions of each kind are in question files, or
+// with -item-analysis how well each question has worked in past tests.
+func stats(ctx context.Context, args []string) (err error) {
+	flags := newFlagSet("stats")
+	itemAnalysis := flags.Bool("item-analysis", false, "Show the difficulty and discrimination of each question from the saved results,\nflagging questions that may be broken or ambiguous. Files limit it to tests of those files.")
+	resultsFile := flags.String("results", results.DefaultPath(), "File the results of tests are saved in")
+	minAttempts := flags.Int("minattempts", 5, "Questions answered fewer times than this aren't flagged")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *itemAnalysis {
+		return showItemAnalysis(*resultsFile, flags.Args(), *minAttempts)
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("stats needs at least one file")
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"File", "Questions", "Multiple Choice", "Free Text", "Avg Choices"})
+	for _, path := range flags.Args() {
+		questions, err := loader.Load(path)
+		if err != nil {
+			return err
+		}
+
+		choice, options := 0, 0
+		for _, q := range questions {
+			if len(q.Choices) > 0 {
+				choice++
+				options += len(q.Options())
+			}
+		}
+		avg := "-"
+		if choice > 0 {
+			avg = fmt.Sprintf("%.1f", float64(options)/float64(choice))
+		}
+		table.Append([]string{path, strconv.Itoa(len(questions)), strconv.Itoa(choice), strconv.Itoa(len(questions) - choice), avg})
+	}
+	table.Render()
+	return nil
+}
+
+// showItemAnalysis shows the item analysis of the results of tests of
+// banks, or of every test if no banks are given.
+func showItemAnalysis(path string, banks []string, minAttempts int) error {
+	all, err := results.Read(path)
+	if err != nil {
+		return err
+	}
+	picked := results.ForBanks(all, banks)
+	if len(picked) == 0 {
+		return fmt.Errorf("there are no results in %s to analyse", path)
+	}
+
+	items := results.ItemAnalysis(picked, minAttempts)
+	fmt.Printf("%v questions answered in %v tests\n", len(items), len(picked))
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Question", "Answer", "Attempts", "Correct", "Discrimination", "Flags"})
+	flagged := 0
+	for _, it := range items {
+		disc := "-"
+		if !math.IsNaN(it.Discrimination) {
+			disc = fmt.Sprintf("%.2f", it.Discrimination)
+		}
+		if len(it.Flags) > 0 {
+			flagged++
+		}
+		table.Append([]string{it.Question, it.Answer, strconv.Itoa(it.Attempts), fmt.Sprintf("%.0f%%", it.Difficulty*100), disc, strings.Join(it.Flags, "; ")})
+	}
+	table.Render()
+	if flagged > 0 {
+		fmt.Printf("%v questions may be broken or ambiguous\n", flagged)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rastewart/go-quiz-game/loader"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// validate checks each question file for rows that can't be read and
+// questions that can't be asked, and reports them with their line numbers.
+func validate(ctx context.Context, args []string) (err error) {
+	flags := newFlagSet("validate")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("validate needs at least one file to check")
+	}
+
+	failed := 0
+	for _, path := range flags.Args() {
+		problems, count, err := checkFile(path)
+		if err != nil {
+			fmt.Printf("%s: %v\n", path, err)
+			failed++
+			continue
+		}
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		if len(problems) > 0 {
+			fmt.Printf("%s: %v problems in %v questions\n", path, len(problems), count)
+			failed++
+		} else {
+			fmt.Printf("%s: %v questions OK\n", path, count)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%v of %v files are not valid", failed, flags.NArg())
+	}
+	return nil
+}
+
+// checkFile loads a question file and returns the problems with its rows
+// and questions, in line order, and how many questions it has.  The error
+// is for a file that can't be read at all.
+func checkFile(path string) (problems []*loader.RowError, count int, err error) {
+	questions, err := loader.Load(path)
+	rows := loader.RowErrors(err)
+	if err != nil && len(rows) == 0 {
+		return nil, 0, err
+	}
+	if len(questions)+len(rows) == 0 {
+		return nil, 0, errors.New("there are no questions")
+	}
+
+	problems = append(rows, checkQuestions(path, questions)...)
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return problems, len(questions) + len(rows), nil
+}
+
+// checkQuestions returns the problems with questions that loaded but
+// can't be asked properly.
+func checkQuestions(path string, questions []quiz.Question) (problems []*loader.RowError) {
+	problem := func(q quiz.Question, format string, args ...any) {
+		problems = append(problems, &loader.RowError{Path: path, Line: q.Line, Err: fmt.Errorf(format, args...)})
+	}
+
+	seen := make(map[string]quiz.Question)
+	ids := make(map[string]quiz.Question)
+	for _, q := range questions {
+		if first, ok := ids[q.ID]; ok && q.ID != "" {
+			problem(q, "the ID %q is already used by the question on line %v", q.ID, first.Line)
+		} else {
+			ids[q.ID] = q
+		}
+		if strings.TrimSpace(q.QText) == "" {
+			problem(q, "the question is empty")
+		}
+		if strings.TrimSpace(q.Answer) == "" {
+			problem(q, "the answer is empty")
+		}
+
+		key := normalize(q.QText)
+		if first, ok := seen[key]; ok && key != "" {
+			problem(q, "the question is a duplicate of the question on line %v", first.Line)
+		} else {
+			seen[key] = q
+		}
+
+		choices := make(map[string]bool)
+		for _, c := range q.Choices {
+			if choices[normalize(c)] {
+				problem(q, "the choice %q is given more than once", c)
+			}
+			choices[normalize(c)] = true
+		}
+		for _, lang := range quiz.Languages([]quiz.Question{q}) {
+			if t := q.Translations[lang]; len(t.Choices) > 0 && len(t.Choices) != len(q.Choices) {
+				problem(q, "the %q translation has %v choices but the question has %v", lang, len(t.Choices), len(q.Choices))
+			}
+		}
+		if n := len(q.Options()); n > 10 {
+			problem(q, "there are %v choices, no more than 10 can be shown as a Telegram poll", n)
+		}
+		if q.Category != "" && strings.TrimSpace(q.Category) == "" {
+			problem(q, "the category is only whitespace")
+		}
+		expanded := q
+		expanded.Choices = slices.Clone(q.Choices)
+		if err := expanded.ExpandTemplates(rand.New(rand.NewSource(1))); err != nil {
+			problem(q, "%v", err)
+		}
+	}
+	return problems
+}
+
+// normalize returns text in lower case with runs of spaces collapsed, so
+// questions that only differ in case or spacing compare equal.
+func normalize(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
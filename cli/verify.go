@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/rastewart/go-quiz-game/results"
+)
+
+// verify checks signed result files weren't changed since they were signed,
+// or with -genkey generates the key for signing them.
+func verify(ctx context.Context, args []string) (err error) {
+	flags := newFlagSet("verify")
+	keyPath := flags.String("key", "", "Public key the results were signed with, the .pub file written next to the -signkey")
+	genKey := flags.String("genkey", "", "File to save a new private key in for \"play -signkey\", with its public key in the file with .pub added")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *genKey != "" {
+		if err = results.GenerateSigningKey(*genKey); err != nil {
+			return fmt.Errorf("unable to generate the signing key: %w", err)
+		}
+		fmt.Printf("Generated a new signing key in %s. Results are checked with the public key in %s.pub.\n", *genKey, *genKey)
+		return nil
+	}
+	if flags.NArg() == 0 || *keyPath == "" {
+		flags.Usage()
+		return errors.New("verify needs the -key and the signed result files to check")
+	}
+	key, err := results.LoadPublicKey(*keyPath)
+	if err != nil {
+		return err
+	}
+
+	bad := 0
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"File", "Name", "Bank", "Finished", "Correct", "Total", "Score", "Signature"})
+	for _, path := range flags.Args() {
+		s, err := results.ReadSigned(path)
+		if err != nil {
+			return err
+		}
+		r, err := s.Verify(key)
+		switch {
+		case errors.Is(err, results.ErrBadSignature):
+			bad++
+			table.Append([]string{path, "", "", "", "", "", "", "CHANGED OR FORGED"})
+		case err != nil:
+			return fmt.Errorf("%s: %w", path, err)
+		default:
+			table.Append([]string{path, r.Name, filepath.Base(r.Bank), r.Finished.Format("2006-01-02 15:04"), strconv.Itoa(r.Correct), strconv.Itoa(r.Total), fmt.Sprintf("%.2f%%", r.Score), "valid"})
+		}
+	}
+	table.Render()
+	if bad > 0 {
+		return fmt.Errorf("%v of the %v results weren't signed with %s or were changed after they were signed", bad, flags.NArg(), *keyPath)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// The version of the quiz, set when it is built, e.g.
+//
+//	go build -ldflags "-X github.com/rastewart/go-quiz-game/cli.Version=v1.2.0 -X github.com/rastewart/go-quiz-game/cli.Commit=$(git rev-parse HEAD) -X github.com/rastewart/go-quiz-game/cli.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Any left empty are filled in from the build info Go embeds in the binary.
+var (
+	Version string //Semantic version, e.g. "v1.2.0"
+	Commit  string //Git commit the binary was built from
+	Date    string //When the binary was built, or the time of the commit
+)
+
+// BuildInfo returns the version, commit and build date of the binary.
+func BuildInfo() (version, commit, date string) {
+	version, commit, date = Version, Commit, Date
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return orUnknown(version), orUnknown(commit), orUnknown(date)
+	}
+
+	// go install module@version records the module's version, and a build
+	// in a git checkout records the commit
+	if version == "" && info.Main.Version != "(devel)" {
+		version = info.Main.Version
+	}
+	// The settings can come in any order, so the revision goes in front of
+	// a "-dirty" that is already there
+	for _, s := range info.Settings {
+		switch {
+		case s.Key == "vcs.revision" && Commit == "":
+			commit = s.Value + commit
+		case s.Key == "vcs.modified" && Commit == "" && s.Value == "true":
+			commit += "-dirty"
+		case s.Key == "vcs.time" && date == "":
+			date = s.Value
+		}
+	}
+	return orUnknown(version), orUnknown(commit), orUnknown(date)
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+// version prints the version, commit and build date of the binary.
+func version(ctx context.Context, args []string) (err error) {
+	flags := newFlagSet("version")
+	if err = flags.Parse(args); err != nil {
+		return err
+	}
+
+	v, commit, date := BuildInfo()
+	fmt.Printf("quiz %s\n", v)
+	fmt.Printf("commit: %s\n", commit)
+	fmt.Printf("built:  %s\n", date)
+	fmt.Printf("go:     %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	return nil
+}
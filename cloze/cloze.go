@@ -0,0 +1,256 @@
+// Package cloze makes fill in the blank questions from a study document.
+// Each sentence of the document becomes a question with one of its key
+// terms blanked out.  Key terms are found with simple rules rather than a
+// language model: runs of capitalized words such as names and places, and
+// numbers such as years and quantities.  The questions are meant to be
+// reviewed and edited before they are used.
+package cloze
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// Blank replaces the key term in the text of a question.
+const Blank = "_____"
+
+// Options control which sentences become questions.
+type Options struct {
+	MinWords int  //Sentences with fewer words are skipped
+	MaxWords int  //Sentences with more words are skipped, 0 for no limit
+	AllTerms bool //Make a question for every key term in a sentence rather than only the best one
+}
+
+// DefaultOptions returns the options used when none are given.
+func DefaultOptions() Options {
+	return Options{MinWords: 5, MaxWords: 40}
+}
+
+// sentenceEnd matches the end of a sentence: a full stop, question or
+// exclamation mark followed by space.
+var sentenceEnd = regexp.MustCompile(`[.!?]["')\]]*\s+`)
+
+// number matches numbers, including years, decimals, thousands and percentages.
+var number = regexp.MustCompile(`\d[\d,]*(\.\d+)?%?`)
+
+// abbreviations don't end a sentence even though they end with a full stop.
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "st": true, "mt": true,
+	"e.g": true, "i.e": true, "etc": true, "vs": true, "no": true, "approx": true, "c": true, "ca": true,
+}
+
+// connectors may join the capitalized words of a name, e.g. Bank of England.
+var connectors = map[string]bool{"of": true, "the": true, "de": true, "la": true, "von": true, "van": true}
+
+// sentence is a sentence of the document and the line it starts on.
+type sentence struct {
+	text string
+	line int
+}
+
+// term is a key term in a sentence, at text[start:end].
+type term struct {
+	start, end int
+	words      int  //Number of words in the term
+	name       bool //Whether the term is a name rather than a number
+}
+
+// Generate makes questions from the sentences of text.  The Line of each
+// question is the line of text its sentence starts on.
+func Generate(text string, opts Options) []quiz.Question {
+	sentences := split(text)
+	names := properNouns(sentences)
+
+	var questions []quiz.Question
+	for _, s := range sentences {
+		words := len(strings.Fields(s.text))
+		if words < opts.MinWords || opts.MaxWords > 0 && words > opts.MaxWords {
+			continue
+		}
+		terms := keyTerms(s.text, names)
+		if len(terms) == 0 {
+			continue
+		}
+		if !opts.AllTerms {
+			terms = []term{best(terms)}
+		}
+		for _, t := range terms {
+			questions = append(questions, quiz.Question{
+				QText:  s.text[:t.start] + Blank + s.text[t.end:],
+				Answer: s.text[t.start:t.end],
+				Line:   s.line,
+			})
+		}
+	}
+	return questions
+}
+
+// split splits text into sentences, joining the lines of each one.
+// Blank lines end a sentence, so headings aren't joined to the paragraph
+// after them.
+func split(text string) []sentence {
+	var sentences []sentence
+	for _, para := range paragraphs(text) {
+		line, rest := para.line, para.text
+		for rest != "" {
+			end := len(rest)
+			for _, m := range sentenceEnd.FindAllStringIndex(rest, -1) {
+				if !abbreviation(rest[:m[0]]) {
+					end = m[1]
+					break
+				}
+			}
+			s := strings.Join(strings.Fields(rest[:end]), " ")
+			if s != "" {
+				sentences = append(sentences, sentence{s, line})
+			}
+			line += strings.Count(rest[:end], "\n")
+			rest = rest[end:]
+		}
+	}
+	return sentences
+}
+
+// paragraphs returns the paragraphs of text, which are separated by
+// blank lines, and the lines they start on.
+func paragraphs(text string) []sentence {
+	var paras []sentence
+	var lines []string
+	start := 0
+	for n, l := range strings.Split(text, "\n") {
+		if strings.TrimSpace(l) == "" {
+			if len(lines) > 0 {
+				paras = append(paras, sentence{strings.Join(lines, "\n"), start})
+			}
+			lines = nil
+			continue
+		}
+		if len(lines) == 0 {
+			start = n + 1
+		}
+		lines = append(lines, l)
+	}
+	if len(lines) > 0 {
+		paras = append(paras, sentence{strings.Join(lines, "\n"), start})
+	}
+	return paras
+}
+
+// abbreviation reports whether text ends with an abbreviation, so the
+// full stop after it doesn't end the sentence.
+func abbreviation(text string) bool {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return false
+	}
+	last := strings.ToLower(strings.TrimLeft(fields[len(fields)-1], "(\"'"))
+	// Initials, as in J. R. R. Tolkien
+	return abbreviations[last] || len([]rune(last)) == 1 && unicode.IsLetter([]rune(last)[0])
+}
+
+// properNouns returns the capitalized words that appear in the middle of
+// a sentence, so a capitalized word at the start of a sentence can be
+// told apart from a name.
+func properNouns(sentences []sentence) map[string]bool {
+	names := make(map[string]bool)
+	for _, s := range sentences {
+		for i, w := range words(s.text) {
+			if i > 0 && capitalized(w.text) {
+				names[w.text] = true
+			}
+		}
+	}
+	return names
+}
+
+// word is a word in a sentence, at text[start:end].
+type word struct {
+	text       string
+	start, end int
+}
+
+// wordPattern matches a word, which may have hyphens or apostrophes in it.
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+(['’-][\p{L}\p{N}]+)*`)
+
+// words returns the words in text.
+func words(text string) []word {
+	var ws []word
+	for _, m := range wordPattern.FindAllStringIndex(text, -1) {
+		ws = append(ws, word{text[m[0]:m[1]], m[0], m[1]})
+	}
+	return ws
+}
+
+// capitalized reports whether w starts with an upper case letter.  "I" is
+// not counted, since it isn't a key term.
+func capitalized(w string) bool {
+	r := []rune(w)
+	return unicode.IsUpper(r[0]) && w != "I"
+}
+
+// keyTerms returns the key terms in a sentence: runs of capitalized words,
+// which may be joined by connectors like "of", and numbers.
+func keyTerms(text string, names map[string]bool) []term {
+	var terms []term
+	ws := words(text)
+	for i := 0; i < len(ws); i++ {
+		if !capitalized(ws[i].text) {
+			continue
+		}
+		j := i
+		for j+1 < len(ws) {
+			next := j + 1
+			for next < len(ws) && connectors[ws[next].text] {
+				next++
+			}
+			if next >= len(ws) || !capitalized(ws[next].text) || !spaced(text, ws[j:next+1]) {
+				break
+			}
+			j = next
+		}
+		// A single capitalized word at the start of a sentence is only a
+		// name if it is capitalized elsewhere too
+		if i == 0 && j == 0 && !names[ws[0].text] {
+			continue
+		}
+		terms = append(terms, term{start: ws[i].start, end: ws[j].end, words: j - i + 1, name: true})
+		i = j
+	}
+
+	for _, m := range number.FindAllStringIndex(text, -1) {
+		end := m[1]
+		for end > m[0] && text[end-1] == ',' {
+			end--
+		}
+		terms = append(terms, term{start: m[0], end: end, words: 1})
+	}
+	sort.Slice(terms, func(i, j int) bool { return terms[i].start < terms[j].start })
+	return terms
+}
+
+// spaced reports whether there is only space between the words ws in
+// text, so they can belong to the same name.
+func spaced(text string, ws []word) bool {
+	for i := 1; i < len(ws); i++ {
+		if strings.TrimSpace(text[ws[i-1].end:ws[i].start]) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// best returns the term most worth asking about: the longest name, or
+// the first number if there are no names.
+func best(terms []term) term {
+	b := terms[0]
+	for _, t := range terms[1:] {
+		if t.name && (!b.name || t.words > b.words) {
+			b = t
+		}
+	}
+	return b
+}
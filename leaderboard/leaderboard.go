@@ -0,0 +1,265 @@
+// Package leaderboard is a central leaderboard that quiz clients push their
+// scores to and pull the global top scores from.
+package leaderboard
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Score is a single result posted to the leaderboard.
+type Score struct {
+	Name      string    `json:"name"`      //Name of the user who took the quiz
+	Quiz      string    `json:"quiz"`      //Name of the question file the quiz was loaded from
+	Correct   int       `json:"correct"`   //Number of questions answered correctly
+	Total     int       `json:"total"`     //Number of questions in the quiz
+	Seconds   float64   `json:"seconds"`   //How long the user took
+	Submitted time.Time `json:"submitted"` //When the score was received by the server
+}
+
+// Percent returns the score as a percentage.
+func (s Score) Percent() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Correct) / float64(s.Total) * 100
+}
+
+// DefaultMaxScores is the number of scores kept for each quiz when the
+// Server's MaxScores isn't set.
+const DefaultMaxScores = 1000
+
+// maxScoreBytes is the largest score the server reads.  A score is a
+// few hundred bytes, so anything bigger isn't one.
+const maxScoreBytes = 4 << 10
+
+// Server is a simple HTTP API for collecting scores from many
+// clients and returning the top scores.  Scores are saved to a JSON file
+// so they survive restarts.  Only the best MaxScores scores for each quiz
+// are kept, so the file can't grow without limit.
+//
+//	POST /scores?token=      submit a Score as JSON
+//	GET  /scores?quiz=&top=  list the top scores, best first
+type Server struct {
+	Addr      string //Address the server listens on
+	File      string //JSON file the scores are saved in
+	Token     string //When set the token must be passed as ?token= to submit a score
+	MaxScores int    //Number of scores kept for each quiz, the best ones. DefaultMaxScores if 0
+
+	mu     sync.Mutex
+	scores []Score
+}
+
+// ListenAndServe loads any saved scores and serves the API.
+func (s *Server) ListenAndServe() error {
+	data, err := os.ReadFile(s.File)
+	if err == nil {
+		if err = json.Unmarshal(data, &s.scores); err != nil {
+			return fmt.Errorf("unable to read %s: %v", s.File, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/scores", s.handleScores)
+
+	fmt.Printf("Leaderboard server is listening on %s. Press Ctrl+C to stop.\n", s.Addr)
+	return http.ListenAndServe(s.Addr, mux)
+}
+
+func (s *Server) handleScores(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		top, _ := strconv.Atoi(r.URL.Query().Get("top"))
+		writeJSON(w, s.top(r.URL.Query().Get("quiz"), top))
+
+	case http.MethodPost:
+		if s.Token != "" && subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(s.Token)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		var score Score
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxScoreBytes)).Decode(&score); err != nil {
+			http.Error(w, "invalid score: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if score.Name == "" || score.Total <= 0 || score.Correct < 0 || score.Correct > score.Total {
+			http.Error(w, "invalid score", http.StatusBadRequest)
+			return
+		}
+		score.Submitted = time.Now()
+
+		if err := s.add(score); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, score)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// add records a score and saves all the scores to disk.  The score is
+// only recorded if they are saved.
+func (s *Server) add(score Score) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scores := s.keepBest(append(s.scores, score), score.Quiz)
+	data, err := json.MarshalIndent(scores, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = writeFile(s.File, data); err != nil {
+		return err
+	}
+	s.scores = scores
+	return nil
+}
+
+// keepBest drops the worst scores for quiz from scores once it has more
+// than MaxScores.
+func (s *Server) keepBest(scores []Score, quiz string) []Score {
+	limit := s.MaxScores
+	if limit <= 0 {
+		limit = DefaultMaxScores
+	}
+	var kept, forQuiz []Score
+	for _, v := range scores {
+		if v.Quiz == quiz {
+			forQuiz = append(forQuiz, v)
+		} else {
+			kept = append(kept, v)
+		}
+	}
+	if len(forQuiz) <= limit {
+		return scores
+	}
+	sortScores(forQuiz)
+	return append(kept, forQuiz[:limit]...)
+}
+
+// writeFile replaces the file at path with data, writing it to a temporary
+// file first so the scores are never left half written if the server stops.
+func writeFile(path string, data []byte) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	return err
+}
+
+// top returns the best n scores for a quiz, or for every quiz if quiz is
+// empty.  All matching scores are returned if n is 0.
+func (s *Server) top(quiz string, n int) []Score {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scores := []Score{}
+	for _, v := range s.scores {
+		if quiz == "" || v.Quiz == quiz {
+			scores = append(scores, v)
+		}
+	}
+	sortScores(scores)
+
+	if n > 0 && n < len(scores) {
+		scores = scores[:n]
+	}
+	return scores
+}
+
+// sortScores orders scores by percentage and then by the fastest time.
+func sortScores(scores []Score) {
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].Percent() != scores[j].Percent() {
+			return scores[i].Percent() > scores[j].Percent()
+		}
+		return scores[i].Seconds < scores[j].Seconds
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// Client pushes scores to and pulls scores from a Server.
+type Client struct {
+	URL    string //Base URL of the leaderboard server
+	Token  string //Token the server needs to accept scores, see Server.Token
+	client *http.Client
+}
+
+// NewClient creates a client for the server at baseURL.
+func NewClient(baseURL string) *Client {
+	return &Client{URL: baseURL, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Push submits a score to the server.
+func (c *Client) Push(score Score) error {
+	body, err := json.Marshal(score)
+	if err != nil {
+		return err
+	}
+	u := c.URL + "/scores"
+	if c.Token != "" {
+		u += "?" + url.Values{"token": {c.Token}}.Encode()
+	}
+	resp, err := c.client.Post(u, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("leaderboard server returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// Top fetches the best n scores for a quiz from the server.
+func (c *Client) Top(quiz string, n int) (scores []Score, err error) {
+	query := url.Values{"quiz": {quiz}, "top": {strconv.Itoa(n)}}
+	resp, err := c.client.Get(c.URL + "/scores?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("leaderboard server returned %s", resp.Status)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&scores)
+	return scores, err
+}
@@ -0,0 +1,110 @@
+package leaderboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSubmitToken(t *testing.T) {
+	dir := t.TempDir()
+	s := &Server{File: filepath.Join(dir, "scores.json"), Token: "secret"}
+	srv := httptest.NewServer(http.HandlerFunc(s.handleScores))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "no token", wantErr: true},
+		{name: "wrong token", token: "guess", wantErr: true},
+		{name: "token", token: "secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(srv.URL)
+			c.Token = tt.token
+			err := c.Push(Score{Name: "Ann", Quiz: "capitals.csv", Correct: 3, Total: 4})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Push() = %v, want an error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Only the score with the token is saved, and it can be read back by anyone
+	scores, err := NewClient(srv.URL).Top("capitals.csv", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 1 || scores[0].Name != "Ann" {
+		t.Errorf("Top() = %+v, want Ann's score", scores)
+	}
+	data, err := os.ReadFile(s.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved []Score
+	if err = json.Unmarshal(data, &saved); err != nil || len(saved) != 1 {
+		t.Errorf("the file has %s, want Ann's score", data)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("%v files were left in the directory, want only the scores", len(files))
+	}
+}
+
+func TestSaveFails(t *testing.T) {
+	s := &Server{File: filepath.Join(t.TempDir(), "missing", "scores.json")}
+	if err := s.add(Score{Name: "Ann", Correct: 1, Total: 1}); err == nil {
+		t.Fatal("add() saved to a directory that doesn't exist")
+	}
+	if len(s.top("", 0)) != 0 {
+		t.Error("a score that wasn't saved was recorded")
+	}
+}
+
+func TestMaxScores(t *testing.T) {
+	s := &Server{File: filepath.Join(t.TempDir(), "scores.json"), MaxScores: 2}
+	for i, score := range []Score{
+		{Name: "Ann", Quiz: "capitals.csv", Correct: 2, Total: 4},
+		{Name: "Bob", Quiz: "capitals.csv", Correct: 4, Total: 4},
+		{Name: "Cat", Quiz: "rivers.csv", Correct: 1, Total: 4},
+		{Name: "Dan", Quiz: "capitals.csv", Correct: 3, Total: 4},
+		{Name: "Eve", Quiz: "capitals.csv", Correct: 1, Total: 4},
+	} {
+		if err := s.add(score); err != nil {
+			t.Fatalf("add() of score %v: %v", i+1, err)
+		}
+	}
+
+	var names []string
+	for _, v := range s.top("", 0) {
+		names = append(names, v.Name)
+	}
+	if got := strings.Join(names, ","); got != "Bob,Dan,Cat" {
+		t.Errorf("the scores kept are %s, want Bob,Dan,Cat", got)
+	}
+}
+
+func TestPostTooLarge(t *testing.T) {
+	s := &Server{File: filepath.Join(t.TempDir(), "scores.json")}
+	srv := httptest.NewServer(http.HandlerFunc(s.handleScores))
+	defer srv.Close()
+
+	body := `{"name": "` + strings.Repeat("x", maxScoreBytes) + `", "quiz": "capitals.csv", "correct": 1, "total": 1}`
+	resp, err := http.Post(srv.URL+"/scores", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status %v, want %v", resp.StatusCode, http.StatusBadRequest)
+	}
+	if len(s.top("", 0)) != 0 {
+		t.Error("a score too large to read was recorded")
+	}
+}
@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// GenerateOptions describe the questions to generate.
+type GenerateOptions struct {
+	Topic      string //What the questions are about, e.g. "Go concurrency"
+	Count      int    //Number of questions
+	Choices    int    //Number of wrong choices for each question, 0 for free answer questions
+	Difficulty string //How hard the questions should be, e.g. "easy", empty for a mix
+}
+
+// batchSize is the most questions asked for at once, since models lose
+// track of long lists.
+const batchSize = 10
+
+const generateSystem = `You write quiz questions for teachers, who review them before use.
+Reply with only a JSON array of questions, each an object with these fields:
+  "question": the question, one sentence
+  "answer": the correct answer, as short as possible, ideally a word, name or number, so a typed answer can match it exactly
+  "choices": the wrong answers for a multiple choice question, plausible but definitely wrong
+  "hint": a hint that helps without giving the answer away
+  "difficulty": "easy", "medium" or "hard"
+Every fact must be correct.  Don't repeat questions.`
+
+// Generate asks the model to draft questions about a topic.  The questions
+// are asked for in batches, and each batch is told the questions it
+// already has so they aren't repeated.  Fewer than opts.Count questions
+// may be returned if the model stops making new ones.
+func (c *Client) Generate(ctx context.Context, opts GenerateOptions) ([]quiz.Question, error) {
+	if strings.TrimSpace(opts.Topic) == "" {
+		return nil, errors.New("there is no topic to write questions about")
+	}
+	var questions []quiz.Question
+	seen := make(map[string]bool)
+	for tries := 0; len(questions) < opts.Count && tries < opts.Count/batchSize+3; tries++ {
+		n := min(opts.Count-len(questions), batchSize)
+		reply, err := c.Complete(ctx, generateSystem, generatePrompt(opts, n, questions))
+		if err != nil {
+			return questions, err
+		}
+		batch, err := parseQuestions(reply)
+		if err != nil {
+			return questions, err
+		}
+		added := 0
+		for _, q := range batch {
+			key := strings.ToLower(strings.TrimSpace(q.QText))
+			if q.QText == "" || q.Answer == "" || seen[key] || len(questions) == opts.Count {
+				continue
+			}
+			seen[key] = true
+			q.Category = opts.Topic
+			if opts.Choices == 0 {
+				q.Choices = nil
+			}
+			questions = append(questions, q)
+			added++
+		}
+		if added == 0 {
+			break
+		}
+	}
+	return questions, nil
+}
+
+// generatePrompt asks for n more questions, listing the ones there are so
+// far so they aren't repeated.
+func generatePrompt(opts GenerateOptions, n int, have []quiz.Question) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Write %v quiz questions about %s.\n", n, opts.Topic)
+	if opts.Choices > 0 {
+		fmt.Fprintf(&b, "Make them multiple choice with %v wrong choices each.\n", opts.Choices)
+	} else {
+		b.WriteString("Make them free answer questions with no choices.\n")
+	}
+	if opts.Difficulty != "" {
+		fmt.Fprintf(&b, "Make them %s.\n", opts.Difficulty)
+	}
+	if len(have) > 0 {
+		b.WriteString("These questions have already been written, so ask about something else:\n")
+		for _, q := range have {
+			fmt.Fprintf(&b, "- %s\n", q.QText)
+		}
+	}
+	return b.String()
+}
+
+// parseQuestions reads the questions in a reply.
+func parseQuestions(reply string) ([]quiz.Question, error) {
+	var drafts []struct {
+		Question   string `json:"question"`
+		Answer     any    `json:"answer"`
+		Choices    []any  `json:"choices"`
+		Hint       string `json:"hint"`
+		Difficulty string `json:"difficulty"`
+	}
+	if err := json.Unmarshal([]byte(JSON(reply)), &drafts); err != nil {
+		return nil, fmt.Errorf("the model didn't reply with a JSON array of questions: %w", err)
+	}
+
+	// Models sometimes give numbers as numbers rather than strings
+	text := func(v any) string {
+		if v == nil {
+			return ""
+		}
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+	questions := make([]quiz.Question, 0, len(drafts))
+	for _, d := range drafts {
+		q := quiz.Question{QText: strings.TrimSpace(d.Question), Answer: text(d.Answer), Hint: d.Hint, Difficulty: d.Difficulty}
+		for _, c := range d.Choices {
+			if c := text(c); c != "" && c != q.Answer {
+				q.Choices = append(q.Choices, c)
+			}
+		}
+		questions = append(questions, q)
+	}
+	return questions, nil
+}
@@ -0,0 +1,120 @@
+// Package llm talks to a large language model through an OpenAI
+// compatible chat completions API, which OpenAI, Ollama, LM Studio, vLLM
+// and many other servers provide.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultURL is the base URL of OpenAI's API.
+const DefaultURL = "https://api.openai.com/v1"
+
+// Client sends prompts to a model.
+type Client struct {
+	URL    string //Base URL of the API, e.g. "http://localhost:11434/v1" for Ollama
+	Key    string //API key, empty for servers that don't need one
+	Model  string //Name of the model, e.g. "gpt-4o-mini"
+	client *http.Client
+}
+
+// NewClient creates a client for model at the API at baseURL.
+func NewClient(baseURL, key, model string) *Client {
+	return &Client{
+		URL:    strings.TrimSuffix(baseURL, "/"),
+		Key:    key,
+		Model:  model,
+		client: &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// message is a message in a chat.
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Complete sends the system instructions and the prompt to the model and
+// returns its reply.
+func (c *Client) Complete(ctx context.Context, system, prompt string) (string, error) {
+	if c.Model == "" {
+		return "", errors.New("no model is set")
+	}
+	body, err := json.Marshal(struct {
+		Model       string    `json:"model"`
+		Messages    []message `json:"messages"`
+		Temperature float64   `json:"temperature"`
+	}{
+		Model:       c.Model,
+		Messages:    []message{{"system", system}, {"user", prompt}},
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Key != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Key)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var reply struct {
+		Choices []struct {
+			Message message `json:"message"`
+		} `json:"choices"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return "", err
+	}
+	if err = json.Unmarshal(data, &reply); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("the model's API returned %s: %s", resp.Status, bytes.TrimSpace(data))
+		}
+		return "", fmt.Errorf("unable to read the model's reply: %w", err)
+	}
+	switch {
+	case reply.Error != nil:
+		return "", fmt.Errorf("the model's API returned %s: %s", resp.Status, reply.Error.Message)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("the model's API returned %s", resp.Status)
+	case len(reply.Choices) == 0:
+		return "", errors.New("the model didn't reply")
+	}
+	return reply.Choices[0].Message.Content, nil
+}
+
+// JSON returns the JSON in a reply, without the Markdown code fence
+// models often put around it.
+func JSON(reply string) string {
+	reply = strings.TrimSpace(reply)
+	if start := strings.Index(reply, "```"); start >= 0 {
+		reply = reply[start+3:]
+		if nl := strings.IndexByte(reply, '\n'); nl >= 0 {
+			reply = reply[nl+1:] // skip the language, e.g. ```json
+		}
+		if end := strings.Index(reply, "```"); end >= 0 {
+			reply = reply[:end]
+		}
+	}
+	return strings.TrimSpace(reply)
+}
@@ -0,0 +1,135 @@
+package loader
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+func init() {
+	RegisterExtension(".aiken", quiz.LoaderFunc(Aiken))
+	RegisterExporter(".aiken", quiz.ExporterFunc(ExportAiken))
+}
+
+// aikenOption matches a lettered option of an Aiken question, e.g. "B. Paris" or "B) Paris".
+var aikenOption = regexp.MustCompile(`^([A-Z])[.)]\s+(.*)$`)
+
+// Aiken loads a file in Moodle's Aiken format, which only holds multiple
+// choice questions.  Each question is followed by its lettered options
+// and then the letter of the answer, e.g.
+//
+//	What is the capital of France?
+//	A. London
+//	B. Paris
+//	C. Berlin
+//	ANSWER: B
+func Aiken(path string) (questions []quiz.Question, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		problems []error
+		q        quiz.Question
+		letters  = map[string]string{}
+		order    []string
+	)
+	reset := func() {
+		q, letters, order = quiz.Question{}, map[string]string{}, nil
+	}
+
+	scanner := bufio.NewScanner(skipBOM(file))
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if letter, ok := strings.CutPrefix(text, "ANSWER:"); ok {
+			letter = strings.TrimSpace(letter)
+			answer, found := letters[letter]
+			switch {
+			case q.QText == "":
+				problems = append(problems, &RowError{Path: path, Line: n, Err: errors.New("an answer without a question")})
+			case !found:
+				problems = append(problems, &RowError{Path: path, Line: q.Line, Err: fmt.Errorf("the answer %q isn't one of the options", letter)})
+			default:
+				q.Answer = answer
+				for _, l := range order {
+					if l != letter {
+						q.Choices = append(q.Choices, letters[l])
+					}
+				}
+				questions = append(questions, q)
+			}
+			reset()
+			continue
+		}
+
+		if m := aikenOption.FindStringSubmatch(text); m != nil && q.QText != "" {
+			letters[m[1]] = m[2]
+			order = append(order, m[1])
+			continue
+		}
+		if len(order) > 0 {
+			// Options have been read, so this is the start of a new question
+			problems = append(problems, &RowError{Path: path, Line: q.Line, Err: errors.New("the question has no ANSWER: line")})
+			reset()
+		}
+		if q.QText == "" {
+			q.QText, q.Line = text, n
+		} else {
+			q.QText += " " + text
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return questions, err
+	}
+	if q.QText != "" {
+		problems = append(problems, &RowError{Path: path, Line: q.Line, Err: errors.New("the question has no ANSWER: line")})
+	}
+	return questions, errors.Join(problems...)
+}
+
+// ExportAiken writes questions to an Aiken file in the format Aiken reads.
+// Aiken only holds multiple choice questions, so it is an error for any of
+// the questions to have no choices.  Hints and categories aren't kept.
+func ExportAiken(path string, questions []quiz.Question) (err error) {
+	for i, q := range questions {
+		if opts := q.Options(); len(opts) == 0 || len(opts) > 26 {
+			return fmt.Errorf("question %v %q can't be written to an Aiken file, which needs 1 to 26 choices", i+1, q.QText)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	w := bufio.NewWriter(file)
+	for _, q := range questions {
+		fmt.Fprintln(w, strings.ReplaceAll(q.QText, "\n", " "))
+		answer := ""
+		for i, c := range q.Options() {
+			letter := string(rune('A' + i))
+			if c == q.Answer {
+				answer = letter
+			}
+			fmt.Fprintf(w, "%s. %s\n", letter, strings.ReplaceAll(c, "\n", " "))
+		}
+		fmt.Fprintf(w, "ANSWER: %s\n\n", answer)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,229 @@
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+func init() {
+	RegisterExtension(".txt", quiz.LoaderFunc(Anki))
+	RegisterExporter(".txt", quiz.ExporterFunc(ExportAnki))
+	RegisterExtension(".tsv", quiz.LoaderFunc(Anki))
+	RegisterExporter(".tsv", quiz.ExporterFunc(ExportAnkiCards))
+}
+
+// ankiSeparators are the names Anki uses for separators in a #separator header.
+var ankiSeparators = map[string]rune{
+	"tab": '\t', "comma": ',', "semicolon": ';', "space": ' ', "pipe": '|', "colon": ':',
+}
+
+// ankiTag matches an HTML tag in a field of an Anki export with HTML.
+var ankiTag = regexp.MustCompile(`<[^>]*>`)
+
+// ankiChoices separates the choices in the Choices column.
+const ankiChoices = " | "
+
+// Anki loads the notes of an Anki "Notes in Plain Text" export.  Each
+// note is a line of fields separated by tabs, or the separator in a
+// #separator header.  The first field is the question and the second the
+// answer, unless a #columns header names the Front, Back, Hint, Choices
+// and ID columns.  The first tag in the column given by a #tags column header is
+// the question's category.
+// A .txt file without a #separator header or tabs is read as CSV, as it
+// always has been.
+func Anki(path string) (questions []quiz.Question, err error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// The headers are lines like #separator:tab at the top of the file
+	headers := map[string]string{}
+	body, skipped := data, 0
+	for bytes.HasPrefix(body, []byte("#")) {
+		line, rest, _ := bytes.Cut(body, []byte("\n"))
+		name, value, _ := strings.Cut(strings.TrimSpace(string(line[1:])), ":")
+		headers[strings.ToLower(name)] = value
+		body, skipped = rest, skipped+1
+	}
+
+	sep := '\t'
+	if name, ok := headers["separator"]; ok {
+		if r, ok := ankiSeparators[strings.ToLower(name)]; ok {
+			sep = r
+		} else {
+			sep, _ = utf8.DecodeRuneInString(name)
+		}
+	} else if first, _, _ := bytes.Cut(body, []byte("\n")); !bytes.ContainsRune(first, '\t') {
+		return CSV(path)
+	}
+
+	// Columns are numbered from 1 in the headers, and 0 is no column
+	front, back, hint, choices, id := 1, 2, 0, 0, 0
+	if names, ok := headers["columns"]; ok {
+		front, back = 0, 0
+		for i, name := range strings.Split(names, string(sep)) {
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "front", "question":
+				front = i + 1
+			case "back", "answer":
+				back = i + 1
+			case "hint":
+				hint = i + 1
+			case "choices":
+				choices = i + 1
+			case "id", "guid":
+				id = i + 1
+			}
+		}
+		if front == 0 || back == 0 {
+			return nil, errors.New(path + ": the #columns header needs Front and Back columns")
+		}
+	}
+	tags, _ := strconv.Atoi(headers["tags column"])
+	isHTML := strings.EqualFold(headers["html"], "true")
+
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.Comma = sep
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	var problems []error
+	for {
+		v, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			problems = append(problems, &RowError{Path: path, Line: perr.StartLine + skipped, Err: perr.Err})
+			continue
+		}
+		if err != nil {
+			return questions, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(column int) string {
+			if column == 0 || column > len(v) {
+				return ""
+			}
+			f := v[column-1]
+			if isHTML {
+				f = strings.NewReplacer("<br>", " ", "<br/>", " ", "<br />", " ").Replace(f)
+				f = html.UnescapeString(ankiTag.ReplaceAllString(f, ""))
+			}
+			return strings.TrimSpace(f)
+		}
+		q := quiz.Question{ID: field(id), QText: field(front), Answer: field(back), Hint: field(hint), Line: line + skipped}
+		if q.QText == "" || q.Answer == "" {
+			problems = append(problems, &RowError{Path: path, Line: q.Line, Err: errors.New("a note needs a front and a back")})
+			continue
+		}
+		if c := field(choices); c != "" {
+			q.Choices = strings.Split(c, ankiChoices)
+		}
+		if t := strings.Fields(field(tags)); len(t) > 0 {
+			// Tags can't have spaces, so they are written with underscores
+			q.Category = strings.ReplaceAll(t[0], "_", " ")
+		}
+		questions = append(questions, q)
+	}
+	return questions, errors.Join(problems...)
+}
+
+// ExportAnki writes questions as an Anki plain text export, which Anki can
+// import as notes with Front, Back, Hint, Choices and ID fields, tagged
+// with the question's category.
+func ExportAnki(path string, questions []quiz.Question) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	w := bufio.NewWriter(file)
+	w.WriteString("#separator:tab\n#html:false\n#columns:Front\tBack\tHint\tChoices\tID\tTags\n#tags column:6\n")
+	writer := csv.NewWriter(w)
+	writer.Comma = '\t'
+	for _, q := range questions {
+		tag := strings.ReplaceAll(q.Category, " ", "_")
+		if err = writer.Write([]string{q.QText, q.Answer, q.Hint, strings.Join(q.Choices, ankiChoices), q.ID, tag}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// ExportAnkiCards writes questions as Anki cards to study from, which Anki
+// imports straight into a deck named after the file with its Basic note
+// type.  The front of each card is the question, with the choices of a
+// multiple choice question listed in alphabetical order, and the back is
+// the answer and the hint.  Each note's GUID is the question's StableID,
+// so importing the file again updates the cards rather than adding more.
+// Unlike ExportAnki the cards don't keep the questions apart from their
+// choices, so they are for studying rather than converting.
+func ExportAnkiCards(path string, questions []quiz.Question) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	deck := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	w := bufio.NewWriter(file)
+	w.WriteString("#separator:tab\n#html:true\n#notetype:Basic\n#deck:" + deck + "\n")
+	w.WriteString("#columns:Front\tBack\tTags\tGUID\n#tags column:3\n#guid column:4\n")
+	writer := csv.NewWriter(w)
+	writer.Comma = '\t'
+	for _, q := range questions {
+		front := html.EscapeString(q.QText)
+		if options := q.Options(); options != nil {
+			// The answer is always first in the options, which would give it away
+			sorted := append([]string(nil), options...)
+			sort.Strings(sorted)
+			front += "<ol type=A>"
+			for _, o := range sorted {
+				front += "<li>" + html.EscapeString(o) + "</li>"
+			}
+			front += "</ol>"
+		}
+		back := html.EscapeString(q.Answer)
+		if q.Hint != "" {
+			back += "<br><br>" + html.EscapeString(q.Hint)
+		}
+		tag := strings.ReplaceAll(q.Category, " ", "_")
+		if err = writer.Write([]string{front, back, tag, q.StableID()}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
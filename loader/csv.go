@@ -0,0 +1,376 @@
+package loader
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+func init() {
+	RegisterExtension(".csv", quiz.ScannerFunc(ScanCSV))
+	RegisterExporter(".csv", quiz.ExporterFunc(ExportCSV))
+}
+
+// CSV loads a csv file containing questions and answers.
+// Each row is a question followed by its answer.  Any columns after the
+// answer are the choices for a multiple choice question.
+// A file can start with a header row naming its columns instead, from
+// CSVColumns, to give the other fields of the questions, e.g.
+//
+//	question,answer,category,difficulty,hint,explanation,points,choices
+//	Capital of France?,Paris,Capitals,easy,It's on the Seine,,2,London,Berlin
+//
+// Only the question and answer columns are needed and they can be in any
+// order.  Any columns after the last named one are choices too.
+// Lines starting with # are comments and blank lines are ignored, so a
+// question starting with # has to be quoted.
+// Rows that can't be read are skipped and reported together as RowErrors
+// in the returned error, alongside the questions from the rows that could.
+func CSV(path string) ([]quiz.Question, error) {
+	return quiz.ScannerFunc(ScanCSV).Load(path)
+}
+
+// CSVColumns are the columns a csv file with a header row can have.
+// timelimit is in seconds and there can be several choices columns.
+var CSVColumns = []string{"question", "answer", "category", "difficulty", "hint", "explanation", "points", "timelimit", "id", "choices"}
+
+// TranslatedColumns are the columns that can be given in other languages,
+// named with the language code after a colon, e.g. "question:fr".  There
+// can be several choices columns for each language, one for each choice.
+var TranslatedColumns = []string{"question", "answer", "hint", "explanation", "choices"}
+
+// translatedColumn splits the name of a column in another language into
+// the column it translates and the language code.
+func translatedColumn(name string) (column, lang string, ok bool) {
+	column, lang, ok = strings.Cut(name, ":")
+	return column, lang, ok && lang != "" && slices.Contains(TranslatedColumns, column)
+}
+
+// ScanCSV reads the questions in a csv file like CSV, one row at a time,
+// calling yield with each until it returns false, so a large file doesn't
+// have to be read all at once.
+func ScanCSV(path string, yield func(q quiz.Question) bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(skipBOM(file))
+	reader.FieldsPerRecord = -1 // rows with choices have more columns than rows without
+	reader.ReuseRecord = true
+	reader.Comment = '#'
+	var (
+		problems []error
+		columns  []string //Names of the columns from the header row, nil without one
+		first    = true
+	)
+	for {
+		v, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			problems = append(problems, &RowError{Path: path, Line: perr.StartLine, Err: perr.Err})
+			continue
+		}
+		if err != nil {
+			return errors.Join(append(problems, err)...)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if blank(v) {
+			continue // a line of spaces or empty columns separates the questions like an empty one
+		}
+		if first {
+			first = false
+			if isHeader(v) {
+				for _, name := range v {
+					columns = append(columns, strings.ToLower(strings.TrimSpace(name)))
+				}
+				continue
+			}
+		}
+
+		question, err := csvQuestion(v, columns)
+		if err != nil {
+			problems = append(problems, &RowError{Path: path, Line: line, Err: err})
+			continue
+		}
+		question.Line = line
+		if !yield(question) {
+			break
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// isHeader reports whether row is a header row naming the question and
+// answer columns and any others from CSVColumns.
+func isHeader(row []string) bool {
+	named := map[string]bool{}
+	for _, name := range row {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, _, translated := translatedColumn(name); name != "" && !translated && !slices.Contains(CSVColumns, name) {
+			return false
+		}
+		named[name] = true
+	}
+	return named["question"] && named["answer"]
+}
+
+// csvQuestion makes a question from a row with the columns named in the
+// header row, or else the question, answer and choices.
+func csvQuestion(row, columns []string) (quiz.Question, error) {
+	if columns == nil {
+		if len(row) < 2 {
+			return quiz.Question{}, errors.New("a question needs an answer")
+		}
+		columns = []string{"question", "answer"}
+	}
+	if len(row) <= slices.Index(columns, "answer") {
+		return quiz.Question{}, errors.New("a question needs an answer")
+	}
+
+	var q quiz.Question
+	for i, f := range row {
+		name := "choices"
+		if i < len(columns) {
+			name = columns[i]
+		}
+		if column, lang, ok := translatedColumn(name); ok {
+			translate(&q, column, lang, f)
+			continue
+		}
+		switch name {
+		case "question":
+			q.QText = f
+		case "answer":
+			q.Answer = f
+		case "category":
+			q.Category = strings.TrimSpace(f)
+		case "difficulty":
+			q.Difficulty = strings.TrimSpace(f)
+		case "hint":
+			q.Hint = strings.TrimSpace(f)
+		case "explanation":
+			q.Explanation = strings.TrimSpace(f)
+		case "id":
+			q.ID = strings.TrimSpace(f)
+		case "points":
+			if f = strings.TrimSpace(f); f != "" {
+				points, err := strconv.ParseFloat(f, 64)
+				if err != nil || points < 0 {
+					return q, fmt.Errorf("the points should be a number, not %q", f)
+				}
+				q.Points = points
+			}
+		case "timelimit":
+			if f = strings.TrimSpace(f); f != "" {
+				seconds, err := strconv.ParseFloat(f, 64)
+				if err != nil || seconds < 0 {
+					return q, fmt.Errorf("the time limit should be a number of seconds, not %q", f)
+				}
+				q.TimeLimit = time.Duration(seconds * float64(time.Second))
+			}
+		case "choices":
+			if c := strings.TrimSpace(f); c != "" {
+				q.Choices = append(q.Choices, c)
+			}
+		}
+	}
+	return q, nil
+}
+
+// translate sets the column of q's translation into lang to f.
+func translate(q *quiz.Question, column, lang, f string) {
+	if f = strings.TrimSpace(f); f == "" {
+		return
+	}
+	if q.Translations == nil {
+		q.Translations = make(map[string]quiz.Translation)
+	}
+	t := q.Translations[lang]
+	switch column {
+	case "question":
+		t.QText = f
+	case "answer":
+		t.Answer = f
+	case "hint":
+		t.Hint = f
+	case "explanation":
+		t.Explanation = f
+	case "choices":
+		t.Choices = append(t.Choices, f)
+	}
+	q.Translations[lang] = t
+}
+
+// blank reports whether every field of a row is empty or spaces.
+func blank(row []string) bool {
+	for _, f := range row {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ExportCSV writes questions to a csv file in the format CSV reads.
+// When any of the questions have fields other than the question, answer
+// and choices the file starts with a header row naming the columns, so
+// they are kept.  Questions starting with # are quoted so they aren't read
+// as comments.
+func ExportCSV(path string, questions []quiz.Question) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	w := bufio.NewWriter(file)
+	writer := csv.NewWriter(w)
+	columns := exportColumns(questions)
+	if columns != nil {
+		if err = writer.Write(columns); err != nil {
+			return err
+		}
+	}
+	for _, q := range questions {
+		row := []string{q.QText, q.Answer}
+		choices := map[string]int{} //Translated choices written so far, by language
+		for _, name := range columns[min(2, len(columns)):] {
+			switch name {
+			case "category":
+				row = append(row, q.Category)
+			case "difficulty":
+				row = append(row, q.Difficulty)
+			case "hint":
+				row = append(row, q.Hint)
+			case "explanation":
+				row = append(row, q.Explanation)
+			case "points":
+				row = append(row, formatNumber(q.Points))
+			case "timelimit":
+				row = append(row, formatNumber(q.TimeLimit.Seconds()))
+			case "id":
+				row = append(row, q.ID)
+			}
+			if column, lang, ok := translatedColumn(name); ok {
+				t := q.Translations[lang]
+				switch column {
+				case "question":
+					row = append(row, t.QText)
+				case "answer":
+					row = append(row, t.Answer)
+				case "hint":
+					row = append(row, t.Hint)
+				case "explanation":
+					row = append(row, t.Explanation)
+				case "choices":
+					// Each choice has its own column
+					if n := choices[lang]; n < len(t.Choices) {
+						row = append(row, t.Choices[n])
+					} else {
+						row = append(row, "")
+					}
+					choices[lang]++
+				}
+			}
+		}
+		row = append(row, q.Choices...)
+		if strings.HasPrefix(q.QText, "#") {
+			// The csv writer only quotes fields that need it to be read back
+			writer.Flush()
+			fmt.Fprintf(w, "\"%s\",", strings.ReplaceAll(q.QText, `"`, `""`))
+			row = row[1:]
+		}
+		if err = writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// exportColumns returns the columns of the header row for questions, or
+// nil if they only have questions, answers and choices and don't need one.
+func exportColumns(questions []quiz.Question) []string {
+	used := map[string]bool{}
+	for _, q := range questions {
+		used["category"] = used["category"] || q.Category != ""
+		used["difficulty"] = used["difficulty"] || q.Difficulty != ""
+		used["hint"] = used["hint"] || q.Hint != ""
+		used["explanation"] = used["explanation"] || q.Explanation != ""
+		used["points"] = used["points"] || q.Points != 0
+		used["timelimit"] = used["timelimit"] || q.TimeLimit != 0
+		used["id"] = used["id"] || q.ID != ""
+		used["choices"] = used["choices"] || len(q.Choices) > 0
+	}
+	columns := []string{"question", "answer"}
+	for _, name := range CSVColumns[2 : len(CSVColumns)-1] {
+		if used[name] {
+			columns = append(columns, name)
+		}
+	}
+	for _, lang := range quiz.Languages(questions) {
+		columns = append(columns, translatedColumns(questions, lang)...)
+	}
+	if len(columns) == 2 {
+		return nil
+	}
+	if used["choices"] {
+		columns = append(columns, "choices")
+	}
+	return columns
+}
+
+// translatedColumns returns the columns for the translations of questions
+// into lang: the question and answer, the hint and explanation if any of
+// them have one, and a choices column for each choice.
+func translatedColumns(questions []quiz.Question, lang string) []string {
+	hint, explanation, choices := false, false, 0
+	for _, q := range questions {
+		t := q.Translations[lang]
+		hint = hint || t.Hint != ""
+		explanation = explanation || t.Explanation != ""
+		choices = max(choices, len(t.Choices))
+	}
+	columns := []string{"question:" + lang, "answer:" + lang}
+	if hint {
+		columns = append(columns, "hint:"+lang)
+	}
+	if explanation {
+		columns = append(columns, "explanation:"+lang)
+	}
+	for range choices {
+		columns = append(columns, "choices:"+lang)
+	}
+	return columns
+}
+
+// formatNumber writes n for a csv file, empty for 0.
+func formatNumber(n float64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
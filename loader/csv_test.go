@@ -0,0 +1,203 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// writeCSV writes data to a csv file and returns its path.
+func writeCSV(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "questions.csv")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		want     []quiz.Question
+		badLines []int //Lines of the rows that can't be read
+	}{
+		{
+			name: "questions and answers",
+			data: "5+5,10\n1+1,2\n",
+			want: []quiz.Question{{QText: "5+5", Answer: "10", Line: 1}, {QText: "1+1", Answer: "2", Line: 2}},
+		},
+		{
+			name: "choices",
+			data: "Capital of France?,Paris,London, Berlin ,\n",
+			want: []quiz.Question{{QText: "Capital of France?", Answer: "Paris", Choices: []string{"London", "Berlin"}, Line: 1}},
+		},
+		{
+			name: "comments and blank lines",
+			data: "# sums\n5+5,10\n\n , \n\"#1+1\",2\n",
+			want: []quiz.Question{{QText: "5+5", Answer: "10", Line: 2}, {QText: "#1+1", Answer: "2", Line: 5}},
+		},
+		{
+			name: "byte order mark",
+			data: "\ufeffquestion,answer\n5+5,10\n",
+			want: []quiz.Question{{QText: "5+5", Answer: "10", Line: 2}},
+		},
+		{
+			name: "header in any order",
+			data: "Answer,Category,Points,TimeLimit,Question,Choices\nParis,Capitals,2,7.5,Capital of France?,London,Berlin\n",
+			want: []quiz.Question{{QText: "Capital of France?", Answer: "Paris", Category: "Capitals", Points: 2, TimeLimit: 7500 * time.Millisecond, Choices: []string{"London", "Berlin"}, Line: 2}},
+		},
+		{
+			name: "translations",
+			data: "question,answer,question:fr,choices:fr,choices\nCapital of Germany?,Berlin,Capitale de l'Allemagne ?,Londres,London\n",
+			want: []quiz.Question{{
+				QText: "Capital of Germany?", Answer: "Berlin", Choices: []string{"London"}, Line: 2,
+				Translations: map[string]quiz.Translation{"fr": {QText: "Capitale de l'Allemagne ?", Choices: []string{"Londres"}}},
+			}},
+		},
+		{
+			name:     "bad rows",
+			data:     "5+5,10\nno answer\nquestion \"quoted,1\n",
+			want:     []quiz.Question{{QText: "5+5", Answer: "10", Line: 1}},
+			badLines: []int{2, 3},
+		},
+		{
+			name:     "bad points",
+			data:     "question,answer,points\n5+5,10,lots\n1+1,2,\n",
+			want:     []quiz.Question{{QText: "1+1", Answer: "2", Line: 3}},
+			badLines: []int{2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CSV(writeCSV(t, tt.data))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CSV() = %+v, want %+v", got, tt.want)
+			}
+			var lines []int
+			for _, row := range RowErrors(err) {
+				lines = append(lines, row.Line)
+			}
+			if !slices.Equal(lines, tt.badLines) {
+				t.Errorf("CSV() reported bad rows on lines %v, want %v: %v", lines, tt.badLines, err)
+			}
+		})
+	}
+}
+
+func TestCSVMissing(t *testing.T) {
+	if _, err := CSV(filepath.Join(t.TempDir(), "missing.csv")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("CSV() of a missing file = %v, want a not exist error", err)
+	}
+}
+
+// TestExportCSV checks questions written with ExportCSV are read back the same.
+func TestExportCSV(t *testing.T) {
+	tests := []struct {
+		name      string
+		questions []quiz.Question
+	}{
+		{"plain", []quiz.Question{{QText: "5+5", Answer: "10"}, {QText: "Capital of France?", Answer: "Paris", Choices: []string{"London", "Berlin"}}}},
+		{"comment like question", []quiz.Question{{QText: "#hashtag?", Answer: "yes"}}},
+		{"other fields", []quiz.Question{
+			{ID: "q1", QText: "Capital of France?", Answer: "Paris", Hint: "Seine", Explanation: "It has been since 987", Category: "Capitals", Difficulty: "easy", Points: 2, TimeLimit: 10 * time.Second, Choices: []string{"London"}},
+			{QText: "5+5", Answer: "10"},
+		}},
+		{"translations", []quiz.Question{{
+			QText: "Capital of Germany?", Answer: "Berlin", Choices: []string{"London", "Paris"},
+			Translations: map[string]quiz.Translation{"fr": {QText: "Capitale de l'Allemagne ?", Choices: []string{"Londres", "Paris"}}},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "export.csv")
+			if err := ExportCSV(path, tt.questions); err != nil {
+				t.Fatal(err)
+			}
+			got, err := CSV(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range got {
+				got[i].Line = 0
+			}
+			if !reflect.DeepEqual(got, tt.questions) {
+				t.Errorf("read back %+v, want %+v", got, tt.questions)
+			}
+		})
+	}
+}
+
+// benchCSV writes a csv file of n questions, every other one multiple
+// choice, and returns its path.
+func benchCSV(b *testing.B, n int) string {
+	var data strings.Builder
+	for i := range n {
+		if i%2 == 0 {
+			fmt.Fprintf(&data, "%d+%d,%d\n", i, i+1, 2*i+1)
+		} else {
+			fmt.Fprintf(&data, "\"What is %d, doubled and one more?\",%d,%d,%d,%d\n", i, 2*i+1, 2*i, 2*i+2, 2*i-1)
+		}
+	}
+	path := filepath.Join(b.TempDir(), "bench.csv")
+	if err := os.WriteFile(path, []byte(data.String()), 0644); err != nil {
+		b.Fatal(err)
+	}
+	return path
+}
+
+func BenchmarkCSV(b *testing.B) {
+	path := benchCSV(b, 10000)
+	for b.Loop() {
+		if _, err := CSV(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkExportCSV(b *testing.B) {
+	questions, err := CSV(benchCSV(b, 10000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	path := filepath.Join(b.TempDir(), "export.csv")
+	for b.Loop() {
+		if err = ExportCSV(path, questions); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkLoadQuestions loads the first 10 questions of a large file,
+// which should cost the same however large the file is.
+func BenchmarkLoadQuestions(b *testing.B) {
+	path := benchCSV(b, 100000)
+	for b.Loop() {
+		test := &quiz.Assessment{FilePath: path, TotalQuestions: 10}
+		if err := test.LoadQuestions(context.Background(), Default); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkLoadQuestionsShuffled loads and shuffles every question in a
+// file, as a test without a question limit does.
+func BenchmarkLoadQuestionsShuffled(b *testing.B) {
+	path := benchCSV(b, 10000)
+	for b.Loop() {
+		test := &quiz.Assessment{FilePath: path, Shuffle: true, Seed: 1, OnDuplicate: quiz.DuplicatesWarn}
+		if err := test.LoadQuestions(context.Background(), Default); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package loader
+
+import (
+	_ "embed"
+	"os"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// DemoSource is the source of the demo quiz built into the program, so
+// the game can be tried without a question file.
+const DemoSource = "demo:"
+
+//go:embed demo.json
+var demo []byte
+
+func init() {
+	RegisterScheme("demo", quiz.LoaderFunc(Demo))
+}
+
+// Demo loads the demo quiz, a few questions on maths, geography, science
+// and history with examples of choices, hints, categories and
+// explanations.
+func Demo(source string) ([]quiz.Question, error) {
+	// The loaders read files, so the demo is saved to a temporary file
+	// first, like a download.
+	file, err := os.CreateTemp("", "quiz-demo-*.json")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(demo)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return JSON(file.Name())
+}
@@ -0,0 +1,284 @@
+package loader
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+func init() {
+	RegisterExtension(".gift", quiz.LoaderFunc(GIFT))
+	RegisterExporter(".gift", quiz.ExporterFunc(ExportGIFT))
+}
+
+// giftSpecial are the characters that must be escaped with a backslash in GIFT text.
+const giftSpecial = `~=#{}:\`
+
+// giftWeight matches the percentage weight before a GIFT answer, e.g. %50%.
+var giftWeight = regexp.MustCompile(`^%-?[0-9.]+%`)
+
+// GIFT loads a file in Moodle's GIFT format.  Questions are separated by
+// blank lines and their answers are between braces, e.g.
+//
+//	// comments start with two slashes
+//	$CATEGORY: Capitals
+//
+//	::France:: What is the capital of France? {=Paris ~London ~Berlin ####It's on the Seine}
+//
+//	What is 5+5? {=10}
+//
+//	The sun is a star. {T}
+//
+// Answers marked = are correct and those marked ~ are wrong choices.  A
+// question with only correct answers is a free text question, and the
+// first correct answer is used.  The title, between ::, is the question's
+// ID and general feedback, after ####, is its hint.  Matching questions and essays aren't supported and
+// are reported as RowErrors.
+func GIFT(path string) (questions []quiz.Question, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		problems []error
+		category string
+		block    []string
+		start    int
+	)
+	// flush parses the lines of the question read so far
+	flush := func() {
+		if len(block) == 0 {
+			return
+		}
+		q, err := parseGIFT(strings.Join(block, "\n"))
+		if err != nil {
+			problems = append(problems, &RowError{Path: path, Line: start, Err: err})
+		} else {
+			q.Category, q.Line = category, start
+			questions = append(questions, q)
+		}
+		block = nil
+	}
+
+	scanner := bufio.NewScanner(skipBOM(file))
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "":
+			flush()
+		case strings.HasPrefix(text, "//"):
+		case strings.HasPrefix(text, "$CATEGORY:"):
+			flush()
+			// Moodle categories are paths like $course$/top/Capitals
+			category = strings.TrimSpace(strings.TrimPrefix(text, "$CATEGORY:"))
+			category = category[strings.LastIndex(category, "/")+1:]
+		default:
+			if len(block) == 0 {
+				start = n
+			}
+			block = append(block, text)
+		}
+	}
+	flush()
+	if err = scanner.Err(); err != nil {
+		return questions, err
+	}
+	return questions, errors.Join(problems...)
+}
+
+// parseGIFT parses the text of one GIFT question.
+func parseGIFT(text string) (q quiz.Question, err error) {
+	if strings.HasPrefix(text, "::") {
+		end := giftIndex(text[2:], "::")
+		if end < 0 {
+			return q, errors.New("the question's title isn't closed with ::")
+		}
+		q.ID = giftUnescape(strings.TrimSpace(text[2 : end+2]))
+		text = text[end+4:]
+	}
+
+	open := giftIndex(text, "{")
+	if open < 0 {
+		return q, errors.New("the question has no answers between { and }")
+	}
+	close := giftIndex(text[open:], "}")
+	if close < 0 {
+		return q, errors.New("the question's answers aren't closed with }")
+	}
+	before, answers, after := text[:open], text[open+1:open+close], text[open+close+1:]
+
+	// A question with text after the answers is a missing word question
+	before = strings.TrimSpace(strings.TrimPrefix(before, "[plain]"))
+	q.QText = giftUnescape(before)
+	if after = strings.TrimSpace(after); after != "" {
+		q.QText += " _____ " + giftUnescape(after)
+	}
+	if q.QText == "" {
+		return q, errors.New("the question has no text")
+	}
+
+	if i := giftIndex(answers, "####"); i >= 0 {
+		q.Hint = giftUnescape(strings.TrimSpace(answers[i+4:]))
+		answers = answers[:i]
+	}
+	answers = strings.TrimSpace(answers)
+
+	switch strings.ToUpper(answers) {
+	case "":
+		return q, errors.New("essay questions aren't supported")
+	case "T", "TRUE":
+		q.Answer, q.Choices = "True", []string{"False"}
+		return q, nil
+	case "F", "FALSE":
+		q.Answer, q.Choices = "False", []string{"True"}
+		return q, nil
+	}
+	if strings.HasPrefix(answers, "#") {
+		// Numeric answers may have a tolerance, e.g. {#3.14:0.01}, which isn't kept
+		answer, _, _ := strings.Cut(strings.TrimPrefix(answers, "#"), ":")
+		q.Answer = giftUnescape(strings.TrimSpace(strings.TrimPrefix(answer, "=")))
+		return q, nil
+	}
+
+	var wrong []string
+	for _, a := range giftSplit(answers) {
+		correct := a[0] == '='
+		a = strings.TrimSpace(a[1:])
+		if w := giftWeight.FindString(a); w != "" {
+			correct = w == "%100%"
+			a = strings.TrimSpace(a[len(w):])
+		}
+		if i := giftIndex(a, "#"); i >= 0 {
+			a = strings.TrimSpace(a[:i]) // feedback for the answer
+		}
+		if giftIndex(a, "->") >= 0 {
+			return q, errors.New("matching questions aren't supported")
+		}
+		a = giftUnescape(a)
+		switch {
+		case correct && q.Answer == "":
+			q.Answer = a
+		case !correct:
+			wrong = append(wrong, a)
+		}
+	}
+	if q.Answer == "" {
+		return q, errors.New("the question has no correct answer")
+	}
+	q.Choices = wrong
+	return q, nil
+}
+
+// giftIndex returns the index of the first unescaped sep in s, or -1.
+func giftIndex(s, sep string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sep) {
+			return i
+		}
+	}
+	return -1
+}
+
+// giftSplit splits GIFT answers into the answers starting with an
+// unescaped = or ~.
+func giftSplit(s string) []string {
+	var answers []string
+	start := -1
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '=', '~':
+			// = also starts the tolerance of a numeric answer, but those are handled before
+			if start >= 0 {
+				answers = append(answers, s[start:i])
+			}
+			start = i
+		}
+	}
+	if start >= 0 {
+		answers = append(answers, s[start:])
+	}
+	return answers
+}
+
+// giftUnescape removes the backslashes escaping special characters in s.
+func giftUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// giftEscape escapes the special characters in s.
+func giftEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			b.WriteString(`\n`)
+		case strings.ContainsRune(giftSpecial, r):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ExportGIFT writes questions to a GIFT file in the format GIFT reads.
+func ExportGIFT(path string, questions []quiz.Question) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	w := bufio.NewWriter(file)
+	category := ""
+	for _, q := range questions {
+		if q.Category != category {
+			category = q.Category
+			fmt.Fprintf(w, "$CATEGORY: %s\n\n", category)
+		}
+
+		if q.ID != "" {
+			fmt.Fprintf(w, "::%s:: ", giftEscape(q.ID))
+		}
+		fmt.Fprintf(w, "%s {=%s", giftEscape(q.QText), giftEscape(q.Answer))
+		for _, c := range q.Choices {
+			if c != q.Answer {
+				fmt.Fprintf(w, " ~%s", giftEscape(c))
+			}
+		}
+		if q.Hint != "" {
+			fmt.Fprintf(w, " ####%s", giftEscape(q.Hint))
+		}
+		fmt.Fprint(w, "}\n\n")
+	}
+	return w.Flush()
+}
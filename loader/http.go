@@ -0,0 +1,64 @@
+package loader
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+func init() {
+	RegisterScheme("http", quiz.LoaderFunc(HTTP))
+	RegisterScheme("https", quiz.LoaderFunc(HTTP))
+}
+
+// HTTP downloads a question file and loads it with the loader registered
+// for the extension in the URL's path, e.g. https://example.com/quiz.json.
+func HTTP(source string) ([]quiz.Question, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	ext := path.Ext(u.Path)
+	l, err := Lookup("questions" + ext)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", source, resp.Status)
+	}
+
+	// The loaders read files, so the download is saved to a temporary
+	// file with the same extension first.
+	file, err := os.CreateTemp("", "quiz-*"+ext)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, resp.Body)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Problems are reported against the URL rather than the temporary file
+	questions, err := l.Load(file.Name())
+	for _, row := range RowErrors(err) {
+		row.Path = source
+	}
+	return questions, err
+}
@@ -0,0 +1,125 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+func init() {
+	RegisterExtension(".json", quiz.LoaderFunc(JSON))
+	RegisterExporter(".json", quiz.ExporterFunc(ExportJSON))
+}
+
+// jsonQuestion is how a question is written in a JSON question file.
+type jsonQuestion struct {
+	ID           string                 `json:"id,omitempty"`
+	Question     string                 `json:"question"`
+	Answer       string                 `json:"answer"`
+	Choices      []string               `json:"choices,omitempty"`
+	Hint         string                 `json:"hint,omitempty"`
+	Explanation  string                 `json:"explanation,omitempty"`
+	Points       float64                `json:"points,omitempty"`
+	Category     string                 `json:"category,omitempty"`
+	Difficulty   string                 `json:"difficulty,omitempty"`
+	TimeLimit    int                    `json:"timelimit,omitempty"`    //Seconds to answer the question
+	Translations map[string]translation `json:"translations,omitempty"` //The question in other languages, by language code
+}
+
+// translation is how a translation of a question is written in a JSON or
+// YAML question file.
+type translation struct {
+	Question    string   `json:"question,omitempty" yaml:"question,omitempty"`
+	Answer      string   `json:"answer,omitempty" yaml:"answer,omitempty"`
+	Choices     []string `json:"choices,omitempty" yaml:"choices,omitempty"`
+	Hint        string   `json:"hint,omitempty" yaml:"hint,omitempty"`
+	Explanation string   `json:"explanation,omitempty" yaml:"explanation,omitempty"`
+}
+
+// fromTranslations returns the translations read from a file as the
+// question's, nil if there are none.
+func fromTranslations(ts map[string]translation) map[string]quiz.Translation {
+	if len(ts) == 0 {
+		return nil
+	}
+	translations := make(map[string]quiz.Translation, len(ts))
+	for lang, t := range ts {
+		translations[lang] = quiz.Translation{QText: t.Question, Answer: t.Answer, Choices: t.Choices, Hint: t.Hint, Explanation: t.Explanation}
+	}
+	return translations
+}
+
+// toTranslations returns the question's translations as they are written
+// to a file, nil if there are none.
+func toTranslations(translations map[string]quiz.Translation) map[string]translation {
+	if len(translations) == 0 {
+		return nil
+	}
+	ts := make(map[string]translation, len(translations))
+	for lang, t := range translations {
+		ts[lang] = translation{Question: t.QText, Answer: t.Answer, Choices: t.Choices, Hint: t.Hint, Explanation: t.Explanation}
+	}
+	return ts
+}
+
+// JSON loads a JSON file containing an array of questions, e.g.
+//
+//	[{"question": "5+5", "answer": "10"},
+//	 {"question": "Capital of France?", "answer": "Paris", "choices": ["London", "Berlin"],
+//	  "hint": "It's on the Seine", "category": "Capitals"}]
+func JSON(path string) (questions []quiz.Question, err error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// The questions are decoded one at a time to know the line each is on
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, fmt.Errorf("%s: the questions should be a JSON array", path)
+	}
+	var problems []error
+	for dec.More() {
+		line := lineAt(data, dec.InputOffset())
+		var v jsonQuestion
+		if err = dec.Decode(&v); err != nil {
+			var terr *json.UnmarshalTypeError
+			if !errors.As(err, &terr) {
+				return questions, fmt.Errorf("%s:%d: %w", path, line, err)
+			}
+			// The rest of the array can still be read after a value of the wrong type
+			problems = append(problems, &RowError{Path: path, Line: line, Err: err})
+			continue
+		}
+		questions = append(questions, quiz.Question{ID: v.ID, QText: v.Question, Answer: v.Answer, Choices: v.Choices, Hint: v.Hint, Explanation: v.Explanation, Points: v.Points, Category: v.Category, Difficulty: v.Difficulty, TimeLimit: time.Duration(v.TimeLimit) * time.Second, Line: line, Translations: fromTranslations(v.Translations)})
+	}
+	return questions, errors.Join(problems...)
+}
+
+// lineAt returns the line of the first value at or after offset in data.
+func lineAt(data []byte, offset int64) int {
+	for offset < int64(len(data)) && strings.ContainsRune(" \t\r\n,", rune(data[offset])) {
+		offset++
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// ExportJSON writes questions to a JSON file in the format JSON reads.
+func ExportJSON(path string, questions []quiz.Question) error {
+	records := make([]jsonQuestion, 0, len(questions))
+	for _, q := range questions {
+		records = append(records, jsonQuestion{ID: q.ID, Question: q.QText, Answer: q.Answer, Choices: q.Choices, Hint: q.Hint, Explanation: q.Explanation, Points: q.Points, Category: q.Category, Difficulty: q.Difficulty, TimeLimit: int(q.TimeLimit / time.Second), Translations: toTranslations(q.Translations)})
+	}
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
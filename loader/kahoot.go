@@ -0,0 +1,267 @@
+package loader
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+func init() {
+	RegisterExtension(".xlsx", quiz.LoaderFunc(Kahoot))
+}
+
+// Kahoot loads a spreadsheet made from Kahoot's quiz template.  Below the
+// template's instructions is a header row, then a row for each question:
+//
+//	| # | Question | Answer 1 | Answer 2 | Answer 3 | Answer 4 | Time limit (sec) | Correct answer(s) |
+//	| 1 | Capital of France? | London | Paris | Berlin | | 20 | 2 |
+//
+// The correct answer is the number of the answer column, and the wrong
+// answers are the choices.  A question can only have one answer, so when
+// several are correct the first is used and the others are left out.  The
+// time limit is the time to answer the question.
+func Kahoot(path string) (questions []quiz.Question, err error) {
+	rows, err := readSheet(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// The header row is found by its cells, since the instructions above
+	// it have changed between versions of the template
+	header, cols := -1, map[string]int{}
+	for i, row := range rows {
+		for j, cell := range row.cells {
+			name := strings.ToLower(cell)
+			switch {
+			case strings.HasPrefix(name, "question"):
+				cols["question"] = j
+			case strings.HasPrefix(name, "answer ") && len(name) >= len("answer 1"):
+				cols[name[:len("answer 1")]] = j
+			case strings.HasPrefix(name, "time limit"):
+				cols["time"] = j
+			case strings.HasPrefix(name, "correct answer"):
+				cols["correct"] = j
+			}
+		}
+		_, hasQuestion := cols["question"]
+		_, hasCorrect := cols["correct"]
+		if hasQuestion && hasCorrect {
+			header = i
+			break
+		}
+		cols = map[string]int{}
+	}
+	if header < 0 {
+		return nil, fmt.Errorf("%s: there is no header row with Question and Correct answer(s) columns, is it a Kahoot template?", path)
+	}
+
+	var problems []error
+	for _, row := range rows[header+1:] {
+		cell := func(name string) string {
+			if j, ok := cols[name]; ok && j < len(row.cells) {
+				return strings.TrimSpace(row.cells[j])
+			}
+			return ""
+		}
+		q := quiz.Question{QText: cell("question"), Line: row.number}
+		if q.QText == "" {
+			continue
+		}
+		rowErr := func(format string, a ...any) {
+			problems = append(problems, &RowError{Path: path, Line: row.number, Err: fmt.Errorf(format, a...)})
+		}
+
+		// Only one answer can be right, so the other correct answers are
+		// left out rather than offered as wrong choices
+		correct := map[int]bool{}
+		for _, f := range strings.FieldsFunc(cell("correct"), func(r rune) bool { return r == ',' || r == ' ' || r == ';' }) {
+			n, err := strconv.Atoi(f)
+			if err != nil || n < 1 || n > 4 || cell(fmt.Sprintf("answer %v", n)) == "" {
+				rowErr("the correct answer %q isn't the number of an answer", f)
+				continue
+			}
+			correct[n] = true
+		}
+		if len(correct) == 0 {
+			rowErr("there is no correct answer")
+			continue
+		}
+		for i := 1; i <= 4; i++ {
+			answer := cell(fmt.Sprintf("answer %v", i))
+			switch {
+			case correct[i] && q.Answer == "":
+				q.Answer = answer
+			case !correct[i] && answer != "":
+				q.Choices = append(q.Choices, answer)
+			}
+		}
+
+		if limit := cell("time"); limit != "" {
+			seconds, err := strconv.Atoi(limit)
+			if err != nil || seconds < 0 {
+				rowErr("the time limit %q isn't a number of seconds", limit)
+				continue
+			}
+			q.TimeLimit = time.Duration(seconds) * time.Second
+		}
+		questions = append(questions, q)
+	}
+	return questions, errors.Join(problems...)
+}
+
+// sheetRow is a row of a spreadsheet, with its number counting from 1.
+type sheetRow struct {
+	number int
+	cells  []string
+}
+
+// readSheet reads the rows of the first sheet of an .xlsx workbook, which
+// is a zip of XML files.  Only the text and numbers in the cells are read.
+func readSheet(name string) ([]sheetRow, error) {
+	z, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	defer z.Close()
+
+	sheet, err := firstSheet(&z.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	var shared struct {
+		Items []sheetText `xml:"si"`
+	}
+	if err = readZipXML(&z.Reader, "xl/sharedStrings.xml", &shared); err != nil && !errors.Is(err, errNoZipFile) {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	var data struct {
+		Rows []struct {
+			Number int `xml:"r,attr"`
+			Cells  []struct {
+				Ref    string    `xml:"r,attr"`
+				Type   string    `xml:"t,attr"`
+				Value  string    `xml:"v"`
+				Inline sheetText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err = readZipXML(&z.Reader, sheet, &data); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	rows := make([]sheetRow, 0, len(data.Rows))
+	for _, r := range data.Rows {
+		row := sheetRow{number: r.Number}
+		for i, c := range r.Cells {
+			col := columnIndex(c.Ref)
+			if col < 0 {
+				col = i
+			}
+			text := c.Value
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, fmt.Errorf("%s: cell %s has an unknown shared string %q", name, c.Ref, c.Value)
+				}
+				text = shared.Items[n].String()
+			case "inlineStr":
+				text = c.Inline.String()
+			case "", "n":
+				// Numbers are stored as doubles, e.g. 0.1 as 0.10000000000000001
+				if f, err := strconv.ParseFloat(c.Value, 64); err == nil {
+					text = strconv.FormatFloat(f, 'f', -1, 64)
+				}
+			}
+			for len(row.cells) <= col {
+				row.cells = append(row.cells, "")
+			}
+			row.cells[col] = text
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// sheetText is a string in a sheet, either plain or as runs of formatted text.
+type sheetText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t sheetText) String() string {
+	s := t.Text
+	for _, r := range t.Runs {
+		s += r.Text
+	}
+	return s
+}
+
+// columnIndex returns the index of the column of a cell reference, e.g. 1
+// for "B9", or -1 if ref has no column.
+func columnIndex(ref string) int {
+	col := 0
+	for i := 0; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		col = col*26 + int(ref[i]-'A'+1)
+	}
+	return col - 1
+}
+
+// firstSheet returns the path in the workbook of its first sheet.
+func firstSheet(z *zip.Reader) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := readZipXML(z, "xl/workbook.xml", &workbook); err != nil {
+		return "", err
+	}
+	if err := readZipXML(z, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("the workbook has no sheets")
+	}
+	for _, r := range rels.Rels {
+		if r.ID == workbook.Sheets[0].ID {
+			if strings.HasPrefix(r.Target, "/") {
+				return strings.TrimPrefix(r.Target, "/"), nil
+			}
+			return path.Join("xl", r.Target), nil
+		}
+	}
+	return "", errors.New("the workbook's first sheet is missing")
+}
+
+// errNoZipFile is returned by readZipXML when the file isn't in the zip.
+var errNoZipFile = errors.New("missing from the workbook")
+
+// readZipXML decodes the XML file called name in z into v.
+func readZipXML(z *zip.Reader, name string, v any) error {
+	f, err := z.Open(name)
+	if err != nil {
+		return fmt.Errorf("%s is %w", name, errNoZipFile)
+	}
+	defer f.Close()
+	if err = xml.NewDecoder(io.LimitReader(f, 64<<20)).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+// Package loader reads question files into quiz questions, and writes
+// questions back out to files.
+//
+// Each format is a quiz.Loader registered for the file extensions or URL
+// schemes it handles.  Load picks the loader for a source from the
+// registry, so new formats only need to register themselves in an init
+// function to be usable everywhere a question file is read.  Formats that
+// can be written also register a quiz.Exporter for their extension.
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// Default loads a source with the loader registered for it, and can scan
+// it one question at a time, see Scan.
+var Default quiz.Loader = quiz.ScannerFunc(Scan)
+
+// DefaultExtension is used for sources whose extension has no loader
+// registered, since question files have always been CSV.
+const DefaultExtension = ".csv"
+
+var registry = struct {
+	sync.RWMutex
+	extensions map[string]quiz.Loader
+	schemes    map[string]quiz.Loader
+	exporters  map[string]quiz.Exporter
+}{
+	extensions: make(map[string]quiz.Loader),
+	schemes:    make(map[string]quiz.Loader),
+	exporters:  make(map[string]quiz.Exporter),
+}
+
+// RegisterExtension makes l the loader for files with extension ext, e.g. ".json".
+func RegisterExtension(ext string, l quiz.Loader) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.extensions[strings.ToLower(ext)] = l
+}
+
+// RegisterScheme makes l the loader for URLs with scheme, e.g. "https".
+func RegisterScheme(scheme string, l quiz.Loader) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.schemes[strings.ToLower(scheme)] = l
+}
+
+// RegisterExporter makes e the exporter for files with extension ext, e.g. ".json".
+func RegisterExporter(ext string, e quiz.Exporter) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.exporters[strings.ToLower(ext)] = e
+}
+
+// Extensions returns the file extensions that have a loader registered.
+func Extensions() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	var exts []string
+	for ext := range registry.extensions {
+		exts = append(exts, ext)
+	}
+	return exts
+}
+
+// Lookup returns the loader for source.  URLs are matched by their scheme
+// and everything else by its file extension.
+func Lookup(source string) (quiz.Loader, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	if u, err := url.Parse(source); err == nil && len(u.Scheme) > 1 {
+		if l, ok := registry.schemes[strings.ToLower(u.Scheme)]; ok {
+			return l, nil
+		}
+		return nil, fmt.Errorf("no loader for %s URLs", u.Scheme)
+	}
+
+	if l, ok := registry.extensions[strings.ToLower(filepath.Ext(source))]; ok {
+		return l, nil
+	}
+	if l, ok := registry.extensions[DefaultExtension]; ok {
+		return l, nil
+	}
+	return nil, fmt.Errorf("no loader for %s", source)
+}
+
+// RowError is a problem with one row, or item, of a question file.
+type RowError struct {
+	Path string //File the row is in
+	Line int    //Line the row starts on
+	Err  error  //What is wrong with the row
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.Path, e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// RowErrors returns the RowErrors in err, which a loader joins together
+// when it finds more than one bad row.
+func RowErrors(err error) []*RowError {
+	var rows []*RowError
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			rows = append(rows, RowErrors(e)...)
+		}
+		return rows
+	}
+	var row *RowError
+	if errors.As(err, &row) {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// SkipBadRows returns a loader that loads sources with l, leaving out the
+// rows that can't be read instead of failing, and writes which rows were
+// skipped and why to w.  Problems that aren't with rows still fail.
+func SkipBadRows(l quiz.Loader, w io.Writer) quiz.Loader {
+	return quiz.LoaderFunc(func(source string) ([]quiz.Question, error) {
+		questions, err := l.Load(source)
+		rows := RowErrors(err)
+		if len(rows) == 0 {
+			return questions, err
+		}
+		fmt.Fprintf(w, "Skipped %v rows of %s that couldn't be read, and loaded %v questions:\n", len(rows), source, len(questions))
+		for _, row := range rows {
+			fmt.Fprintf(w, "  line %v: %v\n", row.Line, row.Err)
+		}
+		return questions, nil
+	})
+}
+
+// Load reads the questions in source with the loader registered for it.
+func Load(source string) ([]quiz.Question, error) {
+	l, err := Lookup(source)
+	if err != nil {
+		return nil, err
+	}
+	return l.Load(source)
+}
+
+// Scan calls yield with each question in source until it returns false,
+// reading the file one question at a time if its loader is a quiz.Scanner
+// or else loading it all first.
+func Scan(source string, yield func(q quiz.Question) bool) error {
+	l, err := Lookup(source)
+	if err != nil {
+		return err
+	}
+	if s, ok := l.(quiz.Scanner); ok {
+		return s.Scan(source, yield)
+	}
+	questions, err := l.Load(source)
+	for _, q := range questions {
+		if !yield(q) {
+			break
+		}
+	}
+	return err
+}
+
+// LookupExporter returns the exporter for path, by its extension.  Unlike
+// Lookup there is no default format, so a file is never written in a
+// format its extension doesn't suggest.
+func LookupExporter(path string) (quiz.Exporter, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	if e, ok := registry.exporters[strings.ToLower(filepath.Ext(path))]; ok {
+		return e, nil
+	}
+	return nil, fmt.Errorf("no exporter for %s", path)
+}
+
+// Export writes questions to path with the exporter registered for it.
+func Export(path string, questions []quiz.Question) error {
+	e, err := LookupExporter(path)
+	if err != nil {
+		return err
+	}
+	return e.Export(path, questions)
+}
+
+// bom is the byte order mark that some editors, such as Excel and
+// Notepad, write at the start of UTF-8 files.
+var bom = []byte("\ufeff")
+
+// readFile reads the file at path, without any byte order mark at the
+// start, so it isn't taken as part of the first question.
+func readFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	return bytes.TrimPrefix(data, bom), err
+}
+
+// skipBOM returns a reader of r past any byte order mark at its start.
+func skipBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if start, err := br.Peek(len(bom)); err == nil && bytes.Equal(start, bom) {
+		br.Discard(len(bom))
+	}
+	return br
+}
@@ -0,0 +1,84 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	for _, ext := range []string{".yaml", ".yml"} {
+		RegisterExtension(ext, quiz.LoaderFunc(YAML))
+		RegisterExporter(ext, quiz.ExporterFunc(ExportYAML))
+	}
+}
+
+// yamlQuestion is how a question is written in a YAML question file.
+type yamlQuestion struct {
+	ID           string                 `yaml:"id,omitempty"`
+	Question     string                 `yaml:"question"`
+	Answer       string                 `yaml:"answer"`
+	Choices      []string               `yaml:"choices,omitempty"`
+	Hint         string                 `yaml:"hint,omitempty"`
+	Explanation  string                 `yaml:"explanation,omitempty"`
+	Points       float64                `yaml:"points,omitempty"`
+	Category     string                 `yaml:"category,omitempty"`
+	Difficulty   string                 `yaml:"difficulty,omitempty"`
+	TimeLimit    int                    `yaml:"timelimit,omitempty"`    //Seconds to answer the question
+	Translations map[string]translation `yaml:"translations,omitempty"` //The question in other languages, by language code
+}
+
+// YAML loads a YAML file containing a list of questions, with the same
+// fields as a JSON question file, e.g.
+//
+//   - question: Capital of France?
+//     answer: Paris
+//     choices: [London, Berlin]
+//     category: Capitals
+func YAML(path string) (questions []quiz.Question, err error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err = yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	list := doc.Content[0]
+	if list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%s:%d: the questions should be a list", path, list.Line)
+	}
+
+	var problems []error
+	for _, item := range list.Content {
+		var v yamlQuestion
+		if err = item.Decode(&v); err != nil {
+			problems = append(problems, &RowError{Path: path, Line: item.Line, Err: err})
+			continue
+		}
+		questions = append(questions, quiz.Question{ID: v.ID, QText: v.Question, Answer: v.Answer, Choices: v.Choices, Hint: v.Hint, Explanation: v.Explanation, Points: v.Points, Category: v.Category, Difficulty: v.Difficulty, TimeLimit: time.Duration(v.TimeLimit) * time.Second, Line: item.Line, Translations: fromTranslations(v.Translations)})
+	}
+	return questions, errors.Join(problems...)
+}
+
+// ExportYAML writes questions to a YAML file in the format YAML reads.
+func ExportYAML(path string, questions []quiz.Question) error {
+	records := make([]yamlQuestion, 0, len(questions))
+	for _, q := range questions {
+		records = append(records, yamlQuestion{ID: q.ID, Question: q.QText, Answer: q.Answer, Choices: q.Choices, Hint: q.Hint, Explanation: q.Explanation, Points: q.Points, Category: q.Category, Difficulty: q.Difficulty, TimeLimit: int(q.TimeLimit / time.Second), Translations: toTranslations(q.Translations)})
+	}
+
+	data, err := yaml.Marshal(records)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
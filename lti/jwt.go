@@ -0,0 +1,276 @@
+package lti
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// b64 is the base64 encoding used in JSON web tokens and keys.
+var b64 = base64.RawURLEncoding
+
+// jwtHeader is the header of a JSON web token.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// claims are the claims in a JSON web token.
+type claims map[string]any
+
+// str returns the string claim name, or "" if it isn't a string.
+func (c claims) str(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// time returns the time of the numeric claim name, or the zero time.
+func (c claims) time(name string) time.Time {
+	n, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(int64(n), 0)
+}
+
+// audience reports whether the aud claim, a string or a list of strings,
+// includes aud.
+func (c claims) audience(aud string) bool {
+	switch v := c["aud"].(type) {
+	case string:
+		return v == aud
+	case []any:
+		for _, a := range v {
+			if a == aud {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sign returns c as a JSON web token signed with key using RS256.
+func sign(c claims, key *rsa.PrivateKey, kid string) (string, error) {
+	header, err := json.Marshal(jwtHeader{Alg: "RS256", Typ: "JWT", Kid: kid})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	signed := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + b64.EncodeToString(sig), nil
+}
+
+// verify checks the RS256 signature of a JSON web token with the key
+// keys finds for it, and returns its claims.  The claims themselves
+// aren't checked.
+func verify(token string, keys *keySet) (claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("the token isn't a JSON web token")
+	}
+	var header jwtHeader
+	if err := decodePart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("the token's header can't be read: %w", err)
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("the token is signed with %s, only RS256 is supported", header.Alg)
+	}
+	key, err := keys.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("the token's signature can't be read: %w", err)
+	}
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err = rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], sig); err != nil {
+		return nil, errors.New("the token's signature is not valid")
+	}
+
+	var c claims
+	if err = decodePart(parts[1], &c); err != nil {
+		return nil, fmt.Errorf("the token's claims can't be read: %w", err)
+	}
+	return c, nil
+}
+
+// decodePart decodes a base64 JSON part of a token into v.
+func decodePart(part string, v any) error {
+	data, err := b64.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// jwk is a public RSA key in a JSON web key set.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// jwks is a JSON web key set.
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// publicJWK returns key as a JSON web key.
+func publicJWK(key *rsa.PublicKey, kid string) jwk {
+	return jwk{
+		Kty: "RSA", Kid: kid, Alg: "RS256", Use: "sig",
+		N: b64.EncodeToString(key.N.Bytes()),
+		E: b64.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// rsaKey returns the RSA public key of k.
+func (k jwk) rsaKey() (*rsa.PublicKey, error) {
+	if k.Kty != "RSA" {
+		return nil, fmt.Errorf("key %s is %s, not RSA", k.Kid, k.Kty)
+	}
+	n, err := b64.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := b64.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+}
+
+// refetchInterval is how long the platform's keys are kept before a token
+// signed with an unknown key fetches them again, so tokens with made up
+// key IDs can't make the tool hit the platform on every launch.
+const refetchInterval = time.Minute
+
+// keySet is the platform's public keys, fetched from its JWKS URL and
+// fetched again when a token is signed with a key that isn't known yet,
+// at most once every refetchInterval.
+type keySet struct {
+	URL string
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time //When the keys were last fetched, or tried to be
+}
+
+// key returns the key with the ID kid.
+func (s *keySet) key(kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if k, ok := s.keys[kid]; ok {
+		return k, nil
+	}
+	if time.Since(s.fetched) < refetchInterval {
+		return nil, fmt.Errorf("the platform has no key %q", kid)
+	}
+	s.fetched = time.Now()
+	if err := s.fetch(); err != nil {
+		return nil, fmt.Errorf("unable to fetch the platform's keys: %w", err)
+	}
+	if k, ok := s.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("the platform has no key %q", kid)
+}
+
+func (s *keySet) fetch() error {
+	resp, err := client.Get(s.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", s.URL, resp.Status)
+	}
+	var set jwks
+	if err = json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return err
+	}
+
+	s.keys = make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if key, err := k.rsaKey(); err == nil {
+			s.keys[k.Kid] = key
+		}
+	}
+	return nil
+}
+
+// loadKey loads the tool's private key from a PEM file, generating and
+// saving a new key the first time the tool is run.
+func loadKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+		if err = os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s isn't a PEM file", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s isn't an RSA key", path)
+	}
+	return key, nil
+}
+
+// keyID returns an ID for key made from a hash of it.
+func keyID(key *rsa.PublicKey) string {
+	sum := sha256.Sum256(x509.MarshalPKCS1PublicKey(key))
+	return b64.EncodeToString(sum[:12])
+}
+
+// randomID returns a random string that can't be guessed.
+func randomID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return b64.EncodeToString(b)
+}
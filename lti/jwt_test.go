@@ -0,0 +1,160 @@
+package lti
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testPlatform serves the JWKS of a platform's key and counts how often
+// it is fetched.
+type testPlatform struct {
+	key     *rsa.PrivateKey
+	kid     string
+	fetches atomic.Int32
+	srv     *httptest.Server
+}
+
+func newTestPlatform(t *testing.T) *testPlatform {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &testPlatform{key: key, kid: "platform-key"}
+	p.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.fetches.Add(1)
+		writeJSON(w, jwks{Keys: []jwk{publicJWK(&key.PublicKey, p.kid)}})
+	}))
+	t.Cleanup(p.srv.Close)
+	return p
+}
+
+// tool returns a tool for the platform with a login waiting for a launch
+// with state and nonce.
+func (p *testPlatform) tool(state, nonce string) *Tool {
+	return &Tool{
+		Platform: Platform{Issuer: "https://lms.example.com", ClientID: "client-1", JWKSURL: p.srv.URL},
+		keys:     &keySet{URL: p.srv.URL},
+		logins:   map[string]login{state: {nonce: nonce, expires: time.Now().Add(loginTimeout)}},
+		sessions: map[string]*session{},
+	}
+}
+
+// launchClaims returns the claims of a valid launch with nonce.
+func launchClaims(nonce string) claims {
+	return claims{
+		"iss":             "https://lms.example.com",
+		"aud":             "client-1",
+		"sub":             "student-1",
+		"exp":             time.Now().Add(time.Hour).Unix(),
+		"nonce":           nonce,
+		claimMessageType:  "LtiResourceLinkRequest",
+		claimVersion:      "1.3.0",
+		claimDeploymentID: "1",
+	}
+}
+
+func TestCheckLaunch(t *testing.T) {
+	p := newTestPlatform(t)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		change  func(c claims)
+		key     *rsa.PrivateKey
+		token   func(token string) string
+		wantErr string
+	}{
+		{name: "valid"},
+		{name: "bad signature", key: other, wantErr: "signature is not valid"},
+		{name: "changed claims", token: func(token string) string {
+			parts := strings.Split(token, ".")
+			parts[1] = b64.EncodeToString([]byte(`{"sub":"teacher-1"}`))
+			return strings.Join(parts, ".")
+		}, wantErr: "signature is not valid"},
+		{name: "unsigned", token: func(token string) string {
+			parts := strings.Split(token, ".")
+			return b64.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+		}, wantErr: "only RS256"},
+		{name: "expired", change: func(c claims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, wantErr: "expired"},
+		{name: "wrong issuer", change: func(c claims) { c["iss"] = "https://evil.example.com" }, wantErr: "not \"https://lms.example.com\""},
+		{name: "wrong audience", change: func(c claims) { c["aud"] = "client-2" }, wantErr: "isn't for this tool"},
+		{name: "audience list", change: func(c claims) { c["aud"] = []any{"client-2", "client-1"} }},
+		{name: "wrong nonce", change: func(c claims) { c["nonce"] = "other" }, wantErr: "nonce"},
+		{name: "no user", change: func(c claims) { delete(c, "sub") }, wantErr: "who the user is"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := launchClaims("nonce-1")
+			if tt.change != nil {
+				tt.change(c)
+			}
+			key := p.key
+			if tt.key != nil {
+				key = tt.key
+			}
+			token, err := sign(c, key, p.kid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.token != nil {
+				token = tt.token(token)
+			}
+
+			_, err = p.tool("state-1", "nonce-1").checkLaunch("state-1", token)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("checkLaunch() = %v, want no error", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("checkLaunch() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckLaunchReplay(t *testing.T) {
+	p := newTestPlatform(t)
+	token, err := sign(launchClaims("nonce-1"), p.key, p.kid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := p.tool("state-1", "nonce-1")
+	if _, err = tool.checkLaunch("state-1", token); err != nil {
+		t.Fatalf("first launch: %v", err)
+	}
+	if _, err = tool.checkLaunch("state-1", token); err == nil {
+		t.Fatal("the same launch was accepted twice")
+	}
+}
+
+func TestKeySetRefetch(t *testing.T) {
+	p := newTestPlatform(t)
+	keys := &keySet{URL: p.srv.URL}
+	if _, err := keys.key(p.kid); err != nil {
+		t.Fatal(err)
+	}
+	for range 10 {
+		if _, err := keys.key("made-up"); err == nil {
+			t.Fatal("a key the platform doesn't have was found")
+		}
+	}
+	if n := p.fetches.Load(); n != 1 {
+		t.Errorf("the keys were fetched %v times, want 1", n)
+	}
+
+	// Once the interval has passed an unknown key fetches them again
+	keys.fetched = time.Now().Add(-refetchInterval)
+	keys.key("made-up")
+	if n := p.fetches.Load(); n != 2 {
+		t.Errorf("the keys were fetched %v times, want 2", n)
+	}
+}
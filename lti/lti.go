@@ -0,0 +1,232 @@
+// Package lti lets a learning management system such as Moodle, Canvas or
+// Blackboard embed the quiz as an LTI 1.3 tool.  A launch from the
+// platform starts a quiz session in the browser for the student who
+// launched it, and their score is sent back to the platform's gradebook
+// with LTI Assignment and Grade Services.
+//
+// The tool serves these endpoints, which are given to the platform when
+// the tool is registered:
+//
+//	/lti/login   OpenID Connect login initiation URL
+//	/lti/launch  launch (redirect) URL
+//	/lti/jwks    the tool's public keys
+package lti
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// The claims of an LTI 1.3 launch.
+const (
+	claimMessageType  = "https://purl.imsglobal.org/spec/lti/claim/message_type"
+	claimVersion      = "https://purl.imsglobal.org/spec/lti/claim/version"
+	claimDeploymentID = "https://purl.imsglobal.org/spec/lti/claim/deployment_id"
+	claimAGS          = "https://purl.imsglobal.org/spec/lti-ags/claim/endpoint"
+)
+
+// Platform is how the tool reaches the LMS that launches it.  The values
+// come from the platform when the tool is registered with it.
+type Platform struct {
+	Issuer   string //Issuer of the platform's tokens, e.g. "https://moodle.example.com"
+	ClientID string //Client ID the platform gave the tool
+	AuthURL  string //Platform's OpenID Connect authorization endpoint
+	TokenURL string //Platform's OAuth 2 token endpoint, for sending grades
+	JWKSURL  string //Platform's public keys
+}
+
+// Tool is an LTI 1.3 tool that runs the quiz for students launched from
+// a platform.
+type Tool struct {
+	Addr     string           //Address the tool listens on, e.g. ":8443"
+	URL      string           //Public URL of the tool, e.g. "https://quiz.example.com"
+	KeyFile  string           //Path to the tool's private RSA key. It is created if it doesn't exist.
+	Platform Platform         //The platform that launches the tool
+	Test     *quiz.Assessment //The loaded test. Each launch gets its own session of it.
+
+	// OnFinished, if set, is called with each session when it finishes.
+	OnFinished func(a *quiz.Assessment)
+
+	key    *rsa.PrivateKey
+	kid    string
+	keys   *keySet
+	grades *grades
+
+	mu       sync.Mutex
+	logins   map[string]login    //Logins waiting for their launch, by state
+	sessions map[string]*session //Sessions by ID
+}
+
+// login is an OpenID Connect login waiting for the platform to launch the tool.
+type login struct {
+	nonce   string
+	expires time.Time
+}
+
+// loginTimeout is how long the platform has to launch the tool after a login.
+const loginTimeout = 10 * time.Minute
+
+// client makes the tool's requests to the platform, giving up on a
+// platform that doesn't answer.
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// ListenAndServe loads the tool's key and serves the tool.
+func (t *Tool) ListenAndServe() (err error) {
+	if t.key, err = loadKey(t.KeyFile); err != nil {
+		return fmt.Errorf("unable to load the LTI key: %w", err)
+	}
+	t.kid = keyID(&t.key.PublicKey)
+	t.keys = &keySet{URL: t.Platform.JWKSURL}
+	t.grades = &grades{tool: t}
+	t.logins = make(map[string]login)
+	t.sessions = make(map[string]*session)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lti/login", t.handleLogin)
+	mux.HandleFunc("/lti/launch", t.handleLaunch)
+	mux.HandleFunc("/lti/jwks", t.handleJWKS)
+	mux.HandleFunc("/lti/quiz/", t.handleQuiz)
+
+	fmt.Printf("LTI tool is listening on %s. Register it with the platform using:\n", t.Addr)
+	fmt.Printf("  Login URL:  %s/lti/login\n  Launch URL: %s/lti/launch\n  Keys URL:   %s/lti/jwks\n", t.URL, t.URL, t.URL)
+	return http.ListenAndServe(t.Addr, mux)
+}
+
+// handleLogin starts an OpenID Connect login for a launch, sending the
+// browser back to the platform to authenticate the user.
+func (t *Tool) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.Form.Get("iss") != t.Platform.Issuer {
+		http.Error(w, "unknown platform "+r.Form.Get("iss"), http.StatusBadRequest)
+		return
+	}
+	if id := r.Form.Get("client_id"); id != "" && id != t.Platform.ClientID {
+		http.Error(w, "unknown client "+id, http.StatusBadRequest)
+		return
+	}
+
+	state, nonce := randomID(), randomID()
+	t.mu.Lock()
+	for s, l := range t.logins {
+		if time.Now().After(l.expires) {
+			delete(t.logins, s)
+		}
+	}
+	t.logins[state] = login{nonce: nonce, expires: time.Now().Add(loginTimeout)}
+	t.mu.Unlock()
+
+	auth, err := url.Parse(t.Platform.AuthURL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	q := auth.Query()
+	q.Set("scope", "openid")
+	q.Set("response_type", "id_token")
+	q.Set("response_mode", "form_post")
+	q.Set("prompt", "none")
+	q.Set("client_id", t.Platform.ClientID)
+	q.Set("redirect_uri", t.URL+"/lti/launch")
+	q.Set("login_hint", r.Form.Get("login_hint"))
+	if hint := r.Form.Get("lti_message_hint"); hint != "" {
+		q.Set("lti_message_hint", hint)
+	}
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	auth.RawQuery = q.Encode()
+	http.Redirect(w, r, auth.String(), http.StatusFound)
+}
+
+// handleLaunch checks the platform's id_token and starts a quiz session
+// for the user it names.
+func (t *Tool) handleLaunch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "launches must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	c, err := t.checkLaunch(r.PostFormValue("state"), r.PostFormValue("id_token"))
+	if err != nil {
+		log.Printf("LTI launch refused: %v", err)
+		http.Error(w, "The launch could not be verified: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	s := t.newSession(c)
+	http.Redirect(w, r, "/lti/quiz/"+s.id, http.StatusSeeOther)
+}
+
+// checkLaunch checks the state and id_token of a launch and returns the
+// token's claims.
+func (t *Tool) checkLaunch(state, token string) (claims, error) {
+	t.mu.Lock()
+	l, ok := t.logins[state]
+	delete(t.logins, state)
+	t.mu.Unlock()
+	if !ok || time.Now().After(l.expires) {
+		return nil, errors.New("the launch doesn't match a login, or took too long")
+	}
+
+	c, err := verify(token, t.keys)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	switch {
+	case c.str("iss") != t.Platform.Issuer:
+		return nil, fmt.Errorf("the token is from %q, not %q", c.str("iss"), t.Platform.Issuer)
+	case !c.audience(t.Platform.ClientID):
+		return nil, errors.New("the token isn't for this tool")
+	case now.After(c.time("exp").Add(time.Minute)):
+		return nil, errors.New("the token has expired")
+	case c.str("nonce") != l.nonce:
+		return nil, errors.New("the token's nonce doesn't match the login")
+	case c.str(claimMessageType) != "LtiResourceLinkRequest":
+		return nil, fmt.Errorf("%q launches aren't supported", c.str(claimMessageType))
+	case c.str(claimVersion) != "1.3.0":
+		return nil, fmt.Errorf("LTI version %q isn't supported", c.str(claimVersion))
+	case c.str(claimDeploymentID) == "":
+		return nil, errors.New("the token has no deployment ID")
+	case c.str("sub") == "":
+		return nil, errors.New("the token doesn't say who the user is")
+	}
+	return c, nil
+}
+
+// handleJWKS serves the tool's public key, which the platform checks the
+// tool's tokens with.
+func (t *Tool) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, jwks{Keys: []jwk{publicJWK(&t.key.PublicKey, t.kid)}})
+}
+
+// handleQuiz shows the session's current question, or takes the answer to it.
+func (t *Tool) handleQuiz(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/lti/quiz/")
+	t.mu.Lock()
+	s, ok := t.sessions[id]
+	t.mu.Unlock()
+	if !ok {
+		http.Error(w, "There is no such quiz session, launch the quiz again from your course.", http.StatusNotFound)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		number, _ := strconv.Atoi(r.PostFormValue("question"))
+		s.answer(number, r.PostFormValue("answer"))
+		http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+		return
+	}
+	s.render(w)
+}
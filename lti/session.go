@@ -0,0 +1,341 @@
+package lti
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"math/rand"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// sessionTTL is how long a session is kept after it starts, so a student
+// can see their score again.
+const sessionTTL = 24 * time.Hour
+
+// session is one student's run through the quiz in their browser.
+type session struct {
+	id       string
+	tool     *Tool
+	userID   string //The platform's ID for the student
+	lineitem string //Where the grade is sent, empty if the platform doesn't take grades
+	a        *quiz.Assessment
+	rand     *rand.Rand
+
+	mu      sync.Mutex
+	index   int            //Index in a.Questions of the question being asked
+	current *quiz.Question //Question being asked, nil before the first and after the last
+	options []string       //Options of the current question in the order shown
+	asked   time.Time      //When the current question was first shown
+	message string         //What happened to the last answer, if it needs saying
+	done    bool
+	pending bool   //Whether the test is done but its grade hasn't been sent yet
+	graded  string //Whether the grade was sent to the platform
+}
+
+// newSession starts a quiz session for the user named by the claims of a launch.
+func (t *Tool) newSession(c claims) *session {
+	s := &session{
+		id:     randomID(),
+		tool:   t,
+		userID: c.str("sub"),
+		a:      t.Test.NewSession(nil, nil),
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	s.a.Name = c.str("name")
+	if s.a.Name == "" {
+		s.a.Name = strings.TrimSpace(c.str("given_name") + " " + c.str("family_name"))
+	}
+	if s.a.Name == "" {
+		s.a.Name = s.userID
+	}
+
+	// Grades can only be sent when the platform gives a line item and lets
+	// the tool post scores to it
+	if ags, ok := c[claimAGS].(map[string]any); ok {
+		lineitem, _ := ags["lineitem"].(string)
+		scopes, _ := ags["scope"].([]any)
+		if slices.Contains(scopes, any(scoreScope)) {
+			s.lineitem = lineitem
+		}
+	}
+
+	s.a.TimeStart = time.Now()
+	s.next()
+
+	// A student who leaves before the time runs out still gets a grade
+	if s.a.TimeLimit > 0 {
+		time.AfterFunc(s.a.TimeLimit, func() {
+			s.mu.Lock()
+			s.expired()
+			s.mu.Unlock()
+			s.sendGrade()
+		})
+	}
+
+	t.mu.Lock()
+	for id, old := range t.sessions {
+		if time.Since(old.a.TimeStart) > sessionTTL {
+			delete(t.sessions, id)
+		}
+	}
+	t.sessions[s.id] = s
+	t.mu.Unlock()
+	log.Printf("LTI launch for %s (%s)", s.a.Name, s.userID)
+	return s
+}
+
+// next moves on to the next question, or finishes the test when there
+// are no more.  Questions from a Source are added to the test as they are asked.
+func (s *session) next() {
+	a := s.a
+	if s.current != nil {
+		s.index++
+	}
+	s.current = nil
+	if a.Source == nil {
+		if s.index < len(a.Questions) {
+			s.current = &a.Questions[s.index]
+		}
+	} else if (a.TotalQuestions == 0 || s.index < a.TotalQuestions) && a.Source.HasNext() {
+		q, err := a.Source.Next()
+		if err != nil {
+			log.Printf("unable to get the next question for %s: %v", a.Name, err)
+		} else {
+			a.Questions = append(a.Questions, q)
+			s.current = &a.Questions[len(a.Questions)-1]
+		}
+	}
+
+	if s.current == nil {
+		s.finish()
+		return
+	}
+	s.options = s.current.Options()
+	s.rand.Shuffle(len(s.options), func(i, j int) { s.options[i], s.options[j] = s.options[j], s.options[i] })
+	s.asked = time.Now()
+}
+
+// expired finishes the test if its time limit has run out and reports
+// whether it has.
+func (s *session) expired() bool {
+	if !s.done && s.a.TimeLimit > 0 && time.Since(s.a.TimeStart) > s.a.TimeLimit {
+		s.message = "Time's up!"
+		s.finish()
+	}
+	return s.done
+}
+
+// answer grades the answer to question number, counting from 1, and moves
+// on.  An answer to any question but the current one, sent again by a double
+// click or the back button, is ignored so it isn't taken for the next one's.
+func (s *session) answer(number int, answer string) {
+	s.mu.Lock()
+	if number == s.index+1 {
+		s.grade(answer)
+	}
+	s.mu.Unlock()
+	s.sendGrade()
+}
+
+// grade grades the answer to the current question with the test's Grader,
+// as it is graded in the terminal, and moves on.
+func (s *session) grade(answer string) {
+	if s.expired() || s.current == nil {
+		return
+	}
+
+	q := s.current
+	s.message = ""
+	if limit := s.a.TimeFor(q); limit > 0 && time.Since(s.asked) > limit {
+		q.UserAnswer, q.Correct = "", false
+		s.message = "Out of time for that question."
+	} else if err := s.a.Grade(context.Background(), q, answer); err != nil {
+		log.Printf("unable to grade %s's answer: %v", s.a.Name, err)
+	}
+	if q.Correct {
+		s.a.TotalCorrect++
+	} else {
+		s.a.TotalIncorrect++
+	}
+	s.next()
+}
+
+// finish ends the test, leaving its grade for sendGrade to send once
+// s.mu is unlocked, so the session isn't locked while the platform is
+// slow to take it.
+func (s *session) finish() {
+	if s.done {
+		return
+	}
+	s.done, s.pending = true, true
+	s.current = nil
+	a := s.a
+	if a.Source != nil && a.TotalQuestions == 0 {
+		a.TotalQuestions = a.TotalCorrect + a.TotalIncorrect
+	}
+	if s.lineitem == "" {
+		s.graded = "This course doesn't take grades from the quiz."
+	} else {
+		s.graded = "Your grade is being sent to your course."
+	}
+}
+
+// sendGrade sends the grade of a finished test to the platform, if it
+// hasn't been sent, and calls the tool's OnFinished.  It must be called
+// without s.mu locked.
+func (s *session) sendGrade() {
+	s.mu.Lock()
+	if !s.pending {
+		s.mu.Unlock()
+		return
+	}
+	s.pending = false
+	a := s.a
+	result, err := a.Score()
+	if err != nil {
+		log.Printf("unable to score %s's test: %v", a.Name, err)
+	}
+	sc := score{
+		UserID:           s.userID,
+		ScoreGiven:       result,
+		ScoreMaximum:     100,
+		Comment:          fmt.Sprintf("%v of %v questions correct", a.TotalCorrect, a.Total()),
+		Timestamp:        time.Now().Format(time.RFC3339),
+		ActivityProgress: "Completed",
+		GradingProgress:  "FullyGraded",
+	}
+	s.mu.Unlock()
+
+	if s.lineitem != "" {
+		graded := "Your grade has been sent to your course."
+		if err = s.tool.grades.send(s.lineitem, sc); err != nil {
+			log.Printf("unable to send %s's grade: %v", a.Name, err)
+			graded = "Your grade couldn't be sent to your course, please tell your teacher."
+		}
+		s.mu.Lock()
+		s.graded = graded
+		s.mu.Unlock()
+	}
+	log.Printf("%s finished the quiz with %.2f%%", a.Name, result)
+	if s.tool.OnFinished != nil {
+		s.tool.OnFinished(a)
+	}
+}
+
+// page is what the quiz page shows.
+type page struct {
+	Title     string
+	Name      string
+	Message   string
+	Number    int
+	Total     int
+	Question  *quiz.Question
+	Options   []string
+	TimeLeft  time.Duration //Time left for the test, or the question if that is less
+	Done      bool
+	Score     float64
+	Correct   int
+	Questions []quiz.Question
+	Graded    string
+}
+
+// render writes the page for the session's current state.
+func (s *session) render(w http.ResponseWriter) {
+	s.mu.Lock()
+	s.expired()
+	s.mu.Unlock()
+	s.sendGrade()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.a
+	p := page{
+		Title:    strings.TrimSuffix(filepath.Base(a.FilePath), filepath.Ext(a.FilePath)),
+		Name:     a.Name,
+		Message:  s.message,
+		Number:   s.index + 1,
+		Total:    a.Total(),
+		Question: s.current,
+		Options:  s.options,
+		Done:     s.done,
+		Correct:  a.TotalCorrect,
+		Graded:   s.graded,
+	}
+	if s.done {
+		p.Score, _ = a.Score()
+		p.Questions = a.Questions[:a.TotalCorrect+a.TotalIncorrect]
+	} else {
+		if a.TimeLimit > 0 {
+			p.TimeLeft = time.Until(a.TimeStart.Add(a.TimeLimit))
+		}
+		limit := a.TimeFor(s.current)
+		if left := time.Until(s.asked.Add(limit)); limit > 0 && (p.TimeLeft == 0 || left < p.TimeLeft) {
+			p.TimeLeft = left
+		}
+		p.TimeLeft = p.TimeLeft.Round(time.Second)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := quizPage.Execute(w, p); err != nil {
+		log.Printf("unable to show the quiz page: %v", err)
+	}
+}
+
+// writeJSON writes v as the JSON response.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("unable to write response: %v", err)
+	}
+}
+
+// quizPage shows a question, or the score when the test is done.  When
+// there is a time limit the page reloads itself when it runs out.
+var quizPage = template.Must(template.New("quiz").Funcs(template.FuncMap{"inc": func(i int) int { return i + 1 }}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+{{if and (not .Done) (gt .TimeLeft 0)}}<meta http-equiv="refresh" content="{{.TimeLeft.Seconds}}">{{end}}
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 40em; margin: 2em auto; padding: 0 1em; }
+.message { color: #a00; }
+label { display: block; margin: 0.5em 0; }
+table { border-collapse: collapse; width: 100%; }
+td, th { border: 1px solid #ccc; padding: 0.3em; text-align: left; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Message}}<p class="message">{{.Message}}</p>{{end}}
+{{if .Done}}
+<p>You got {{.Correct}} of {{.Total}} questions right, {{.Name}}.  Your score is {{printf "%.2f" .Score}}%.</p>
+<p>{{.Graded}}</p>
+<table>
+<tr><th>#</th><th>Question</th><th>Answer</th><th>Your Answer</th><th>Correct</th></tr>
+{{range $i, $q := .Questions}}<tr><td>{{inc $i}}</td><td>{{$q.QText}}</td><td>{{$q.Answer}}</td><td>{{$q.UserAnswer}}</td><td>{{if $q.Correct}}Yes{{else}}No{{end}}</td></tr>
+{{end}}</table>
+{{else}}
+<p>Question {{.Number}} of {{.Total}}{{if gt .TimeLeft 0}}, {{.TimeLeft}} left{{end}}</p>
+<form method="post">
+<input type="hidden" name="question" value="{{.Number}}">
+<p><strong>{{.Question.QText}}</strong></p>
+{{if .Options}}{{range .Options}}<label><input type="radio" name="answer" value="{{.}}" required> {{.}}</label>
+{{end}}{{else}}<p><input type="text" name="answer" autofocus autocomplete="off"></p>
+{{end}}<p><button type="submit">Answer</button></p>
+</form>
+{{end}}
+</body>
+</html>
+`))
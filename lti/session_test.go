@@ -0,0 +1,141 @@
+package lti
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// gradebook is a platform that hands out access tokens and takes scores.
+func gradebook(t *testing.T, scores chan<- score) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			writeJSON(w, map[string]any{"access_token": "token-1", "expires_in": 3600})
+		case "/lineitem/scores":
+			var s score
+			if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+				t.Error(err)
+			}
+			scores <- s
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// gradedTool returns a tool whose platform takes grades at srv.
+func gradedTool(t *testing.T, srv *httptest.Server, test *quiz.Assessment) *Tool {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := &Tool{
+		Platform: Platform{ClientID: "client-1", TokenURL: srv.URL + "/token"},
+		Test:     test,
+		key:      key,
+		kid:      keyID(&key.PublicKey),
+		sessions: map[string]*session{},
+	}
+	tool.grades = &grades{tool: tool}
+	return tool
+}
+
+// gradedLaunch returns the claims of a launch whose grade goes to srv.
+func gradedLaunch(srv *httptest.Server) claims {
+	c := launchClaims("nonce-1")
+	c[claimAGS] = map[string]any{"lineitem": srv.URL + "/lineitem", "scope": []any{scoreScope}}
+	return c
+}
+
+func TestSessionGrade(t *testing.T) {
+	scores := make(chan score, 1)
+	srv := gradebook(t, scores)
+	test := &quiz.Assessment{Questions: []quiz.Question{{QText: "1+1", Answer: "2"}, {QText: "2+2", Answer: "4"}}, TotalQuestions: 2}
+	s := gradedTool(t, srv, test).newSession(gradedLaunch(srv))
+
+	s.answer(1, "2")
+	s.answer(2, "5")
+	select {
+	case got := <-scores:
+		if got.UserID != "student-1" || got.ScoreGiven != 50 || got.ScoreMaximum != 100 {
+			t.Errorf("the platform was sent %+v, want 50 of 100 for student-1", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no grade was sent")
+	}
+	if s.graded != "Your grade has been sent to your course." {
+		t.Errorf("the student was told %q", s.graded)
+	}
+
+	// Answering again doesn't send the grade twice
+	s.answer(3, "4")
+	select {
+	case got := <-scores:
+		t.Errorf("a second grade was sent: %+v", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSessionAbandoned(t *testing.T) {
+	scores := make(chan score, 1)
+	srv := gradebook(t, scores)
+	test := &quiz.Assessment{Questions: []quiz.Question{{QText: "1+1", Answer: "2"}, {QText: "2+2", Answer: "4"}}, TotalQuestions: 2, TimeLimit: 50 * time.Millisecond}
+	s := gradedTool(t, srv, test).newSession(gradedLaunch(srv))
+
+	// The student answers one question and closes the page
+	s.answer(1, "2")
+	select {
+	case got := <-scores:
+		if got.ScoreGiven != 50 {
+			t.Errorf("the platform was sent a score of %v, want 50", got.ScoreGiven)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no grade was sent when the time ran out")
+	}
+}
+
+func TestSessionAnswer(t *testing.T) {
+	arithmetic := []quiz.Question{{QText: "1+1", Answer: "2"}, {QText: "2+2", Answer: "4"}, {QText: "3+3", Answer: "6"}}
+	tests := []struct {
+		name        string
+		grader      quiz.Grader
+		answers     []string //Answers to each question in turn, with the number sent
+		wantCorrect int
+		wantIndex   int
+	}{
+		{name: "in turn", answers: []string{"1:2", "2:4", "3:6"}, wantCorrect: 3, wantIndex: 3},
+		{name: "sent twice", answers: []string{"1:2", "1:2"}, wantCorrect: 1, wantIndex: 1},
+		{name: "back button", answers: []string{"1:2", "2:4", "1:2"}, wantCorrect: 2, wantIndex: 2},
+		{name: "no number", answers: []string{":2"}, wantIndex: 0},
+		{name: "normalized", answers: []string{"1: 2 ", "2:\uff14"}, wantCorrect: 2, wantIndex: 2},
+		{name: "grader", grader: func(ctx context.Context, q *quiz.Question, answer string) (bool, error) { return answer == "two", nil }, answers: []string{"1:two", "2:4"}, wantCorrect: 1, wantIndex: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := &quiz.Assessment{Questions: arithmetic, TotalQuestions: 3, Grader: tt.grader}
+			s := (&Tool{Test: test, sessions: map[string]*session{}}).newSession(launchClaims("nonce-1"))
+			for _, a := range tt.answers {
+				number, answer, _ := strings.Cut(a, ":")
+				n, _ := strconv.Atoi(number)
+				s.answer(n, answer)
+			}
+			if s.a.TotalCorrect != tt.wantCorrect || s.index != tt.wantIndex {
+				t.Errorf("%v right and on question %v, want %v right and on question %v", s.a.TotalCorrect, s.index+1, tt.wantCorrect, tt.wantIndex+1)
+			}
+		})
+	}
+}
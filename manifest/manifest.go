@@ -0,0 +1,269 @@
+// Package manifest runs formal exams described by a manifest file: a title
+// page, instructions, a declaration the candidate must accept before
+// starting, and sections that each have their own question bank and time
+// limits, asked one after another.
+package manifest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/rastewart/go-quiz-game/quiz"
+	"gopkg.in/yaml.v3"
+)
+
+// Exam is an exam manifest, e.g.
+//
+//	title: Networking Final
+//	instructions: Answer every question.  You can't go back to a section.
+//	declaration: I will sit this exam on my own, without notes.
+//	pass: 60
+//	nopaste: true
+//	sections:
+//	  - title: Addressing
+//	    bank: addressing.csv
+//	    questions: 10
+//	    timelimit: 10m
+//	  - title: Routing
+//	    bank: routing.json
+//	    timelimit: 15m
+//	    questionlimit: 1m
+type Exam struct {
+	Title        string    `yaml:"title"`
+	Description  string    `yaml:"description"`  //Shown on the title page
+	Instructions string    `yaml:"instructions"` //Shown before the declaration
+	Declaration  string    `yaml:"declaration"`  //Must be accepted before the exam starts, if any
+	Pass         float64   `yaml:"pass"`         //Percentage needed to pass, 0 for no pass mark
+	NoPaste      bool      `yaml:"nopaste"`      //Whether answers must be typed rather than pasted
+	OnDuplicate  string    `yaml:"onduplicate"`  //What to do with questions asked in more than one place, see quiz.HandleDuplicates
+	Sections     []Section `yaml:"sections"`
+
+	path  string             //File the manifest was read from
+	tests []*quiz.Assessment //Tests for the sections, in order
+}
+
+// Section is a part of an exam with its own questions and time limits.
+type Section struct {
+	Title         string        `yaml:"title"`
+	Instructions  string        `yaml:"instructions"`
+	Bank          string        `yaml:"bank"`          //Question file or URL, relative to the manifest
+	Questions     int           `yaml:"questions"`     //Number of questions, 0 for all of them
+	Shuffle       bool          `yaml:"shuffle"`       //Whether the questions are shuffled
+	Seed          int64         `yaml:"seed"`          //Seed for shuffling, 0 for a different order every time
+	TimeLimit     time.Duration `yaml:"timelimit"`     //Time for the section, 0 for no limit
+	QuestionLimit time.Duration `yaml:"questionlimit"` //Time for each question, 0 for no limit
+}
+
+// Load reads the exam manifest at path and loads the questions of its
+// sections with l, so a bad bank is found before the exam starts.
+// Questions repeated within or across the sections are handled as
+// OnDuplicate says, with any warnings written to w.
+func Load(ctx context.Context, path string, l quiz.Loader, w io.Writer) (*Exam, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	e := &Exam{path: path}
+	if err = yaml.Unmarshal(data, e); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(e.Sections) == 0 {
+		return nil, fmt.Errorf("%s: the exam has no sections", path)
+	}
+
+	seen := make(map[string]string)
+	for i, s := range e.Sections {
+		if s.Bank == "" {
+			return nil, fmt.Errorf("%s: section %v has no bank of questions", path, i+1)
+		}
+		test := quiz.NewAssessment(quiz.Config{
+			FilePath:       e.bankPath(s.Bank),
+			Shuffle:        s.Shuffle,
+			Seed:           s.Seed,
+			TotalQuestions: s.Questions,
+			TimeLimit:      s.TimeLimit,
+			QuestionLimit:  s.QuestionLimit,
+		})
+		if err = test.LoadQuestions(ctx, l); err != nil {
+			return nil, fmt.Errorf("%s: section %v: %w", path, i+1, err)
+		}
+		if test.Questions, err = quiz.HandleDuplicates(test.Questions, test.FilePath, seen, e.OnDuplicate, w); err != nil {
+			return nil, fmt.Errorf("%s: section %v: %w", path, i+1, err)
+		}
+		if len(test.Questions) == 0 {
+			return nil, fmt.Errorf("%s: section %v: every question was asked in an earlier section", path, i+1)
+		}
+		test.TotalQuestions = len(test.Questions)
+		e.tests = append(e.tests, test)
+	}
+	return e, nil
+}
+
+// bankPath returns where a section's bank is: a URL or absolute path as
+// it is, or else a path relative to the manifest.
+func (e *Exam) bankPath(bank string) string {
+	if u, err := url.Parse(bank); (err == nil && len(u.Scheme) > 1) || filepath.IsAbs(bank) {
+		return bank
+	}
+	return filepath.Join(filepath.Dir(e.path), bank)
+}
+
+// Name returns the exam's title, or the name of its manifest if it has
+// no title.
+func (e *Exam) Name() string {
+	if e.Title != "" {
+		return e.Title
+	}
+	return filepath.Base(e.path)
+}
+
+// Tests returns the tests for the sections, so handlers can be registered
+// with them before the exam starts.
+func (e *Exam) Tests() []*quiz.Assessment {
+	return e.tests
+}
+
+// Run sits the exam, reading the candidate's input from in and writing
+// to out.  It shows the title page, asks for the candidate's name, shows
+// the instructions and waits for the declaration to be accepted, then
+// runs each section in turn.  A section that runs out of time ends and
+// the next one starts.  The results of every section are shown at the end.
+// If ctx is cancelled the exam stops and ctx's error is returned.
+func (e *Exam) Run(ctx context.Context, in io.Reader, out io.Writer) (err error) {
+	input := quiz.NewInput(in)
+	defer input.Close()
+	readLine := func() (string, error) {
+		line, err := input.ReadLine(ctx)
+		return strings.TrimSpace(line), err
+	}
+
+	e.titlePage(out)
+	fmt.Fprint(out, "Please enter your name: ")
+	name, err := readLine()
+	if err != nil {
+		return err
+	}
+	if e.Instructions != "" {
+		fmt.Fprintf(out, "\nInstructions\n\n%s\n", strings.TrimSpace(e.Instructions))
+	}
+	if e.Declaration != "" {
+		fmt.Fprintf(out, "\nDeclaration\n\n%s\n\n", strings.TrimSpace(e.Declaration))
+		for {
+			fmt.Fprint(out, "Type \"I agree\" to accept the declaration and start the exam: ")
+			agreed, err := readLine()
+			if err != nil {
+				return err
+			}
+			if strings.EqualFold(strings.Join(strings.Fields(agreed), " "), "I agree") {
+				break
+			}
+			fmt.Fprintln(out, "You must accept the declaration to sit the exam.")
+		}
+	}
+
+	for i, test := range e.tests {
+		s := e.Sections[i]
+		fmt.Fprintf(out, "\nSection %v of %v", i+1, len(e.tests))
+		if s.Title != "" {
+			fmt.Fprintf(out, ": %s", s.Title)
+		}
+		fmt.Fprintln(out)
+		if s.Instructions != "" {
+			fmt.Fprintf(out, "\n%s\n\n", strings.TrimSpace(s.Instructions))
+		}
+		fmt.Fprint(out, "Press ENTER to start the section")
+		if _, err = readLine(); err != nil {
+			return err
+		}
+
+		test.Name, test.NoGreeting, test.NoPaste, test.Out = name, true, e.NoPaste, out
+		quiz.WithSharedInput(input)(test)
+		err = test.StartTest(ctx)
+		// Stopping a section with /quit moves on to the next, like running out of time
+		if err != nil && !errors.Is(err, quiz.ErrTimeExpired) && !errors.Is(err, quiz.ErrQuit) {
+			return err
+		}
+	}
+	e.showResults(out, name)
+	return nil
+}
+
+// titlePage writes the exam's title, description and size.
+func (e *Exam) titlePage(out io.Writer) {
+	title := e.Title
+	if title == "" {
+		title = "Exam"
+	}
+	rule := strings.Repeat("=", len(title)+4)
+	fmt.Fprintf(out, "%s\n  %s\n%s\n", rule, title, rule)
+	if e.Description != "" {
+		fmt.Fprintf(out, "\n%s\n", strings.TrimSpace(e.Description))
+	}
+
+	questions, limit, timed := 0, time.Duration(0), true
+	for i, test := range e.tests {
+		questions += test.TotalQuestions
+		limit += e.Sections[i].TimeLimit
+		timed = timed && e.Sections[i].TimeLimit > 0
+	}
+	fmt.Fprintf(out, "\nThere are %v sections with %v questions in all", len(e.tests), questions)
+	if timed {
+		fmt.Fprintf(out, ", and up to %s to answer them", limit)
+	}
+	fmt.Fprintln(out, ".")
+	if e.Pass > 0 {
+		fmt.Fprintf(out, "You need %.0f%% to pass.\n", e.Pass)
+	}
+	fmt.Fprintln(out)
+}
+
+// Score returns the percentage of the questions in every section that
+// were answered correctly.
+func (e *Exam) Score() float64 {
+	correct, total := 0, 0
+	for _, test := range e.tests {
+		correct += test.TotalCorrect
+		total += test.Total()
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(correct) / float64(total) * 100
+}
+
+// Passed reports whether the exam was passed, which it always is without
+// a pass mark.
+func (e *Exam) Passed() bool {
+	return e.Score() >= e.Pass
+}
+
+// showResults writes the score for each section and the whole exam.
+func (e *Exam) showResults(out io.Writer, name string) {
+	fmt.Fprintf(out, "\nResults for %s\n", name)
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"#", "Section", "Correct", "Questions", "Score"})
+	for i, test := range e.tests {
+		score, _ := test.Score()
+		table.Append([]string{strconv.Itoa(i + 1), e.Sections[i].Title, strconv.Itoa(test.TotalCorrect), strconv.Itoa(test.Total()), fmt.Sprintf("%.2f%%", score)})
+	}
+	table.Render()
+
+	fmt.Fprintf(out, "Your score for the exam is %.2f%%", e.Score())
+	switch {
+	case e.Pass <= 0:
+		fmt.Fprintln(out, ".")
+	case e.Passed():
+		fmt.Fprintln(out, ", a pass.")
+	default:
+		fmt.Fprintf(out, ", below the pass mark of %.0f%%.\n", e.Pass)
+	}
+}
@@ -0,0 +1,146 @@
+// Package metrics collects Prometheus metrics for the quiz servers, so
+// operators can monitor a hosted quiz.
+//
+//	quiz_sessions_started_total{server}          counter
+//	quiz_sessions_finished_total{server}         counter
+//	quiz_questions_answered_total{server,result} counter, result is "correct" or "incorrect"
+//	quiz_answer_seconds{server}                  histogram of the time taken to answer a question
+//	quiz_score_percent{server}                   histogram of the scores of participants in finished sessions
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// Metrics are the metrics for the quiz servers.  Each server labels its
+// metrics with its own name, e.g. "ssh" or "telegram".
+type Metrics struct {
+	Addr string //Address /metrics is served on
+
+	registry *prometheus.Registry
+	started  *prometheus.CounterVec
+	finished *prometheus.CounterVec
+	answered *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	score    *prometheus.HistogramVec
+}
+
+// New creates the metrics, to be served on addr.
+func New(addr string) *Metrics {
+	m := &Metrics{
+		Addr:     addr,
+		registry: prometheus.NewRegistry(),
+		started: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "quiz_sessions_started_total",
+			Help: "Number of quiz sessions started.",
+		}, []string{"server"}),
+		finished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "quiz_sessions_finished_total",
+			Help: "Number of quiz sessions finished, including those that ran out of time.",
+		}, []string{"server"}),
+		answered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "quiz_questions_answered_total",
+			Help: "Number of questions answered.",
+		}, []string{"server", "result"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "quiz_answer_seconds",
+			Help:    "Time taken to answer a question.",
+			Buckets: []float64{1, 2, 5, 10, 15, 20, 30, 60, 120},
+		}, []string{"server"}),
+		score: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "quiz_score_percent",
+			Help:    "Scores of finished quiz sessions.",
+			Buckets: prometheus.LinearBuckets(10, 10, 10),
+		}, []string{"server"}),
+	}
+	m.registry.MustRegister(m.started, m.finished, m.answered, m.latency, m.score,
+		collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return m
+}
+
+// ListenAndServe serves the metrics on /metrics.
+func (m *Metrics) ListenAndServe() error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	fmt.Printf("Metrics are available at http://%s/metrics\n", m.Addr)
+	return http.ListenAndServe(m.Addr, mux)
+}
+
+// Handler returns the handler that serves the metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// SessionStarted counts a session starting on server.
+func (m *Metrics) SessionStarted(server string) {
+	m.started.WithLabelValues(server).Inc()
+}
+
+// QuestionAnswered counts an answer and records how long it took.
+func (m *Metrics) QuestionAnswered(server string, correct bool, took time.Duration) {
+	result := "incorrect"
+	if correct {
+		result = "correct"
+	}
+	m.answered.WithLabelValues(server, result).Inc()
+	m.latency.WithLabelValues(server).Observe(took.Seconds())
+}
+
+// SessionFinished counts a session finishing.
+func (m *Metrics) SessionFinished(server string) {
+	m.finished.WithLabelValues(server).Inc()
+}
+
+// Scored records a participant's percentage score at the end of a session.
+func (m *Metrics) Scored(server string, score float64) {
+	m.score.WithLabelValues(server).Observe(score)
+}
+
+// Watch records the metrics for a session from the Progress lines reported
+// by the quiz until r is closed.  An answer is timed from when its
+// question was asked until the next Progress shows it answered.
+func (m *Metrics) Watch(server string, r io.Reader) {
+	var last quiz.Progress
+	var asked time.Time
+	started, finished := false, false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var p quiz.Progress
+		if json.Unmarshal(scanner.Bytes(), &p) != nil {
+			continue
+		}
+		now := time.Now()
+		if !started {
+			m.SessionStarted(server)
+			started = true
+		}
+
+		// Each question asked, or the end of the test, follows at most one answer
+		if p.Correct > last.Correct {
+			m.QuestionAnswered(server, true, now.Sub(asked))
+		} else if p.Incorrect > last.Incorrect {
+			m.QuestionAnswered(server, false, now.Sub(asked))
+		}
+		if p.Finished && !finished {
+			finished = true
+			score := float64(0)
+			if p.Total > 0 {
+				score = float64(p.Correct) / float64(p.Total) * 100
+			}
+			m.SessionFinished(server)
+			m.Scored(server, score)
+		}
+		last, asked = p, now
+	}
+}
@@ -0,0 +1,191 @@
+// Package observer is a live view of quiz sessions for an instructor.
+package observer
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// expireAfter is how long a session stays in the view after it ends.
+const expireAfter = time.Hour
+
+// View collects the progress of every ssh session and serves a live
+// view of it for an instructor.  Sessions that ended more than
+// expireAfter ago are dropped, so a server left running doesn't
+// collect them forever.
+//
+//	GET /          HTML page that refreshes every couple of seconds
+//	GET /sessions  the same data as JSON
+type View struct {
+	Addr  string //Address the observer view listens on
+	Token string //When set the token must be passed as ?token= to see the view
+
+	mu       sync.Mutex
+	nextID   int
+	sessions map[int]*Session
+}
+
+// Session is the latest Progress of one ssh session.
+type Session struct {
+	ID        int       `json:"id"`
+	Remote    string    `json:"remote"`    //Address the participant connected from
+	Connected time.Time `json:"connected"` //When the participant connected
+	Elapsed   float64   `json:"elapsed"`   //Seconds since the test started
+	Ended     time.Time `json:"-"`         //When the session ended
+	Active    bool      `json:"active"`    //Whether the participant is still connected
+	quiz.Progress
+}
+
+// New creates a View listening on addr.
+func New(addr string, token string) *View {
+	return &View{Addr: addr, Token: token, sessions: make(map[int]*Session)}
+}
+
+// ListenAndServe serves the observer view.
+func (o *View) ListenAndServe() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", o.handleIndex)
+	mux.HandleFunc("/sessions", o.handleSessions)
+	return http.ListenAndServe(o.Addr, mux)
+}
+
+// Watch tracks a session by reading the Progress lines reported by the quiz
+// until r is closed.  It is called from the ssh server for each session.
+func (o *View) Watch(remote string, r io.Reader) {
+	o.mu.Lock()
+	o.prune()
+	o.nextID++
+	s := &Session{ID: o.nextID, Remote: remote, Connected: time.Now(), Active: true}
+	o.sessions[s.ID] = s
+	o.mu.Unlock()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var p quiz.Progress
+		if json.Unmarshal(scanner.Bytes(), &p) != nil {
+			continue
+		}
+		o.mu.Lock()
+		s.Progress = p
+		if p.Finished {
+			s.Ended = time.Now()
+		}
+		o.mu.Unlock()
+	}
+
+	o.mu.Lock()
+	s.Active = false
+	if s.Ended.IsZero() {
+		s.Ended = time.Now()
+	}
+	o.mu.Unlock()
+}
+
+// prune drops the sessions that ended more than expireAfter ago.
+// o.mu must be locked.
+func (o *View) prune() {
+	for id, s := range o.sessions {
+		if !s.Ended.IsZero() && time.Since(s.Ended) > expireAfter {
+			delete(o.sessions, id)
+		}
+	}
+}
+
+// Sessions returns a copy of every session, newest first.
+func (o *View) Sessions() []Session {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.prune()
+	sessions := []Session{}
+	for _, s := range o.sessions {
+		v := *s
+		if !v.Started.IsZero() {
+			end := time.Now()
+			if !v.Ended.IsZero() {
+				end = v.Ended
+			}
+			v.Elapsed = end.Sub(v.Started).Seconds()
+		}
+		sessions = append(sessions, v)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID > sessions[j].ID })
+	return sessions
+}
+
+func (o *View) authorized(w http.ResponseWriter, r *http.Request) bool {
+	if o.Token != "" && subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(o.Token)) != 1 {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func (o *View) handleSessions(w http.ResponseWriter, r *http.Request) {
+	if !o.authorized(w, r) {
+		return
+	}
+	writeJSON(w, o.Sessions())
+}
+
+var observerPage = template.Must(template.New("observer").Funcs(template.FuncMap{
+	"status": func(s Session) string {
+		switch {
+		case s.Finished:
+			return "finished"
+		case !s.Active:
+			return "disconnected"
+		case s.Question == 0:
+			return "waiting to start"
+		}
+		return fmt.Sprintf("question %v of %v", s.Question, s.Total)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="2">
+<title>Quiz sessions</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+</style>
+</head>
+<body>
+<h1>Quiz sessions</h1>
+<table>
+<tr><th>#</th><th>Name</th><th>From</th><th>Status</th><th>Elapsed</th><th>Correct</th><th>Incorrect</th></tr>
+{{range .}}<tr><td>{{.ID}}</td><td>{{.Name}}</td><td>{{.Remote}}</td><td>{{status .}}</td><td>{{printf "%.0fs" .Elapsed}}</td><td>{{.Correct}}</td><td>{{.Incorrect}}</td></tr>
+{{else}}<tr><td colspan="7">Nobody has connected yet.</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func (o *View) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if !o.authorized(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	observerPage.Execute(w, o.Sessions())
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,96 @@
+package observer
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// progress returns p as a line of JSON, as the quiz reports it.
+func progress(t *testing.T, p quiz.Progress) string {
+	t.Helper()
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data) + "\n"
+}
+
+func TestWatch(t *testing.T) {
+	o := New("", "")
+	lines := progress(t, quiz.Progress{Name: "Ann", Question: 1, Total: 3, Started: time.Now()}) +
+		"not progress\n" +
+		progress(t, quiz.Progress{Name: "Ann", Question: 1, Total: 3, Correct: 1, Started: time.Now()})
+	o.Watch("10.0.0.1:1234", strings.NewReader(lines))
+	o.Watch("10.0.0.2:1234", strings.NewReader(progress(t, quiz.Progress{Name: "Bob", Question: 3, Total: 3, Correct: 2, Incorrect: 1, Finished: true})))
+
+	sessions := o.Sessions()
+	if len(sessions) != 2 {
+		t.Fatalf("Sessions() = %+v, want 2 sessions", sessions)
+	}
+	bob, ann := sessions[0], sessions[1]
+	if bob.Name != "Bob" || !bob.Finished || bob.Active {
+		t.Errorf("the newest session is %+v, want Bob's finished one", bob)
+	}
+	if ann.Name != "Ann" || ann.Correct != 1 || ann.Finished || ann.Active || ann.Ended.IsZero() {
+		t.Errorf("the oldest session is %+v, want Ann's answer to the first question and disconnected", ann)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	o := New("", "")
+	o.Watch("10.0.0.1:1234", strings.NewReader(progress(t, quiz.Progress{Name: "Ann", Finished: true})))
+	o.Watch("10.0.0.2:1234", strings.NewReader(progress(t, quiz.Progress{Name: "Bob", Finished: true})))
+
+	// Ann's session ended long ago, and Cat is still connected
+	o.mu.Lock()
+	o.sessions[1].Ended = time.Now().Add(-expireAfter - time.Minute)
+	o.mu.Unlock()
+	r, w := io.Pipe()
+	defer w.Close()
+	go o.Watch("10.0.0.3:1234", r)
+	io.WriteString(w, progress(t, quiz.Progress{Name: "Cat", Question: 1}))
+
+	var ids []int
+	for _, s := range o.Sessions() {
+		ids = append(ids, s.ID)
+	}
+	if !slices.Equal(ids, []int{3, 2}) {
+		t.Errorf("the sessions are %v, want Cat's and Bob's, 3 and 2", ids)
+	}
+}
+
+func TestToken(t *testing.T) {
+	o := New("", "secret")
+	srv := httptest.NewServer(http.HandlerFunc(o.handleSessions))
+	defer srv.Close()
+
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{name: "no token", want: http.StatusForbidden},
+		{name: "wrong token", query: "?token=secre", want: http.StatusForbidden},
+		{name: "token", query: "?token=secret", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(srv.URL + tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status %v, want %v", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,689 @@
+// Package quiz is the engine for the quiz game.  An Assessment holds the
+// questions for a test, administers it in the terminal and keeps the score.
+package quiz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// Assessment tracks the content and results of the test.
+type Assessment struct {
+	Questions        []Question     //slice of Question stuct
+	TotalCorrect     int            //Number of Questions answered correctly
+	TotalIncorrect   int            //number of Questions answered incorrectly[]
+	TotalQuestions   int            //Total number of Questions in Assessment, 0 for no limit with a Source
+	FilePath         string         //Filepath to file contaning questions
+	Shuffle          bool           //Should the questions be randomized / shuffled
+	Seed             int64          //Seed for shuffling so the order can be repeated, 0 for a different order every time
+	TimeLimit        time.Duration  //The amount of time the user has to complete the test, 0 for no limit
+	QuestionLimit    time.Duration  //The amount of time the user has to answer each question, 0 for no limit
+	WarnBefore       time.Duration  //How long before the test's or a question's time runs out the user is warned, 0 for no warning
+	Bell             bool           //Ring the terminal bell with the warning that the time is nearly up
+	Lives            int            //Number of wrong answers that end the test, 0 for no limit
+	MaxPerCategory   int            //Most questions loaded from any one category, 0 for no limit
+	OnDuplicate      string         //What to do with loaded questions that repeat an earlier one, one of DuplicatePolicies, empty to keep them
+	Language         string         //Language code of the translations the loaded questions are asked in, empty for the language they are written in
+	Strata           StrataFunc     //Groups the questions so the TotalQuestions loaded cover every group, nil to load the first TotalQuestions
+	Quotas           map[string]int //Number of questions to load from each group of Strata, nil to load them in proportion to the groups
+	TimeStart        time.Time      //Start time for the Assessment
+	Name             string         //Name of the user taking the Quiz
+	NoGreeting       bool           //Start without the welcome, asking for the Name or waiting for ENTER
+	NoPaste          bool           //Refuse answers pasted into a terminal with bracketed paste, so they must be typed
+	SavePath         string         //File SaveCommand saves the test in to finish later, empty if it can't be saved
+	Width            int            //Columns of text the score table is fitted to, 0 for the width of the terminal
+	FullText         bool           //Cut long text in the score table short and show it in full below, instead of wrapping it
+	LeaderboardURL   string         //URL of the leaderboard server scores are pushed to
+	LeaderboardToken string         //Token the leaderboard server needs to accept scores, empty if it doesn't need one
+	LeaderboardTop   int            //Number of top scores to show from the leaderboard
+	Source           QuestionSource //Where questions come from as they are asked. When nil the loaded Questions are asked
+	Grader           Grader         //Decides whether answers are correct. When nil answers must match exactly
+	Scorer           Scorer         //Calculates the percentage score. When nil it is the percentage answered correctly
+	Transcriber      Transcriber    //Turns a spoken answer into text. When set an empty answer records one
+	In               io.Reader      //Where the user's input is read from, os.Stdin if nil
+	Out              io.Writer      //Where the test is written to, os.Stdout if nil
+
+	input       *Input        //Lines of the user's input, read from In
+	sharedInput bool          //Whether input is shared with other tests, which close it
+	hooks       hooks         //Handlers for the events in the test
+	rand        *rand.Rand    //Random source for this test, so tests don't share the global one
+	elapsed     time.Duration //Time taken before the test was saved, for a restored test
+
+	mu            sync.Mutex //Guards the clocks, which Pause and Resume can reach from other goroutines
+	paused        bool       //Whether the test is paused
+	testClock     *clock     //Time limit for the test, nil before it starts
+	questionClock *clock     //Time limit for the current question, nil without a QuestionLimit
+}
+
+// userInput returns the user's input, read from In.
+func (a *Assessment) userInput() *Input {
+	if a.input == nil {
+		if a.In == nil {
+			a.In = os.Stdin
+		}
+		a.input = NewInput(a.In)
+	}
+	return a.input
+}
+
+// closeInput stops reading the input once the test is over, unless other
+// tests share it.
+func (a *Assessment) closeInput() {
+	if a.input != nil && !a.sharedInput {
+		a.input.Close()
+	}
+}
+
+// output returns the writer the test is written to.
+func (a *Assessment) output() io.Writer {
+	if a.Out == nil {
+		a.Out = os.Stdout
+	}
+	return a.Out
+}
+
+// random returns the random source for the test, seeded with Seed if it is set.
+func (a *Assessment) random() *rand.Rand {
+	if a.rand == nil {
+		seed := a.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		a.rand = rand.New(rand.NewSource(seed))
+	}
+	return a.rand
+}
+
+// NewSession returns a copy of the test for another user to take, reading
+// from in and writing to out.  The copy has all of the test's settings, but
+// not its Name, and its own questions, score, hooks and random source, so
+// many sessions can run at once from one loaded test.
+// Questions are reshuffled for each session when Shuffle is set, in the
+// same order for every session if Seed is set.
+// Source, Grader, Scorer and Transcriber are shared, so they must be safe for concurrent use.
+func (a *Assessment) NewSession(in io.Reader, out io.Writer) *Assessment {
+	s := &Assessment{
+		Questions:        make([]Question, len(a.Questions)),
+		TotalQuestions:   a.TotalQuestions,
+		FilePath:         a.FilePath,
+		Shuffle:          a.Shuffle,
+		Seed:             a.Seed,
+		TimeLimit:        a.TimeLimit,
+		QuestionLimit:    a.QuestionLimit,
+		WarnBefore:       a.WarnBefore,
+		Bell:             a.Bell,
+		Lives:            a.Lives,
+		MaxPerCategory:   a.MaxPerCategory,
+		OnDuplicate:      a.OnDuplicate,
+		Language:         a.Language,
+		Strata:           a.Strata,
+		Quotas:           a.Quotas,
+		NoGreeting:       a.NoGreeting,
+		NoPaste:          a.NoPaste,
+		SavePath:         a.SavePath,
+		Width:            a.Width,
+		FullText:         a.FullText,
+		LeaderboardURL:   a.LeaderboardURL,
+		LeaderboardToken: a.LeaderboardToken,
+		LeaderboardTop:   a.LeaderboardTop,
+		Source:           a.Source,
+		Grader:           a.Grader,
+		Scorer:           a.Scorer,
+		Transcriber:      a.Transcriber,
+		In:               in,
+		Out:              out,
+		hooks:            a.hooks.clone(),
+	}
+	for i, q := range a.Questions {
+		s.Questions[i] = q.unanswered()
+	}
+	s.ShuffleQuestions()
+	return s
+}
+
+// Loader reads the questions from a source, such as a file path or URL.
+// When some of the questions can't be read Load may return the ones that
+// could along with the error.
+type Loader interface {
+	Load(source string) ([]Question, error)
+}
+
+// LoaderFunc lets an ordinary function be used as a Loader.
+type LoaderFunc func(source string) ([]Question, error)
+
+// Load calls f(source).
+func (f LoaderFunc) Load(source string) ([]Question, error) {
+	return f(source)
+}
+
+// Scanner is a Loader that can also read a source one question at a time,
+// so the questions needed from a large file can be taken without holding
+// the rest of it.  Scan calls yield with each question in order until yield
+// returns false, and like Load it may return an error for the questions
+// that couldn't be read.
+type Scanner interface {
+	Loader
+	Scan(source string, yield func(q Question) bool) error
+}
+
+// ScannerFunc lets an ordinary function be used as a Scanner.
+type ScannerFunc func(source string, yield func(q Question) bool) error
+
+// Scan calls f(source, yield).
+func (f ScannerFunc) Scan(source string, yield func(q Question) bool) error {
+	return f(source, yield)
+}
+
+// Load returns every question f scans from source.
+func (f ScannerFunc) Load(source string) ([]Question, error) {
+	var questions []Question
+	err := f(source, func(q Question) bool {
+		questions = append(questions, q)
+		return true
+	})
+	return questions, err
+}
+
+// Exporter writes questions to a file in its format.
+type Exporter interface {
+	Export(path string, questions []Question) error
+}
+
+// ExporterFunc lets an ordinary function be used as an Exporter.
+type ExporterFunc func(path string, questions []Question) error
+
+// Export calls f(path, questions).
+func (f ExporterFunc) Export(path string, questions []Question) error {
+	return f(path, questions)
+}
+
+// Grader decides whether answer is the correct answer to q.  It should
+// give up when ctx is done, which StartTest does when the time for the
+// question or the test runs out while the answer is being graded.
+type Grader func(ctx context.Context, q *Question, answer string) (bool, error)
+
+// Scorer calculates the percentage score for the test.
+type Scorer func(a *Assessment) (float64, error)
+
+// Transcriber records a spoken answer and returns what was said.  It
+// should stop recording when ctx is done.
+type Transcriber func(ctx context.Context) (string, error)
+
+// ShuffleQuestions will shuffle the questions in the Questions slice of the Assessment struct.
+// This function is called from LoadQuestions.
+func (a *Assessment) ShuffleQuestions() {
+	if len(a.Questions) == 0 || !a.Shuffle { //if there are no questions don't do anything
+		return
+	}
+
+	a.random().Shuffle(len(a.Questions), func(i, j int) { a.Questions[i], a.Questions[j] = a.Questions[j], a.Questions[i] })
+}
+
+// LoadQuestions loads the questions in FilePath using the loader l.
+// Only the first TotalQuestions questions are kept and they are shuffled if needed.
+// With Strata the questions are sampled at random instead, in proportion to
+// the groups or by the Quotas for them, see Sample and SampleQuotas.
+// Questions that repeat an earlier one are handled as OnDuplicate says, see
+// HandleDuplicates, and MaxPerCategory caps the questions from each
+// category, before they are picked.
+// Any templates in the questions are expanded, see Question.ExpandTemplates.
+// it returns an error wrapping ErrLoadFailed if loading fails, ErrNoQuestions
+// if there aren't any questions, or ctx's error if ctx is done.
+func (a *Assessment) LoadQuestions(ctx context.Context, l Loader) (err error) {
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+
+	// Only the first TotalQuestions are needed unless they are sampled
+	var questions []Question
+	if s, ok := l.(Scanner); ok && a.TotalQuestions > 0 && a.Strata == nil {
+		questions, err = a.scanQuestions(s)
+	} else {
+		questions, err = a.loadQuestions(l)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrLoadFailed, err)
+	}
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	if len(questions) == 0 {
+		return fmt.Errorf("%w in %s", ErrNoQuestions, a.FilePath)
+	}
+
+	switch {
+	case a.Strata != nil && a.Quotas != nil:
+		if a.Questions, err = SampleQuotas(questions, a.Quotas, a.Strata, a.random()); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrLoadFailed, a.FilePath, err)
+		}
+		a.TotalQuestions = len(a.Questions)
+	case a.Strata != nil:
+		a.Questions = Sample(questions, a.TotalQuestions, a.Strata, a.random())
+		a.TotalQuestions = len(a.Questions)
+	default:
+		if a.TotalQuestions > len(questions) || a.TotalQuestions == 0 {
+			a.TotalQuestions = len(questions)
+		}
+		a.Questions = questions[:a.TotalQuestions]
+	}
+
+	// Shuffle the questions if needed
+	a.ShuffleQuestions()
+
+	// Templates get their values from the test's random source, so a Seed
+	// gives the same values every time
+	a.translate()
+	for i := range a.Questions {
+		q := &a.Questions[i]
+		if err = q.ExpandTemplates(a.random()); err != nil {
+			return fmt.Errorf("%w: %s:%d: %w", ErrLoadFailed, a.FilePath, q.Line, err)
+		}
+	}
+	return nil
+}
+
+// loadQuestions loads all the questions in FilePath with l, handling the
+// duplicates and capping the categories.
+func (a *Assessment) loadQuestions(l Loader) ([]Question, error) {
+	questions, err := l.Load(a.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if questions, err = HandleDuplicates(questions, a.FilePath, make(map[string]string), a.OnDuplicate, a.output()); err != nil {
+		return nil, err
+	}
+	if a.MaxPerCategory > 0 {
+		questions = CapPerCategory(questions, a.MaxPerCategory)
+	}
+	return questions, nil
+}
+
+// scanQuestions is like loadQuestions, but stops reading FilePath once it
+// has TotalQuestions questions.  Problems later in the file aren't found.
+func (a *Assessment) scanQuestions(s Scanner) (questions []Question, err error) {
+	seen := make(map[string]string)
+	keep := capCategories(a.MaxPerCategory)
+	serr := s.Scan(a.FilePath, func(q Question) bool {
+		kept, derr := HandleDuplicates([]Question{q}, a.FilePath, seen, a.OnDuplicate, a.output())
+		if derr != nil {
+			err = derr
+			return false
+		}
+		for _, q := range kept {
+			if keep(q) {
+				questions = append(questions, q)
+			}
+		}
+		return len(questions) < a.TotalQuestions
+	})
+	if err != nil {
+		return nil, err
+	}
+	if serr != nil {
+		return nil, serr
+	}
+	return questions, nil
+}
+
+// GreetUser welcomes the user and asks for their name, unless Name is
+// already set.
+func (a *Assessment) GreetUser(ctx context.Context) (err error) {
+	out := a.output()
+	if a.Name != "" {
+		fmt.Fprintf(out, "Welcome to the Quiz Game, %s\n", a.Name)
+		return nil
+	}
+	fmt.Fprintln(out, "Welcome to the Quiz Game")
+	fmt.Fprintf(out, "Please enter your name: ")
+	a.Name, err = a.readInput(ctx)
+
+	a.Name = strings.TrimSpace(a.Name)
+
+	if err != nil {
+		fmt.Fprintln(out, "Error occurred:", err)
+		return err
+	}
+	return nil
+}
+
+// StartTest administers the test by looping through the questions in the Questions slice
+// and setting the properties on the Assessment struct.
+// it also runs the timer for the test, and for each question if there is a QuestionLimit.
+// A question that runs out of time is marked wrong and the test moves on.
+// Answering BookmarkCommand bookmarks the question and asks for the answer again,
+// and the other commands, such as QuitCommand and SaveCommand, are handled the same way.
+// QuitCommand shows the score so far and returns ErrQuit.  SaveCommand saves the test
+// to SavePath and returns ErrSaved, without showing the score or calling the OnFinished handlers.
+// With Lives set the test ends once that many questions are wrong.
+// If the time limit for the test runs out the score is shown and ErrTimeExpired is returned.
+// If ctx is cancelled during the test the score so far is shown and ctx's error is returned.
+// An Assessment runs one test; use NewSession to run it again.
+func (a *Assessment) StartTest(ctx context.Context) (err error) {
+
+	out := a.output()
+	if a.Source == nil && len(a.Questions) == 0 {
+		return ErrNoQuestions
+	}
+	defer a.closeInput()
+
+	if !a.NoGreeting {
+		err = a.GreetUser(ctx)
+		if err != nil {
+			fmt.Fprintln(out, "Error occurred:", err)
+			return err
+		}
+	}
+
+	switch {
+	case a.Source != nil && a.TotalQuestions == 0 && a.TimeLimit == 0:
+		fmt.Fprintln(out, "There is no time limit. Press Ctrl+C when you want to stop.")
+	case a.Source != nil && a.TotalQuestions == 0:
+		fmt.Fprintf(out, "You have %s to answer as many questions as you can.\n", a.TimeLimit)
+	case a.TimeLimit == 0:
+		fmt.Fprintf(out, "There is no time limit. There are %v questions in the test.\n", a.TotalQuestions)
+	default:
+		fmt.Fprintf(out, "You have %s to finish the test. There are %v questions in the test.\n", a.TimeLimit, a.TotalQuestions)
+	}
+	if a.QuestionLimit > 0 {
+		fmt.Fprintf(out, "You have %s to answer each question.\n", a.QuestionLimit)
+	}
+	switch {
+	case a.Lives == 1:
+		fmt.Fprintln(out, "The test ends at your first wrong answer.")
+	case a.Lives > 1:
+		fmt.Fprintf(out, "You have %v lives. The test ends when you have got %v questions wrong.\n", a.Lives, a.Lives)
+	}
+	fmt.Fprintf(out, "Answer %s to bookmark a question to study later, or %s for the other commands.\n", BookmarkCommand, HelpCommand)
+	if !a.NoGreeting {
+		fmt.Fprintf(out, "Press ENTER to start the test")
+		_, err = a.readInput(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			fmt.Fprintln(out, "Error occurred:", err)
+			return err
+		}
+	}
+
+	// A restored test carries on where it was saved
+	a.TimeStart = time.Now().Add(-a.elapsed)
+	if a.TimeLimit > 0 {
+		a.startClock(a.TimeLimit-a.elapsed, false)
+	}
+	defer a.stopClocks()
+	a.emitQuizStart()
+
+	for i := a.TotalCorrect + a.TotalIncorrect; ; i++ {
+		q, err := a.nextQuestion(i)
+		if err != nil {
+			return err
+		}
+		if q == nil {
+			break
+		}
+
+		a.emitQuestionAsked(i+1, q)
+		if limit := a.TimeFor(q); limit > 0 {
+			a.startClock(limit, true)
+		} else {
+			a.stopQuestionClock()
+		}
+		q.Prompt(out, i+1)
+		asked := time.Now()
+		answer, err := a.answer(ctx, q)
+		q.AnswerTime = time.Since(asked)
+		if err == nil {
+			err = a.grade(ctx, q, answer)
+		}
+
+		switch {
+		case ctx.Err() != nil:
+			fmt.Fprintln(out, "")
+			fmt.Fprintf(out, "The test was stopped %s.\n", a.Name)
+			a.ShowScore()
+			a.emitFinished()
+			return ctx.Err()
+		case errors.Is(err, ErrQuit):
+			fmt.Fprintln(out, "")
+			fmt.Fprintf(out, "You stopped the test %s.\n", a.Name)
+			a.ShowScore()
+			a.emitFinished()
+			return ErrQuit
+		case errors.Is(err, ErrSaved):
+			if err = a.Save(a.SavePath); err != nil {
+				fmt.Fprintln(out, "Error occurred:", err)
+				return err
+			}
+			fmt.Fprintf(out, "Saved the test in %s to finish later.\n", a.SavePath)
+			return ErrSaved
+		case errors.Is(err, ErrTimeExpired):
+			a.emitTimeExpired()
+			fmt.Fprintln(out, "")
+			fmt.Fprintf(out, "Time's Up %s!\n", a.Name)
+			a.ShowScore()
+			a.emitFinished()
+			return ErrTimeExpired
+		case errors.Is(err, errQuestionExpired):
+			fmt.Fprintln(out, "")
+			fmt.Fprintln(out, "Out of time for this question.")
+			q.record("")
+		case err != nil:
+			fmt.Fprintln(out, "Error occurred:", err)
+			return err
+		}
+
+		if q.Correct {
+			a.TotalCorrect++
+		} else {
+			a.TotalIncorrect++
+		}
+		a.emitAnswered(i+1, q)
+
+		if a.Lives > 0 && !q.Correct {
+			switch left := a.Lives - a.TotalIncorrect; {
+			case left > 1:
+				fmt.Fprintf(out, "Wrong, the answer was %s. You have %v lives left.\n", q.Answer, left)
+			case left == 1:
+				fmt.Fprintf(out, "Wrong, the answer was %s. You have 1 life left.\n", q.Answer)
+			default:
+				fmt.Fprintf(out, "Wrong, the answer was %s. You are out of lives!\n", q.Answer)
+			}
+			if a.TotalIncorrect >= a.Lives {
+				break
+			}
+		}
+	}
+	a.ShowScore()
+	a.emitFinished()
+
+	return nil
+}
+
+// Terminals in bracketed paste mode start pasted text with pasteStart,
+// and pasteOn and pasteOff turn the mode on and off.
+const (
+	pasteStart = "\x1b[200~"
+	pasteOn    = "\x1b[?2004h"
+	pasteOff   = "\x1b[?2004l"
+)
+
+// answer reads the user's answer to q, handling the commands they give in
+// place of one and, with NoPaste, refusing pasted answers, until they give
+// an answer or a command that ends the test.
+func (a *Assessment) answer(ctx context.Context, q *Question) (string, error) {
+	out := a.output()
+	if a.NoPaste {
+		fmt.Fprint(out, pasteOn)
+		defer fmt.Fprint(out, pasteOff)
+	}
+	for {
+		answer, err := a.readAnswer(ctx)
+		switch {
+		case err != nil:
+			return answer, err
+		case a.NoPaste && strings.Contains(answer, pasteStart):
+			fmt.Fprint(out, "Pasted answers aren't allowed, please type your answer: ")
+		default:
+			if ok, err := a.command(q, answer, out); !ok || err != nil {
+				return answer, err
+			}
+		}
+	}
+}
+
+// Grade records answer as the user's answer to q and decides whether it
+// is correct, with the Grader if there is one, as StartTest does for each
+// answer.  It doesn't count the answer towards the score.
+func (a *Assessment) Grade(ctx context.Context, q *Question, answer string) (err error) {
+	q.record(answer)
+	if a.Grader != nil {
+		q.Correct, err = a.Grader(ctx, q, q.UserAnswer)
+	}
+	return err
+}
+
+// Score returns the percentage score for the test, from the Scorer if
+// there is one or else the percentage of questions answered correctly.
+// When questions have Points the percentage is of the points they are
+// worth, with the questions that weren't asked worth a point each.
+// If the Scorer fails the percentage answered correctly is returned with
+// its error.
+func (a *Assessment) Score() (float64, error) {
+	score := float64(0)
+	if total := a.Total(); total > 0 {
+		score = float64(a.TotalCorrect) / float64(total) * 100
+		if a.hasPoints() {
+			earned, possible := 0.0, float64(max(total-len(a.Questions), 0))
+			for _, q := range a.Questions {
+				possible += q.Worth()
+				if q.Correct {
+					earned += q.Worth()
+				}
+			}
+			score = earned / possible * 100
+		}
+	}
+	if a.Scorer != nil {
+		custom, err := a.Scorer(a)
+		if err != nil {
+			return score, err
+		}
+		score = custom
+	}
+	return score, nil
+}
+
+// hasPoints reports whether any of the questions has Points.
+func (a *Assessment) hasPoints() bool {
+	for _, q := range a.Questions {
+		if q.Points > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ShowScore prints out the results of the test.
+func (a *Assessment) ShowScore() {
+	out := a.output()
+
+	// if the user answered all the questions then tell them
+	// how much time they took to answer the questions and how
+	// much time was left on the clock
+	total := a.Total()
+	if a.TotalCorrect+a.TotalIncorrect == total {
+		Now := time.Now()
+		TestTime := Now.Sub(a.TimeStart)
+		TimeLeft := a.TimeLimit.Seconds() - TestTime.Seconds()
+
+		fmt.Fprintf(out, "You answered all %v questions in %.2f seconds.\n", total, TestTime.Seconds())
+		if a.TimeLimit > 0 {
+			fmt.Fprintf(out, "There were %.2f seconds remaining on the clock.\n", TimeLeft)
+		}
+	} else {
+		taken := time.Since(a.TimeStart)
+		if a.TimeLimit > 0 && taken > a.TimeLimit {
+			taken = a.TimeLimit
+		}
+		fmt.Fprintf(out, "You answered %v questions out of a total of %v questions in %.2f seconds.\n",
+			a.TotalCorrect+a.TotalIncorrect, total, taken.Seconds())
+	}
+	fmt.Fprintf(out, "You got %v questions right and %v questions wrong.\n", a.TotalCorrect, a.TotalIncorrect)
+	score, err := a.Score()
+	if err != nil {
+		fmt.Fprintln(out, "Error occurred:", err)
+	}
+	fmt.Fprintf(out, "Your score is %.2f%% %s! \n", score, a.Name)
+
+	header := []string{"#", "Question", "Answer", "User Answer", "Correct"}
+	rows := make([][]string, len(a.Questions))
+	for i, v := range a.Questions {
+		rows[i] = []string{strconv.FormatInt(int64(i+1), 10), v.QText, v.Answer, v.UserAnswer, strconv.FormatBool(v.Correct)}
+	}
+	// Long questions and answers are fitted to the terminal, so the table
+	// doesn't wrap around it
+	var cut []bool
+	table := tablewriter.NewWriter(out)
+	if width := a.width(); width > 0 {
+		cut = fitTable(header, rows, []int{1, 2, 3}, width, a.FullText)
+		table.SetAutoWrapText(false)
+	}
+	table.SetHeader(header)
+	table.AppendBulk(rows)
+
+	table.Render() // Send output
+
+	if slices.Contains(cut, true) {
+		fmt.Fprintln(out, "In full:")
+		for i, v := range a.Questions {
+			if cut[i] {
+				fmt.Fprintf(out, "  %v. %s = %s, answered %q\n", i+1, v.QText, v.Answer, v.UserAnswer)
+			}
+		}
+	}
+
+	explained := false
+	for _, q := range a.Questions {
+		if q.Explanation == "" || q.Correct || q.UserAnswer == "" && q.AnswerTime == 0 {
+			continue
+		}
+		if !explained {
+			fmt.Fprintln(out, "Why the answers are right:")
+			explained = true
+		}
+		fmt.Fprintf(out, "  %s = %s: %s\n", q.QText, q.Answer, q.Explanation)
+	}
+
+	if bookmarks := a.Bookmarks(); len(bookmarks) > 0 {
+		fmt.Fprintln(out, "Bookmarked to study later:")
+		for _, q := range bookmarks {
+			fmt.Fprintf(out, "  %s = %s\n", q.QText, q.Answer)
+		}
+	}
+}
+
+// Bookmarks returns the questions the user bookmarked, each once, whether
+// they were answered correctly or not.
+func (a *Assessment) Bookmarks() []Question {
+	var bookmarks []Question
+	seen := make(map[string]bool)
+	for _, q := range a.Questions {
+		if id := q.StableID(); q.Bookmarked && !seen[id] {
+			seen[id] = true
+			bookmarks = append(bookmarks, q)
+		}
+	}
+	return bookmarks
+}
@@ -0,0 +1,304 @@
+package quiz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// benchQuestions returns n arithmetic questions in 10 categories.
+func benchQuestions(n int) []Question {
+	questions := make([]Question, n)
+	for i := range questions {
+		questions[i] = Question{
+			QText:    fmt.Sprintf("%d+%d", i, i+1),
+			Answer:   fmt.Sprint(2*i + 1),
+			Category: fmt.Sprint("category ", i%10),
+			Line:     i + 1,
+		}
+	}
+	return questions
+}
+
+// texts returns the text of each question, in order.
+func texts(questions []Question) []string {
+	var t []string
+	for _, q := range questions {
+		t = append(t, q.QText)
+	}
+	return t
+}
+
+func TestShuffleQuestions(t *testing.T) {
+	tests := []struct {
+		name    string
+		shuffle bool
+		seed    int64
+	}{
+		{name: "not shuffled"},
+		{name: "seeded", shuffle: true, seed: 1},
+		{name: "another seed", shuffle: true, seed: 2},
+	}
+	want := texts(benchQuestions(50))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shuffle := func() []string {
+				a := &Assessment{Questions: benchQuestions(50), Shuffle: tt.shuffle, Seed: tt.seed}
+				a.ShuffleQuestions()
+				return texts(a.Questions)
+			}
+			got := shuffle()
+			if !tt.shuffle {
+				if !slices.Equal(got, want) {
+					t.Errorf("the questions were reordered without Shuffle: %q", got)
+				}
+				return
+			}
+			if slices.Equal(got, want) {
+				t.Error("the questions weren't shuffled")
+			}
+			if !slices.Equal(slices.Sorted(slices.Values(got)), slices.Sorted(slices.Values(want))) {
+				t.Errorf("shuffling changed the questions: %q", got)
+			}
+			if again := shuffle(); !slices.Equal(got, again) {
+				t.Errorf("the same seed gave the orders %q and %q", got, again)
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	errScorer := errors.New("scorer failed")
+	tests := []struct {
+		name           string
+		questions      []Question
+		totalQuestions int
+		scorer         Scorer
+		want           float64
+		wantErr        error
+	}{
+		{name: "no questions", want: 0},
+		{name: "all correct", questions: []Question{{Correct: true}, {Correct: true}}, want: 100},
+		{name: "half correct", questions: []Question{{Correct: true}, {}}, want: 50},
+		{name: "questions not asked", questions: []Question{{Correct: true}}, totalQuestions: 4, want: 25},
+		{name: "points", questions: []Question{{Correct: true, Points: 3}, {Points: 1}}, want: 75},
+		{name: "points and questions not asked", questions: []Question{{Correct: true, Points: 3}, {Points: 1}}, totalQuestions: 4, want: 50},
+		{name: "scorer", questions: []Question{{Correct: true}, {}}, scorer: func(*Assessment) (float64, error) { return 90, nil }, want: 90},
+		{name: "scorer fails", questions: []Question{{Correct: true}, {}}, scorer: func(*Assessment) (float64, error) { return 0, errScorer }, want: 50, wantErr: errScorer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Assessment{Questions: tt.questions, TotalQuestions: tt.totalQuestions, Scorer: tt.scorer}
+			for _, q := range tt.questions {
+				if q.Correct {
+					a.TotalCorrect++
+				} else {
+					a.TotalIncorrect++
+				}
+			}
+			got, err := a.Score()
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Score() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+// arithmetic is a test of three questions with no time limit.
+var arithmetic = &Assessment{
+	Questions:      []Question{{QText: "1+1", Answer: "2"}, {QText: "2+2", Answer: "4"}, {QText: "3+3", Answer: "6"}},
+	TotalQuestions: 3,
+}
+
+func TestStartTest(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		lives         int
+		wantErr       error
+		wantCorrect   int
+		wantIncorrect int
+		wantOut       []string //Text the output should contain
+	}{
+		{name: "all correct", input: "Alice\n\n2\n4\n6\n", wantCorrect: 3, wantOut: []string{"Welcome to the Quiz Game", "Alice", "You got 3 questions right and 0 questions wrong.", "100.00%"}},
+		{name: "some wrong", input: "Bob\n\n2\n5\n 6 \n", wantCorrect: 2, wantIncorrect: 1, wantOut: []string{"You got 2 questions right and 1 questions wrong."}},
+		{name: "last line without a newline", input: "Carol\n\n2\n4\n6", wantCorrect: 3},
+		{name: "commands aren't answers", input: "Dan\n\n/help\n2\n/time\n4\n6\n", wantCorrect: 3, wantOut: []string{QuitCommand}},
+		{name: "quit", input: "Erin\n\n2\n/quit\n", wantErr: ErrQuit, wantCorrect: 1, wantOut: []string{"You stopped the test Erin."}},
+		{name: "out of lives", input: "Frank\n\n1\n3\n", lives: 2, wantIncorrect: 2, wantOut: []string{"You are out of lives!"}},
+		{name: "input ends", input: "Grace\n\n2\n", wantErr: io.EOF, wantCorrect: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			s := arithmetic.NewSession(strings.NewReader(tt.input), &out)
+			s.Lives = tt.lives
+			err := s.StartTest(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("StartTest() = %v, want %v", err, tt.wantErr)
+			}
+			if s.TotalCorrect != tt.wantCorrect || s.TotalIncorrect != tt.wantIncorrect {
+				t.Errorf("%v right and %v wrong, want %v and %v", s.TotalCorrect, s.TotalIncorrect, tt.wantCorrect, tt.wantIncorrect)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("the output doesn't have %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+	if arithmetic.TotalCorrect != 0 || arithmetic.Questions[0].UserAnswer != "" {
+		t.Error("a session changed the test it was made from")
+	}
+}
+
+func TestStartTestTimeLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		answers       string
+		timeLimit     time.Duration
+		questionLimit time.Duration
+		grader        Grader
+		wantErr       error
+		wantCorrect   int
+		wantIncorrect int
+		wantOut       string
+	}{
+		{name: "test runs out", answers: "2\n", timeLimit: 50 * time.Millisecond, wantErr: ErrTimeExpired, wantCorrect: 1, wantOut: "Time's Up Alice!"},
+		{name: "questions run out", answers: "2\n", questionLimit: 20 * time.Millisecond, wantCorrect: 1, wantIncorrect: 2, wantOut: "Out of time for this question."},
+		{name: "grader is stopped", answers: "2\n4\n6\n", questionLimit: 20 * time.Millisecond, grader: slowGrader, wantIncorrect: 3, wantOut: "Out of time for this question."},
+		{name: "test runs out while grading", answers: "2\n", timeLimit: 50 * time.Millisecond, grader: slowGrader, wantErr: ErrTimeExpired, wantOut: "Time's Up Alice!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The user gives the answers and then stops typing
+			in, typing := io.Pipe()
+			defer typing.Close()
+			go io.WriteString(typing, tt.answers)
+
+			var out bytes.Buffer
+			s := arithmetic.NewSession(in, &out)
+			s.Name, s.NoGreeting = "Alice", true
+			s.TimeLimit, s.QuestionLimit, s.Grader = tt.timeLimit, tt.questionLimit, tt.grader
+			done := make(chan error)
+			go func() { done <- s.StartTest(context.Background()) }()
+			select {
+			case err := <-done:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("StartTest() = %v, want %v", err, tt.wantErr)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("the test didn't end when the time ran out")
+			}
+			if s.TotalCorrect != tt.wantCorrect || s.TotalIncorrect != tt.wantIncorrect {
+				t.Errorf("%v right and %v wrong, want %v and %v", s.TotalCorrect, s.TotalIncorrect, tt.wantCorrect, tt.wantIncorrect)
+			}
+			if !strings.Contains(out.String(), tt.wantOut) {
+				t.Errorf("the output doesn't have %q:\n%s", tt.wantOut, out.String())
+			}
+		})
+	}
+}
+
+// slowGrader takes until it is stopped to grade an answer.
+func slowGrader(ctx context.Context, q *Question, answer string) (bool, error) {
+	<-ctx.Done()
+	return true, ctx.Err()
+}
+
+func TestStartTestCancelled(t *testing.T) {
+	in, typing := io.Pipe()
+	defer typing.Close()
+	s := arithmetic.NewSession(in, io.Discard)
+	s.Name, s.NoGreeting = "Alice", true
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.StartTest(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("StartTest() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func BenchmarkShuffleQuestions(b *testing.B) {
+	a := &Assessment{Questions: benchQuestions(10000), Shuffle: true, Seed: 1}
+	for b.Loop() {
+		a.ShuffleQuestions()
+	}
+}
+
+func BenchmarkSample(b *testing.B) {
+	questions := benchQuestions(10000)
+	r := rand.New(rand.NewSource(1))
+	strata := func(q Question) string { return q.Category }
+	for b.Loop() {
+		Sample(questions, 100, strata, r)
+	}
+}
+
+func BenchmarkReservoir(b *testing.B) {
+	questions := benchQuestions(10000)
+	r := rand.New(rand.NewSource(1))
+	for b.Loop() {
+		s := NewReservoir(100, r)
+		for _, q := range questions {
+			s.Add(q)
+		}
+		s.Sample()
+	}
+}
+
+func BenchmarkHandleDuplicates(b *testing.B) {
+	questions := benchQuestions(10000)
+	for b.Loop() {
+		if _, err := HandleDuplicates(questions, "bench.csv", make(map[string]string), DuplicatesWarn, io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGrade grades an answer to every question, half of them wrong,
+// and works out the score.
+func BenchmarkGrade(b *testing.B) {
+	a := &Assessment{Questions: benchQuestions(10000)}
+	for b.Loop() {
+		a.TotalCorrect, a.TotalIncorrect = 0, 0
+		for i := range a.Questions {
+			q := &a.Questions[i]
+			answer := q.Answer
+			if i%2 == 1 {
+				answer = "wrong"
+			}
+			if q.record(" " + answer + " "); q.Correct {
+				a.TotalCorrect++
+			} else {
+				a.TotalIncorrect++
+			}
+		}
+		if _, err := a.Score(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkStartTest runs a whole test of 100 questions, answered from a
+// reader, to measure the cost of asking and grading each one.
+func BenchmarkStartTest(b *testing.B) {
+	test := &Assessment{Questions: benchQuestions(100), TotalQuestions: 100}
+	var answers strings.Builder
+	for _, q := range test.Questions {
+		fmt.Fprintln(&answers, q.Answer)
+	}
+	for b.Loop() {
+		s := test.NewSession(strings.NewReader(answers.String()), io.Discard)
+		s.NoGreeting = true
+		if err := s.StartTest(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package quiz
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BookmarkCommand is typed in place of an answer to bookmark the question.
+const BookmarkCommand = "!b"
+
+// The commands that can be typed in place of an answer.  They are handled
+// before the answer is graded, and the answer is asked for again unless
+// the command ends the test.
+const (
+	QuitCommand = "/quit" //End the test now and show the score so far
+	SaveCommand = "/save" //Save the test to SavePath and stop, to finish later with Restore
+	TimeCommand = "/time" //Show the time left for the test and the question
+	FlagCommand = "/flag" //Bookmark the question, like BookmarkCommand
+	HelpCommand = "/help" //List the commands
+)
+
+// commandHelp describes each command for HelpCommand.
+var commandHelp = [][2]string{
+	{QuitCommand, "end the test now and see your score so far"},
+	{SaveCommand, "save the test and stop, to finish it later"},
+	{TimeCommand, "show the time you have left"},
+	{FlagCommand, "bookmark the question to study later, or " + BookmarkCommand},
+	{HelpCommand, "list these commands"},
+}
+
+// showCommands writes the commands the user can type in place of an
+// answer to w.
+func (a *Assessment) showCommands(w io.Writer) {
+	fmt.Fprintln(w, "Commands you can type in place of an answer:")
+	for _, c := range commandHelp {
+		if c[0] == SaveCommand && !a.canSave() {
+			continue
+		}
+		fmt.Fprintf(w, "  %-6s %s\n", c[0], c[1])
+	}
+}
+
+// canSave reports whether SaveCommand can save the test.  Questions from
+// a Source are made as they are asked, so they can't be saved.
+func (a *Assessment) canSave() bool {
+	return a.SavePath != "" && a.Source == nil
+}
+
+// timeLeft returns what TimeCommand shows: the time left for the test and
+// for the question.
+func (a *Assessment) timeLeft() string {
+	a.mu.Lock()
+	test, question := a.testClock.remaining(), a.questionClock.remaining()
+	a.mu.Unlock()
+
+	switch {
+	case a.testClock == nil && a.questionClock == nil:
+		return "There is no time limit"
+	case a.questionClock == nil:
+		return fmt.Sprintf("You have %s left for the test", test)
+	case a.testClock == nil:
+		return fmt.Sprintf("You have %s left for this question", question)
+	default:
+		return fmt.Sprintf("You have %s left for the test and %s for this question", test, question)
+	}
+}
+
+// savedTest is a test saved part way through by SaveCommand.
+type savedTest struct {
+	FilePath       string        `json:"filepath"`  //File the questions were loaded from
+	Name           string        `json:"name"`      //Name of the user taking the test
+	Questions      []Question    `json:"questions"` //Questions in the order they are asked, with the answers so far
+	TotalCorrect   int           `json:"correct"`   //Questions answered correctly so far
+	TotalIncorrect int           `json:"incorrect"` //Questions answered incorrectly so far
+	Elapsed        time.Duration `json:"elapsed"`   //Time taken so far
+	Saved          time.Time     `json:"saved"`     //When the test was saved
+}
+
+// Save writes the test as it is so far to path, to be finished later with
+// Restore.  The question being answered when it is saved is asked again.
+func (a *Assessment) Save(path string) error {
+	data, err := json.MarshalIndent(savedTest{
+		FilePath:       a.FilePath,
+		Name:           a.Name,
+		Questions:      a.Questions,
+		TotalCorrect:   a.TotalCorrect,
+		TotalIncorrect: a.TotalIncorrect,
+		Elapsed:        time.Since(a.TimeStart),
+		Saved:          time.Now(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// Restore carries on with the test saved in path by Save, in place of the
+// loaded questions, which must come from the same file.  StartTest then
+// asks the questions that weren't answered, with the time taken before it
+// was saved counted against the TimeLimit.  The file is removed so the
+// test can only be finished once.
+func (a *Assessment) Restore(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var saved savedTest
+	if err = json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if saved.FilePath != a.FilePath {
+		return fmt.Errorf("the test saved in %s is of %s, not %s", path, saved.FilePath, a.FilePath)
+	}
+	if a.Source != nil || saved.TotalCorrect+saved.TotalIncorrect > len(saved.Questions) {
+		return fmt.Errorf("the test saved in %s can't be carried on with", path)
+	}
+
+	a.Questions, a.TotalQuestions = saved.Questions, len(saved.Questions)
+	a.TotalCorrect, a.TotalIncorrect = saved.TotalCorrect, saved.TotalIncorrect
+	a.elapsed = saved.Elapsed
+	if a.Name == "" {
+		a.Name = saved.Name
+	}
+	return os.Remove(path)
+}
+
+// command handles answer if it is one of the commands, writing what it
+// shows to out, and reports whether it was.  It returns ErrQuit or
+// ErrSaved for the commands that end the test.
+func (a *Assessment) command(q *Question, answer string, out io.Writer) (bool, error) {
+	switch strings.TrimSpace(answer) {
+	case BookmarkCommand, FlagCommand:
+		q.Bookmarked = true
+		fmt.Fprint(out, "Bookmarked. Your answer: ")
+	case TimeCommand:
+		fmt.Fprintf(out, "%s. Your answer: ", a.timeLeft())
+	case HelpCommand:
+		fmt.Fprintln(out)
+		a.showCommands(out)
+		fmt.Fprint(out, "Your answer: ")
+	case QuitCommand:
+		return true, ErrQuit
+	case SaveCommand:
+		if !a.canSave() {
+			fmt.Fprint(out, "This test can't be saved. Your answer: ")
+			return true, nil
+		}
+		return true, ErrSaved
+	default:
+		return false, nil
+	}
+	return true, nil
+}
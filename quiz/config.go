@@ -0,0 +1,118 @@
+package quiz
+
+import (
+	"io"
+	"time"
+)
+
+// Config is the settings for a test, separate from the questions being
+// asked and the state of the test, so it can be built from flags, a file
+// or code.
+type Config struct {
+	FilePath         string        //File or URL containing the questions
+	Shuffle          bool          //Should the questions be shuffled
+	Seed             int64         //Seed for shuffling so the order can be repeated, 0 for a different order every time
+	TotalQuestions   int           //Number of questions in the test, 0 for all of them
+	TimeLimit        time.Duration //The amount of time the user has to complete the test, 0 for no limit
+	QuestionLimit    time.Duration //The amount of time the user has to answer each question, 0 for no limit
+	WarnBefore       time.Duration //How long before the test's or a question's time runs out the user is warned, 0 for no warning
+	Bell             bool          //Ring the terminal bell with the warning that the time is nearly up
+	Lives            int           //Number of wrong answers that end the test, 0 for no limit
+	MaxPerCategory   int           //Most questions from any one category, 0 for no limit
+	OnDuplicate      string        //What to do with questions that repeat an earlier one, one of DuplicatePolicies, empty to keep them
+	Language         string        //Language code of the translations the questions are asked in, empty for the language they are written in
+	LeaderboardURL   string        //URL of the leaderboard server scores are pushed to
+	LeaderboardToken string        //Token the leaderboard server needs to accept scores, empty if it doesn't need one
+	LeaderboardTop   int           //Number of top scores to show from the leaderboard
+}
+
+// DefaultConfig returns the settings the quiz game uses when none are given.
+func DefaultConfig() Config {
+	return Config{
+		FilePath:       "problems.csv",
+		TimeLimit:      time.Second * 30,
+		LeaderboardTop: 10,
+	}
+}
+
+// Option customises an Assessment made by NewAssessment.
+type Option func(a *Assessment)
+
+// NewAssessment returns a test with the settings in cfg, customised by opts.
+// The questions still need loading with LoadQuestions unless they are
+// given with WithQuestions or WithSource.
+func NewAssessment(cfg Config, opts ...Option) *Assessment {
+	a := &Assessment{
+		FilePath:         cfg.FilePath,
+		Shuffle:          cfg.Shuffle,
+		Seed:             cfg.Seed,
+		TotalQuestions:   cfg.TotalQuestions,
+		TimeLimit:        cfg.TimeLimit,
+		QuestionLimit:    cfg.QuestionLimit,
+		WarnBefore:       cfg.WarnBefore,
+		Bell:             cfg.Bell,
+		Lives:            cfg.Lives,
+		MaxPerCategory:   cfg.MaxPerCategory,
+		OnDuplicate:      cfg.OnDuplicate,
+		Language:         cfg.Language,
+		LeaderboardURL:   cfg.LeaderboardURL,
+		LeaderboardToken: cfg.LeaderboardToken,
+		LeaderboardTop:   cfg.LeaderboardTop,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// WithQuestions sets the questions for the test, instead of loading them.
+func WithQuestions(questions []Question) Option {
+	return func(a *Assessment) {
+		a.Questions = questions
+		if a.TotalQuestions == 0 || a.TotalQuestions > len(questions) {
+			a.TotalQuestions = len(questions)
+		}
+	}
+}
+
+// WithSource asks the questions from src as the test goes.
+func WithSource(src QuestionSource) Option {
+	return func(a *Assessment) { a.Source = src }
+}
+
+// WithGrader decides whether answers are correct with g.
+func WithGrader(g Grader) Option {
+	return func(a *Assessment) { a.Grader = g }
+}
+
+// WithScorer calculates the score with s.
+func WithScorer(s Scorer) Option {
+	return func(a *Assessment) { a.Scorer = s }
+}
+
+// WithTranscriber lets the user speak their answers, turned into text by t.
+func WithTranscriber(t Transcriber) Option {
+	return func(a *Assessment) { a.Transcriber = t }
+}
+
+// WithInput reads the user's answers from r.
+func WithInput(r io.Reader) Option {
+	return func(a *Assessment) { a.In = r }
+}
+
+// WithSharedInput reads the user's answers from in, which is shared with
+// other tests, so it is left open when the test finishes.
+func WithSharedInput(in *Input) Option {
+	return func(a *Assessment) { a.input, a.sharedInput = in, true }
+}
+
+// WithStrata samples the loaded questions so they cover the groups strata
+// puts them in, taking quotas[k] questions from group k if quotas isn't nil.
+func WithStrata(strata StrataFunc, quotas map[string]int) Option {
+	return func(a *Assessment) { a.Strata, a.Quotas = strata, quotas }
+}
+
+// WithOutput writes the test to w.
+func WithOutput(w io.Writer) Option {
+	return func(a *Assessment) { a.Out = w }
+}
@@ -0,0 +1,16 @@
+package quiz
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// discardTyped throws away what the user has typed into the terminal In
+// reads from without pressing ENTER, so half an answer typed as the time
+// ran out isn't taken as the answer to the next question.
+func (a *Assessment) discardTyped() {
+	if f, ok := a.In.(*os.File); ok {
+		unix.IoctlSetInt(int(f.Fd()), unix.TCFLSH, unix.TCIFLUSH)
+	}
+}
@@ -0,0 +1,118 @@
+package quiz
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+)
+
+// The kinds of answer wrong choices are matched on, so a date is only
+// given other dates as wrong choices and a number other numbers.
+const (
+	answerText   = "text"
+	answerNumber = "number"
+	answerYear   = "year"
+	answerDate   = "date"
+)
+
+// month matches the name of a month, in full or shortened, as a whole word.
+const month = `(jan(uary)?|feb(ruary)?|mar(ch)?|apr(il)?|may|june?|july?|aug(ust)?|sep(t(ember)?)?|oct(ober)?|nov(ember)?|dec(ember)?)\.?`
+
+var (
+	numberAnswer = regexp.MustCompile(`^[-+]?[\d,]*\.?\d+%?$`)
+	eraAnswer    = regexp.MustCompile(`(?i)^\d{1,4}\s*(bc|bce|ad|ce)$`)
+	yearAnswer   = regexp.MustCompile(`^\d{3,4}$`)
+	yearQuestion = regexp.MustCompile(`(?i)\b(years?|dates?|when)\b`)
+	dateAnswer   = regexp.MustCompile(`(?i)^(\d{4}-\d{1,2}-\d{1,2}` + //2024-07-14
+		`|\d{1,2}[/.]\d{1,2}[/.]\d{2,4}` + //14/07/2024
+		`|\d{1,2}(st|nd|rd|th)?\s+(of\s+)?` + month + `(,?\s+\d{1,4}(\s*(bc|bce|ad|ce))?)?` + //14 July 1789
+		`|` + month + `(\s+\d{1,2}(st|nd|rd|th)?,?)?\s+\d{1,4}(\s*(bc|bce|ad|ce))?)$`) //July 14, 1789
+)
+
+// answerKind returns whether the answer to q is a date, a year, a number
+// or text.  A number is only taken for a year when it has an era, like
+// "44 BC", or the question or its category asks for a year or date, so
+// an answer like "212" to a sum is given other numbers.
+func answerKind(q Question) string {
+	answer := strings.TrimSpace(q.Answer)
+	switch {
+	case dateAnswer.MatchString(answer):
+		return answerDate
+	case eraAnswer.MatchString(answer):
+		return answerYear
+	case yearAnswer.MatchString(answer) && (yearQuestion.MatchString(q.QText) || yearQuestion.MatchString(q.Category)):
+		return answerYear
+	case numberAnswer.MatchString(answer):
+		return answerNumber
+	default:
+		return answerText
+	}
+}
+
+// Distractors are the answers of a question bank grouped by category and
+// kind, to make wrong choices for its free answer questions that are like
+// their answers: the capital of one country is given other capitals to
+// choose from and a date other dates, instead of answers to unrelated
+// questions.  Questions without a category are grouped together.
+type Distractors map[[2]string][]string
+
+// NewDistractors groups the answers of questions.  Answers with templates
+// are left out, since they are only known once the templates are filled
+// in.
+func NewDistractors(questions []Question) Distractors {
+	d := Distractors{}
+	seen := map[[2]string]bool{}
+	for _, q := range questions {
+		answer := strings.TrimSpace(q.Answer)
+		if answer == "" || strings.Contains(answer, "{{") {
+			continue
+		}
+		group := distractorGroup(q)
+		if key := [2]string{group[0], Normalize(answer)}; !seen[key] {
+			seen[key] = true
+			d[group] = append(d[group], answer)
+		}
+	}
+	return d
+}
+
+// distractorGroup returns the group of Distractors the answer to q is in:
+// its category, ignoring case, and the kind of answer.
+func distractorGroup(q Question) [2]string {
+	return [2]string{strings.ToLower(strings.TrimSpace(q.Category)), answerKind(q)}
+}
+
+// Choices returns up to n wrong choices for q, picked at random with r
+// from the other answers in its group.  A question in a small group gets
+// fewer than n, or none, rather than choices from another topic that give
+// the answer away.
+func (d Distractors) Choices(q Question, n int, r *rand.Rand) []string {
+	answer := strings.TrimSpace(q.Answer)
+	pool := d[distractorGroup(q)]
+	var choices []string
+	for _, i := range r.Perm(len(pool)) {
+		if len(choices) == n {
+			break
+		}
+		if Normalize(pool[i]) != Normalize(answer) {
+			choices = append(choices, pool[i])
+		}
+	}
+	return choices
+}
+
+// AddChoices makes the free answer questions multiple choice, each with up
+// to n wrong choices from the answers of the other questions, picked with
+// Distractors.  Questions that already have choices, whose answers have
+// templates or that have translations, whose choices would then be in the
+// wrong language, are left as they are.
+func AddChoices(questions []Question, n int, r *rand.Rand) {
+	d := NewDistractors(questions)
+	for i := range questions {
+		q := &questions[i]
+		if len(q.Choices) > 0 || strings.Contains(q.Answer, "{{") || len(q.Translations) > 0 {
+			continue
+		}
+		q.Choices = d.Choices(*q, n, r)
+	}
+}
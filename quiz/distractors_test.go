@@ -0,0 +1,74 @@
+package quiz
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestAnswerKind(t *testing.T) {
+	tests := []struct {
+		q    Question
+		want string
+	}{
+		{Question{QText: "Capital of France?", Answer: "Paris"}, answerText},
+		{Question{QText: "5+5", Answer: "10"}, answerNumber},
+		{Question{QText: "10*10", Answer: "100"}, answerNumber},
+		{Question{QText: "Boiling point of water in Fahrenheit?", Answer: "212"}, answerNumber},
+		{Question{QText: "Population of Iceland?", Answer: "372,000"}, answerNumber},
+		{Question{QText: "Pi to two places?", Answer: "3.14"}, answerNumber},
+		{Question{QText: "Battle of Hastings?", Answer: "1066"}, answerNumber},
+		{Question{QText: "In what year was the Battle of Hastings?", Answer: "1066"}, answerYear},
+		{Question{QText: "When was the Battle of Hastings?", Answer: "1066"}, answerYear},
+		{Question{QText: "Battle of Hastings?", Answer: "1066", Category: "History dates"}, answerYear},
+		{Question{QText: "Caesar was killed in", Answer: "44 BC"}, answerYear},
+		{Question{QText: "Storming of the Bastille?", Answer: "14 July 1789"}, answerDate},
+		{Question{QText: "Moon landing?", Answer: "July 20, 1969"}, answerDate},
+		{Question{QText: "Armistice Day?", Answer: "11th of November"}, answerDate},
+		{Question{QText: "Y2K?", Answer: "2000-01-01"}, answerDate},
+		{Question{QText: "Christmas?", Answer: "25/12/2024"}, answerDate},
+		{Question{QText: "Nursery rhyme?", Answer: "Mary had 2 lambs"}, answerText},
+		{Question{QText: "Base ten?", Answer: "Decimal 10"}, answerText},
+		{Question{QText: "A marching band?", Answer: "March 4 ever"}, answerText},
+	}
+	for _, tt := range tests {
+		if got := answerKind(tt.q); got != tt.want {
+			t.Errorf("answerKind(%q = %q) = %s, want %s", tt.q.QText, tt.q.Answer, got, tt.want)
+		}
+	}
+}
+
+func TestAddChoices(t *testing.T) {
+	questions := []Question{
+		{QText: "Capital of France?", Answer: "Paris", Category: "Capitals"},
+		{QText: "Capital of Spain?", Answer: "Madrid", Category: "capitals"},
+		{QText: "Capital of Italy?", Answer: "Rome", Category: "Capitals"},
+		{QText: "Storming of the Bastille?", Answer: "14 July 1789", Category: "History"},
+		{QText: "Moon landing?", Answer: "20 July 1969", Category: "History"},
+		{QText: "First emperor of Rome?", Answer: "Augustus", Category: "History"},
+		{QText: "Largest planet?", Answer: "Jupiter", Category: "Space"},
+		{QText: "Colour of the sky?", Answer: "Blue", Choices: []string{"Green"}},
+		{QText: "A random number?", Answer: "{{randint 1 9}}"},
+	}
+	AddChoices(questions, 3, rand.New(rand.NewSource(1)))
+
+	want := map[string][]string{
+		"Paris":        {"Madrid", "Rome"},
+		"Madrid":       {"Paris", "Rome"},
+		"Rome":         {"Madrid", "Paris"},
+		"14 July 1789": {"20 July 1969"},
+		"20 July 1969": {"14 July 1789"},
+		"Augustus":     nil,
+		"Jupiter":      nil,
+		"Blue":         {"Green"},
+	}
+	for _, q := range questions {
+		got := slices.Sorted(slices.Values(q.Choices))
+		if w, ok := want[q.Answer]; ok && !slices.Equal(got, w) {
+			t.Errorf("%s has the choices %q, want %q", q.QText, got, w)
+		}
+	}
+	if q := questions[len(questions)-1]; len(q.Choices) != 0 {
+		t.Errorf("a question with a template answer was given the choices %q", q.Choices)
+	}
+}
@@ -0,0 +1,125 @@
+package quiz
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// drillWindow is the number of recent answers the rolling accuracy of a
+// drill is worked out from.
+const drillWindow = 20
+
+// Drill is a QuestionSource that asks the same questions over and over,
+// for practice that goes on until the user stops.  The questions are
+// reshuffled for each pass, and the ones answered correctly most times in
+// a row are left until the end of the pass, so the questions the user
+// gets wrong come round sooner.  Follow tells a Drill how the questions
+// were answered.
+type Drill struct {
+	questions []Question
+	rand      *rand.Rand
+	pass      []int          //Questions left in this pass, next last
+	streaks   []int          //Times in a row each question has been answered correctly
+	index     map[string]int //Index of each question by its StableID
+	recent    []bool         //Whether the last drillWindow answers were correct
+	passes    int            //Number of passes started
+	last      int            //Index of the last question asked
+}
+
+// NewDrill returns a Drill of questions, shuffled with r.
+func NewDrill(questions []Question, r *rand.Rand) *Drill {
+	d := &Drill{
+		questions: questions,
+		rand:      r,
+		streaks:   make([]int, len(questions)),
+		index:     make(map[string]int, len(questions)),
+	}
+	for i := range questions {
+		d.index[questions[i].StableID()] = i
+	}
+	return d
+}
+
+// HasNext reports whether there is another question, which there always
+// is unless there are no questions.
+func (d *Drill) HasNext() bool {
+	return len(d.questions) > 0
+}
+
+// Next returns the next question, starting a new pass when the last one
+// is finished.
+func (d *Drill) Next() (Question, error) {
+	if len(d.pass) == 0 {
+		d.newPass()
+	}
+	i := d.pass[len(d.pass)-1]
+	d.pass = d.pass[:len(d.pass)-1]
+	d.last = i
+	q := d.questions[i]
+	q.UserAnswer, q.Correct, q.Bookmarked, q.AnswerTime = "", false, false, 0
+	return q, nil
+}
+
+// newPass shuffles the questions for the next pass, with those on the
+// longest streaks last.
+func (d *Drill) newPass() {
+	d.passes++
+	order := d.rand.Perm(len(d.questions))
+	sort.SliceStable(order, func(i, j int) bool { return d.streaks[order[i]] < d.streaks[order[j]] })
+	// The pass is taken from the end
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	// The same question isn't asked twice in a row
+	if n := len(order); d.passes > 1 && n > 1 && order[n-1] == d.last {
+		order[n-1], order[n-2] = order[n-2], order[n-1]
+	}
+	d.pass = order
+}
+
+// Accuracy returns the percentage of the recent answers that were
+// correct, and how many answers that is out of.
+func (d *Drill) Accuracy() (float64, int) {
+	if len(d.recent) == 0 {
+		return 0, 0
+	}
+	right := 0
+	for _, correct := range d.recent {
+		if correct {
+			right++
+		}
+	}
+	return float64(right) / float64(len(d.recent)) * 100, len(d.recent)
+}
+
+// answered keeps track of whether q was answered correctly.
+func (d *Drill) answered(q *Question) {
+	if i, ok := d.index[q.StableID()]; ok {
+		if q.Correct {
+			d.streaks[i]++
+		} else {
+			d.streaks[i] = 0
+		}
+	}
+	d.recent = append(d.recent, q.Correct)
+	if len(d.recent) > drillWindow {
+		d.recent = d.recent[1:]
+	}
+}
+
+// Follow registers a handler with a that tells the drill how each
+// question was answered, and shows the answer and the rolling accuracy.
+func (d *Drill) Follow(a *Assessment) {
+	a.OnAnswered(func(a *Assessment, qnum int, q *Question) {
+		d.answered(q)
+		out := a.output()
+		if q.Correct {
+			fmt.Fprint(out, "Right!")
+		} else {
+			fmt.Fprintf(out, "Wrong, the answer was %s.", q.Answer)
+		}
+		accuracy, of := d.Accuracy()
+		fmt.Fprintf(out, " %.0f%% of your last %v answers were right, pass %v.\n", accuracy, of, d.passes)
+	})
+}
@@ -0,0 +1,66 @@
+package quiz
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// What to do with a question whose text repeats an earlier one, for
+// OnDuplicate and HandleDuplicates.
+const (
+	DuplicatesWarn = "warn" //Keep it and warn about it
+	DuplicatesSkip = "skip" //Leave it out
+	DuplicatesFail = "fail" //Refuse to load the questions
+)
+
+// DuplicatePolicies are the values OnDuplicate can have, other than empty.
+var DuplicatePolicies = []string{DuplicatesWarn, DuplicatesSkip, DuplicatesFail}
+
+// normalizedText returns the question's text in lower case with runs of
+// spaces collapsed, so questions that only differ in case or spacing have
+// the same text.
+func (q *Question) normalizedText() string {
+	return strings.ToLower(strings.Join(strings.Fields(q.QText), " "))
+}
+
+// HandleDuplicates deals with the questions from source whose text repeats
+// an earlier question's, ignoring case and extra spaces, as policy says,
+// and adds the others to seen, which maps the text of the questions seen
+// so far to where they are.  Passing the same seen for several sources finds
+// questions repeated across them.  With DuplicatesWarn each one is kept
+// and written to w, with DuplicatesSkip they are left out, and with
+// DuplicatesFail an error is returned.  An empty policy keeps them all.
+func HandleDuplicates(questions []Question, source string, seen map[string]string, policy string, w io.Writer) ([]Question, error) {
+	switch policy {
+	case "":
+		return questions, nil
+	case DuplicatesWarn, DuplicatesSkip, DuplicatesFail:
+	default:
+		return nil, fmt.Errorf("%q isn't a way to handle duplicate questions, use one of %s", policy, strings.Join(DuplicatePolicies, ", "))
+	}
+
+	kept := questions[:0:0]
+	for _, q := range questions {
+		where := source
+		if q.Line > 0 {
+			where = fmt.Sprintf("%s:%d", source, q.Line)
+		}
+		key := q.normalizedText()
+		first, ok := seen[key]
+		if !ok || key == "" {
+			seen[key] = where
+			kept = append(kept, q)
+			continue
+		}
+		switch policy {
+		case DuplicatesWarn:
+			fmt.Fprintf(w, "%s: %q repeats the question at %s\n", where, q.QText, first)
+			kept = append(kept, q)
+		case DuplicatesSkip:
+		case DuplicatesFail:
+			return nil, fmt.Errorf("%s: %q repeats the question at %s", where, q.QText, first)
+		}
+	}
+	return kept, nil
+}
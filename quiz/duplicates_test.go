@@ -0,0 +1,64 @@
+package quiz
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHandleDuplicates(t *testing.T) {
+	questions := []Question{
+		{QText: "Capital of France?", Line: 1},
+		{QText: "Capital of Spain?", Line: 2},
+		{QText: "  capital of   FRANCE? ", Line: 3},
+		{QText: "", Line: 4},
+		{QText: "", Line: 5},
+	}
+	tests := []struct {
+		policy  string
+		want    []int //Lines of the questions kept
+		wantOut string
+		wantErr string
+	}{
+		{policy: "", want: []int{1, 2, 3, 4, 5}},
+		{policy: DuplicatesWarn, want: []int{1, 2, 3, 4, 5}, wantOut: "quiz.csv:3: \"  capital of   FRANCE? \" repeats the question at quiz.csv:1\n"},
+		{policy: DuplicatesSkip, want: []int{1, 2, 4, 5}},
+		{policy: DuplicatesFail, wantErr: "quiz.csv:3: \"  capital of   FRANCE? \" repeats the question at quiz.csv:1"},
+		{policy: "ignore", wantErr: "isn't a way to handle duplicate questions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			var out bytes.Buffer
+			kept, err := HandleDuplicates(questions, "quiz.csv", make(map[string]string), tt.policy, &out)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("HandleDuplicates() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var lines []int
+			for _, q := range kept {
+				lines = append(lines, q.Line)
+			}
+			if !slices.Equal(lines, tt.want) {
+				t.Errorf("HandleDuplicates() kept lines %v, want %v", lines, tt.want)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("HandleDuplicates() wrote %q, want %q", out.String(), tt.wantOut)
+			}
+		})
+	}
+
+	// Questions repeated in a later source are found too
+	seen := make(map[string]string)
+	HandleDuplicates(questions[:2], "first.csv", seen, DuplicatesSkip, io.Discard)
+	kept, _ := HandleDuplicates([]Question{{QText: "Capital of Spain?", Line: 7}, {QText: "Capital of Italy?", Line: 8}}, "second.csv", seen, DuplicatesSkip, io.Discard)
+	if len(kept) != 1 || kept[0].Line != 8 {
+		t.Errorf("HandleDuplicates() kept %+v from the second source, want only line 8", kept)
+	}
+}
@@ -0,0 +1,12 @@
+package quiz
+
+import "errors"
+
+// Errors returned by the quiz engine, to be checked with errors.Is.
+var (
+	ErrNoQuestions = errors.New("quiz: there are no questions")   //The test has no questions to ask
+	ErrLoadFailed  = errors.New("quiz: unable to load questions") //The questions couldn't be loaded
+	ErrTimeExpired = errors.New("quiz: time's up")                //The time limit ran out before the test was finished
+	ErrQuit        = errors.New("quiz: the test was stopped")     //The user stopped the test with QuitCommand
+	ErrSaved       = errors.New("quiz: the test was saved")       //The user saved the test to finish later with SaveCommand
+)
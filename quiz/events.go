@@ -0,0 +1,108 @@
+package quiz
+
+import "slices"
+
+// hooks holds the handlers registered for the events in a test.
+// Handlers are called in the order they were registered.
+type hooks struct {
+	quizStart     []func(a *Assessment)
+	questionAsked []func(a *Assessment, qnum int, q *Question)
+	answered      []func(a *Assessment, qnum int, q *Question)
+	timeExpired   []func(a *Assessment)
+	finished      []finishedHandler
+	submitted     *bool //Whether the OnSubmit handlers were called for a scored attempt, shared by the rounds made by Replay
+}
+
+// finishedHandler is a handler registered with OnFinished or OnSubmit.
+type finishedHandler struct {
+	h      func(a *Assessment)
+	submit bool //Registered with OnSubmit, so only called for the first scored attempt
+}
+
+// OnQuizStart registers h to be called when the clock starts.
+func (a *Assessment) OnQuizStart(h func(a *Assessment)) {
+	a.hooks.quizStart = append(a.hooks.quizStart, h)
+}
+
+// OnQuestionAsked registers h to be called just before each question is
+// asked.  qnum is the number of the question in the test, starting at 1.
+func (a *Assessment) OnQuestionAsked(h func(a *Assessment, qnum int, q *Question)) {
+	a.hooks.questionAsked = append(a.hooks.questionAsked, h)
+}
+
+// OnAnswered registers h to be called after each question is answered and graded.
+func (a *Assessment) OnAnswered(h func(a *Assessment, qnum int, q *Question)) {
+	a.hooks.answered = append(a.hooks.answered, h)
+}
+
+// OnTimeExpired registers h to be called when the time limit runs out,
+// before the score is shown.
+func (a *Assessment) OnTimeExpired(h func(a *Assessment)) {
+	a.hooks.timeExpired = append(a.hooks.timeExpired, h)
+}
+
+// OnFinished registers h to be called after the score has been shown,
+// however the test ended.
+func (a *Assessment) OnFinished(h func(a *Assessment)) {
+	a.hooks.finished = append(a.hooks.finished, finishedHandler{h: h})
+}
+
+// OnSubmit registers h to be called like an OnFinished handler, for
+// handlers that record or submit the result, such as saving it or sending
+// the grade to an LMS.  They are only called for the first attempt with a
+// question answered, so the rounds of a test played again with Replay
+// don't submit their scores as well.
+func (a *Assessment) OnSubmit(h func(a *Assessment)) {
+	a.hooks.finished = append(a.hooks.finished, finishedHandler{h: h, submit: true})
+}
+
+// clone returns a copy of the handlers that can be added to without
+// affecting h, for a new attempt whose result is submitted too.
+func (h hooks) clone() hooks {
+	return hooks{
+		quizStart:     slices.Clone(h.quizStart),
+		questionAsked: slices.Clone(h.questionAsked),
+		answered:      slices.Clone(h.answered),
+		timeExpired:   slices.Clone(h.timeExpired),
+		finished:      slices.Clone(h.finished),
+	}
+}
+
+func (a *Assessment) emitQuizStart() {
+	for _, h := range a.hooks.quizStart {
+		h(a)
+	}
+}
+
+func (a *Assessment) emitQuestionAsked(qnum int, q *Question) {
+	for _, h := range a.hooks.questionAsked {
+		h(a, qnum, q)
+	}
+}
+
+func (a *Assessment) emitAnswered(qnum int, q *Question) {
+	for _, h := range a.hooks.answered {
+		h(a, qnum, q)
+	}
+}
+
+func (a *Assessment) emitTimeExpired() {
+	for _, h := range a.hooks.timeExpired {
+		h(a)
+	}
+}
+
+func (a *Assessment) emitFinished() {
+	submitted := a.hooks.submitted != nil && *a.hooks.submitted
+	for _, f := range a.hooks.finished {
+		if !f.submit || !submitted {
+			f.h(a)
+		}
+	}
+	if a.TotalCorrect+a.TotalIncorrect > 0 {
+		if a.hooks.submitted == nil {
+			a.hooks.submitted = new(bool)
+		}
+		*a.hooks.submitted = true
+	}
+}
@@ -0,0 +1,78 @@
+package quiz
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+)
+
+// line is a line of the user's input, or the error that ended the input.
+type line struct {
+	text string
+	err  error
+}
+
+// Input is the user's input to a test, read a line at a time.  One
+// goroutine reads the input, so a line isn't lost when a read is given up
+// on because the time ran out.  Tests run one after another on the same
+// terminal, such as the sections of an exam, can share an Input with
+// WithSharedInput so no line is lost between them either.
+type Input struct {
+	reader *bufio.Reader
+	ch     chan line
+	done   chan struct{}
+	start  sync.Once
+	stop   sync.Once
+}
+
+// NewInput returns the Input read from r.
+func NewInput(r io.Reader) *Input {
+	return &Input{reader: bufio.NewReader(r), ch: make(chan line), done: make(chan struct{})}
+}
+
+// lines returns the channel the lines arrive on, starting the goroutine
+// that reads them the first time.  It stops when the input ends or Close
+// is called.
+func (in *Input) lines() <-chan line {
+	in.start.Do(func() {
+		go func() {
+			send := func(l line) bool {
+				select {
+				case in.ch <- l:
+					return true
+				case <-in.done:
+					return false
+				}
+			}
+			for {
+				// A last line without a newline still counts as a line
+				text, err := in.reader.ReadString('\n')
+				if text != "" && !send(line{text: text}) {
+					return
+				}
+				if err != nil {
+					send(line{err: err})
+					return
+				}
+			}
+		}()
+	})
+	return in.ch
+}
+
+// ReadLine waits for a line of input, giving up with ctx's error when ctx
+// is done.
+func (in *Input) ReadLine(ctx context.Context) (string, error) {
+	select {
+	case l := <-in.lines():
+		return l.text, l.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Close stops reading the input.
+func (in *Input) Close() {
+	in.stop.Do(func() { close(in.done) })
+}
@@ -0,0 +1,43 @@
+package quiz
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Progress is a snapshot of how far a participant has got through the test.
+// It deliberately contains no questions or answers so it is safe to show to
+// an observer while the test is still running.
+type Progress struct {
+	Name      string    `json:"name"`      //Name of the participant
+	Question  int       `json:"question"`  //Number of the question being answered, 0 before the test starts
+	Total     int       `json:"total"`     //Number of questions in the test
+	Correct   int       `json:"correct"`   //Questions answered correctly so far
+	Incorrect int       `json:"incorrect"` //Questions answered incorrectly so far
+	Started   time.Time `json:"started"`   //When the test started, zero before the test starts
+	Finished  bool      `json:"finished"`  //Whether the test is over
+}
+
+// ReportProgressTo registers handlers that write the Progress of the test
+// to w as lines of JSON as the test goes on: when it starts, when each
+// question is asked and answered, and when it is finished.
+func (a *Assessment) ReportProgressTo(w io.Writer) {
+	enc := json.NewEncoder(w)
+	report := func(a *Assessment, question int, finished bool) {
+		enc.Encode(Progress{
+			Name:      a.Name,
+			Question:  question,
+			Total:     a.Total(),
+			Correct:   a.TotalCorrect,
+			Incorrect: a.TotalIncorrect,
+			Started:   a.TimeStart,
+			Finished:  finished,
+		})
+	}
+
+	a.OnQuizStart(func(a *Assessment) { report(a, 0, false) })
+	a.OnQuestionAsked(func(a *Assessment, qnum int, q *Question) { report(a, qnum, false) })
+	a.OnAnswered(func(a *Assessment, qnum int, q *Question) { report(a, qnum, false) })
+	a.OnFinished(func(a *Assessment) { report(a, a.TotalCorrect+a.TotalIncorrect, true) })
+}
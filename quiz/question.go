@@ -0,0 +1,151 @@
+package quiz
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Question struct stores the fields for each question in the assessment.
+type Question struct {
+	ID           string                 //Identifier that stays the same when the question is edited or moved, if the file gives one
+	QText        string                 //Question text
+	Answer       string                 //Correct Answer for Question
+	UserAnswer   string                 //Answer the user Provided
+	Correct      bool                   //Whether the user got the answer right or not
+	Bookmarked   bool                   //Whether the user bookmarked the question to study later
+	AnswerTime   time.Duration          //How long the user took to answer, 0 until the question is answered
+	Choices      []string               //Choices for a multiple choice question, empty for free answer questions
+	Hint         string                 //Hint to help the user answer, if any
+	Explanation  string                 //Why the answer is right, shown with the score when the question is answered wrongly, if any
+	Points       float64                //What a correct answer is worth towards the score, 0 for 1 point
+	Category     string                 //Topic the question is about, if any
+	Difficulty   string                 //How hard the question is, e.g. "easy" or "hard", if known
+	TimeLimit    time.Duration          //Time to answer this question, in place of the test's QuestionLimit, 0 to use the test's
+	Line         int                    //Line of the question file the question starts on, 0 if unknown
+	Translations map[string]Translation //The question in other languages, by language code such as "fr", if the file gives any
+}
+
+// StableID returns an identifier for the question that stays the same
+// when the questions in a file are reordered: its ID if it has one, or
+// else a hash of its text.  A question's hash changes when its text is
+// edited, so files that are edited should give IDs.
+func (q *Question) StableID() string {
+	if q.ID != "" {
+		return q.ID
+	}
+	sum := sha256.Sum256([]byte(q.normalizedText()))
+	return hex.EncodeToString(sum[:6])
+}
+
+// Worth returns the Points a correct answer to the question is worth.
+func (q *Question) Worth() float64 {
+	if q.Points > 0 {
+		return q.Points
+	}
+	return 1
+}
+
+// AskQuestion delivers a question to out and tracks the user's response read
+// from in in the Question struct.  The qnum variable tracks the number for the question.
+// Pass the same *bufio.Reader for every question, otherwise input buffered
+// while reading one answer is lost before the next.
+// It stops waiting for the answer and returns ctx's error when ctx is done.
+func (q *Question) AskQuestion(ctx context.Context, in io.Reader, out io.Writer, qnum int) (err error) {
+	reader, ok := in.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReader(in)
+	}
+
+	q.Prompt(out, qnum)
+	answer, err := readLine(ctx, reader)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		fmt.Fprintln(out, "Error occurred:", err)
+		return err
+	}
+	q.record(answer)
+	return nil
+}
+
+// Prompt writes the question to out as it is asked in the terminal.  A
+// multiple choice question lists its options, in alphabetical order so the
+// answer isn't always first, and the hint is shown below the question.
+func (q *Question) Prompt(out io.Writer, qnum int) {
+	options := q.Options()
+	if options == nil && q.Hint == "" {
+		fmt.Fprintf(out, "%v. %s = ", qnum, q.QText)
+		return
+	}
+	fmt.Fprintf(out, "%v. %s\n", qnum, q.QText)
+	if options != nil {
+		options = slices.Clone(options)
+		slices.Sort(options)
+		fmt.Fprintf(out, "    Choices:  %s\n", strings.Join(options, " | "))
+	}
+	if q.Hint != "" {
+		fmt.Fprintf(out, "    Hint:     %s\n", q.Hint)
+	}
+	fmt.Fprint(out, "    = ")
+}
+
+// record keeps the user's answer, normalized, and whether it is correct.
+func (q *Question) record(answer string) {
+	q.UserAnswer = Normalize(answer)
+	q.Correct = q.IsCorrect(q.UserAnswer)
+}
+
+// IsCorrect reports whether answer is the correct answer to the question.
+// Both are normalized first, so an answer typed with an input method or
+// read from a file with invisible characters in it matches.
+func (q *Question) IsCorrect(answer string) bool {
+	return Normalize(answer) == Normalize(q.Answer)
+}
+
+// Options returns the choices for a multiple choice question with the
+// answer included, or nil for a free answer question.
+func (q *Question) Options() []string {
+	if len(q.Choices) == 0 {
+		return nil
+	}
+	for _, c := range q.Choices {
+		if c == q.Answer {
+			return q.Choices
+		}
+	}
+	return append([]string{q.Answer}, q.Choices...)
+}
+
+// readLine reads a line from reader, giving up when ctx is done.
+// A read that is given up on carries on in the background and the line it
+// reads is lost, so reader shouldn't be used again once ctx is done.
+func readLine(ctx context.Context, reader *bufio.Reader) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	type result struct {
+		line string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		line, err := reader.ReadString('\n')
+		done <- result{line, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.line, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
@@ -0,0 +1,38 @@
+package quiz
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestPrompt(t *testing.T) {
+	tests := []struct {
+		name string
+		q    Question
+		want string
+	}{
+		{name: "free answer", q: Question{QText: "5+5", Answer: "10"}, want: "3. 5+5 = "},
+		{name: "choices", q: Question{QText: "Capital of France?", Answer: "Paris", Choices: []string{"Rome", "Madrid"}},
+			want: "3. Capital of France?\n    Choices:  Madrid | Paris | Rome\n    = "},
+		{name: "choices with the answer", q: Question{QText: "Capital of France?", Answer: "Paris", Choices: []string{"Paris", "Rome"}},
+			want: "3. Capital of France?\n    Choices:  Paris | Rome\n    = "},
+		{name: "hint", q: Question{QText: "Capital of France?", Answer: "Paris", Hint: "It's on the Seine"},
+			want: "3. Capital of France?\n    Hint:     It's on the Seine\n    = "},
+		{name: "choices and hint", q: Question{QText: "Capital of France?", Answer: "Paris", Choices: []string{"Rome"}, Hint: "It's on the Seine"},
+			want: "3. Capital of France?\n    Choices:  Paris | Rome\n    Hint:     It's on the Seine\n    = "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			choices := slices.Clone(tt.q.Choices)
+			var out bytes.Buffer
+			tt.q.Prompt(&out, 3)
+			if out.String() != tt.want {
+				t.Errorf("Prompt() wrote %q, want %q", out.String(), tt.want)
+			}
+			if !slices.Equal(tt.q.Choices, choices) {
+				t.Errorf("Prompt() reordered the question's choices to %q", tt.q.Choices)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package quiz
+
+import (
+	"errors"
+	"slices"
+)
+
+// The ways a test can be taken again with Replay.
+const (
+	ReplaySame     = "same"       //The same questions in the same order
+	ReplayShuffled = "reshuffled" //The same questions in a new order
+	ReplayMissed   = "missed"     //Only the questions that weren't answered correctly
+)
+
+// unanswered returns a copy of the question as it was before it was asked.
+func (q Question) unanswered() Question {
+	q.UserAnswer, q.Correct, q.Bookmarked, q.AnswerTime = "", false, false, 0
+	q.Choices = slices.Clone(q.Choices)
+	return q
+}
+
+// Replay returns a new round of the test, after it has finished, for the
+// same user to take again in the way how says, one of the Replay constants.
+// The round starts without the greeting, and reads the same input, so the
+// input should be shared with WithSharedInput for no line to be lost
+// between rounds.  The OnSubmit handlers are only called for the first
+// round with a question answered.  It returns ErrNoQuestions if there are none to ask,
+// such as when every question was answered correctly and only the missed
+// ones are asked again.
+func (a *Assessment) Replay(how string) (*Assessment, error) {
+	if a.Source != nil {
+		return nil, errors.New("quiz: questions from a source can't be asked again")
+	}
+	s := a.NewSession(a.In, a.Out)
+	s.Questions = s.Questions[:0]
+	for _, q := range a.Questions {
+		if how != ReplayMissed || !q.Correct {
+			s.Questions = append(s.Questions, q.unanswered())
+		}
+	}
+	if len(s.Questions) == 0 {
+		return nil, ErrNoQuestions
+	}
+	s.TotalQuestions = len(s.Questions)
+
+	// Each round carries on with the random source, so a Seed doesn't
+	// give every round the same order
+	s.rand = a.random()
+	if how == ReplayShuffled {
+		s.rand.Shuffle(len(s.Questions), func(i, j int) { s.Questions[i], s.Questions[j] = s.Questions[j], s.Questions[i] })
+	}
+	s.input, s.sharedInput = a.input, a.sharedInput
+	if a.hooks.submitted == nil {
+		a.hooks.submitted = new(bool)
+	}
+	s.hooks.submitted = a.hooks.submitted
+	s.Name, s.NoGreeting = a.Name, true
+	return s, nil
+}
@@ -0,0 +1,213 @@
+package quiz
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// CapPerCategory returns the questions without those after the first max
+// in each category, keeping their order.  Questions without a category
+// are all kept.
+func CapPerCategory(questions []Question, max int) []Question {
+	keep := capCategories(max)
+	var capped []Question
+	for _, q := range questions {
+		if keep(q) {
+			capped = append(capped, q)
+		}
+	}
+	return capped
+}
+
+// capCategories returns a function that reports whether each question in
+// turn is one of the first max in its category, or always true if max is 0.
+func capCategories(max int) func(Question) bool {
+	counts := make(map[string]int)
+	return func(q Question) bool {
+		if max <= 0 || q.Category == "" {
+			return true
+		}
+		if counts[q.Category] >= max {
+			return false
+		}
+		counts[q.Category]++
+		return true
+	}
+}
+
+// Reservoir picks a sample of questions at random as they are added one at
+// a time, without holding the others, so a sample can be taken from a
+// file too large to load (reservoir sampling).
+type Reservoir struct {
+	sample []Question
+	size   int        //Number of questions in the sample
+	seen   int        //Number of questions added
+	rand   *rand.Rand //Random source for picking
+}
+
+// NewReservoir returns a Reservoir that picks n questions with r.
+func NewReservoir(n int, r *rand.Rand) *Reservoir {
+	return &Reservoir{size: n, rand: r}
+}
+
+// Add offers q for the sample, which it is in with the same chance as
+// every other question added.
+func (s *Reservoir) Add(q Question) {
+	s.seen++
+	if len(s.sample) < s.size {
+		s.sample = append(s.sample, q)
+		return
+	}
+	if i := s.rand.Intn(s.seen); i < s.size {
+		s.sample[i] = q
+	}
+}
+
+// Seen returns the number of questions added.
+func (s *Reservoir) Seen() int {
+	return s.seen
+}
+
+// Sample returns the questions picked, in a random order.
+func (s *Reservoir) Sample() []Question {
+	sample := append([]Question(nil), s.sample...)
+	s.rand.Shuffle(len(sample), func(i, j int) { sample[i], sample[j] = sample[j], sample[i] })
+	return sample
+}
+
+// StrataFunc returns the key of the group a question is in for sampling,
+// e.g. its category.
+type StrataFunc func(q Question) string
+
+// Sample returns n questions picked at random from questions with the
+// random source r, or all of them in a random order if there are n or
+// fewer.
+//
+// When strata is not nil the questions are grouped by the key it returns
+// for each question, e.g. the category, and each group is sampled in
+// proportion to its size, so the sample covers the groups like the whole
+// set of questions does.  Questions left over after the whole shares go
+// to the groups that got none first, so small groups aren't always left out.
+func Sample(questions []Question, n int, strata func(Question) string, r *rand.Rand) []Question {
+	if n <= 0 || n > len(questions) {
+		n = len(questions)
+	}
+	if strata == nil {
+		strata = func(Question) string { return "" }
+	}
+
+	// Group the questions, keeping the groups in the order they first appear
+	groups := make(map[string][]Question)
+	var keys []string
+	for _, q := range questions {
+		k := strata(q)
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], q)
+	}
+
+	// Share out n by the largest remainder method
+	type share struct {
+		key   string
+		count int
+		rem   float64
+	}
+	shares := make([]share, len(keys))
+	given := 0
+	for i, k := range keys {
+		exact := float64(n) * float64(len(groups[k])) / float64(len(questions))
+		shares[i] = share{key: k, count: int(exact), rem: exact - float64(int(exact))}
+		given += shares[i].count
+	}
+	order := make([]int, len(shares))
+	for i := range order {
+		order[i] = i
+	}
+	// Groups that got nothing go first so every group is covered if it can be
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := shares[order[i]], shares[order[j]]
+		if (a.count == 0) != (b.count == 0) {
+			return a.count == 0
+		}
+		return a.rem > b.rem
+	})
+	for i := 0; given < n; i = (i + 1) % len(order) {
+		s := &shares[order[i]]
+		if s.count < len(groups[s.key]) {
+			s.count++
+			given++
+		}
+	}
+
+	var sample []Question
+	for _, s := range shares {
+		group := append([]Question(nil), groups[s.key]...)
+		r.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+		sample = append(sample, group[:s.count]...)
+	}
+	r.Shuffle(len(sample), func(i, j int) { sample[i], sample[j] = sample[j], sample[i] })
+	return sample
+}
+
+// SampleQuotas returns quotas[k] questions picked at random with the
+// random source r from the questions strata groups under each key k, in
+// a random order.  Groups without a quota are left out.  It returns an
+// error if a group has fewer questions than its quota.
+func SampleQuotas(questions []Question, quotas map[string]int, strata func(Question) string, r *rand.Rand) ([]Question, error) {
+	groups := make(map[string][]Question)
+	for _, q := range questions {
+		k := strata(q)
+		groups[k] = append(groups[k], q)
+	}
+
+	// The keys are sorted so the same seed picks the same questions
+	keys := make([]string, 0, len(quotas))
+	for k := range quotas {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sample []Question
+	for _, k := range keys {
+		group := append([]Question(nil), groups[k]...)
+		if quotas[k] > len(group) {
+			return nil, fmt.Errorf("there are %v questions in %q, too few for %v of them", len(group), k, quotas[k])
+		}
+		r.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+		sample = append(sample, group[:quotas[k]]...)
+	}
+	r.Shuffle(len(sample), func(i, j int) { sample[i], sample[j] = sample[j], sample[i] })
+	return sample, nil
+}
+
+// WeightedSample returns n questions picked at random from questions with
+// the random source r, or all of them if there are n or fewer, where a
+// question's chance of being picked, and of being asked early, is in
+// proportion to its weight.  Questions with a weight of 0 or less are
+// only picked once the rest are.
+func WeightedSample(questions []Question, n int, weight func(Question) float64, r *rand.Rand) []Question {
+	if n <= 0 || n > len(questions) {
+		n = len(questions)
+	}
+
+	// Each question gets a key of u^(1/w) for a uniform u, and the n with
+	// the largest keys are a weighted sample (Efraimidis and Spirakis)
+	keys := make([]float64, len(questions))
+	order := make([]int, len(questions))
+	for i, q := range questions {
+		order[i] = i
+		keys[i] = -1 - r.Float64()
+		if w := weight(q); w > 0 {
+			keys[i] = math.Pow(r.Float64(), 1/w)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return keys[order[i]] > keys[order[j]] })
+
+	sample := make([]Question, n)
+	for i := range sample {
+		sample[i] = questions[order[i]]
+	}
+	return sample
+}
@@ -0,0 +1,114 @@
+package quiz
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	category := func(q Question) string { return q.Category }
+	tests := []struct {
+		name      string
+		questions []Question
+		n         int
+		strata    func(Question) string
+		want      map[string]int //Questions wanted from each category
+	}{
+		{name: "all of them", questions: benchQuestions(20), n: 0, want: map[string]int{"category 0": 2, "category 1": 2, "category 2": 2, "category 3": 2, "category 4": 2, "category 5": 2, "category 6": 2, "category 7": 2, "category 8": 2, "category 9": 2}},
+		{name: "more than there are", questions: benchQuestions(10), n: 50, strata: category, want: map[string]int{"category 0": 1, "category 1": 1, "category 2": 1, "category 3": 1, "category 4": 1, "category 5": 1, "category 6": 1, "category 7": 1, "category 8": 1, "category 9": 1}},
+		{name: "in proportion", questions: benchQuestions(100), n: 20, strata: category, want: map[string]int{"category 0": 2, "category 1": 2, "category 2": 2, "category 3": 2, "category 4": 2, "category 5": 2, "category 6": 2, "category 7": 2, "category 8": 2, "category 9": 2}},
+		{name: "small group gets the leftover", questions: append(benchQuestions(10)[:1:1], Question{QText: "a", Category: "large"}, Question{QText: "b", Category: "large"}, Question{QText: "c", Category: "large"}, Question{QText: "d", Category: "large"}, Question{QText: "e", Category: "large"}, Question{QText: "f", Category: "large"}, Question{QText: "g", Category: "large"}, Question{QText: "h", Category: "large"}, Question{QText: "i", Category: "large"}), n: 2, strata: category, want: map[string]int{"category 0": 1, "large": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample := Sample(tt.questions, tt.n, tt.strata, rand.New(rand.NewSource(1)))
+			got := make(map[string]int)
+			for _, q := range sample {
+				got[q.Category]++
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Sample() took %v from each category, want %v", got, tt.want)
+			}
+			if len(slices.Compact(slices.Sorted(slices.Values(texts(sample))))) != len(sample) {
+				t.Errorf("Sample() picked a question twice: %q", texts(sample))
+			}
+		})
+	}
+}
+
+func TestSampleQuotas(t *testing.T) {
+	category := func(q Question) string { return q.Category }
+	sample, err := SampleQuotas(benchQuestions(30), map[string]int{"category 1": 3, "category 2": 1}, category, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int)
+	for _, q := range sample {
+		got[q.Category]++
+	}
+	if got["category 1"] != 3 || got["category 2"] != 1 || len(got) != 2 {
+		t.Errorf("SampleQuotas() took %v from each category", got)
+	}
+	if _, err = SampleQuotas(benchQuestions(30), map[string]int{"category 1": 4}, category, rand.New(rand.NewSource(1))); err == nil {
+		t.Error("SampleQuotas() took more questions than a category has")
+	}
+}
+
+func TestReservoir(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		added    int
+		wantSize int
+	}{
+		{name: "fewer than the size", size: 10, added: 4, wantSize: 4},
+		{name: "the size", size: 10, added: 10, wantSize: 10},
+		{name: "more than the size", size: 10, added: 1000, wantSize: 10},
+		{name: "empty", size: 10, added: 0, wantSize: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			questions := benchQuestions(tt.added)
+			s := NewReservoir(tt.size, rand.New(rand.NewSource(1)))
+			for _, q := range questions {
+				s.Add(q)
+			}
+			if s.Seen() != tt.added {
+				t.Errorf("Seen() = %v, want %v", s.Seen(), tt.added)
+			}
+			sample := texts(s.Sample())
+			if len(sample) != tt.wantSize {
+				t.Fatalf("Sample() has %v questions, want %v", len(sample), tt.wantSize)
+			}
+			added := texts(questions)
+			for _, q := range sample {
+				if !slices.Contains(added, q) {
+					t.Errorf("Sample() has %q, which wasn't added", q)
+				}
+			}
+			if len(slices.Compact(slices.Sorted(slices.Values(sample)))) != len(sample) {
+				t.Errorf("Sample() picked a question twice: %q", sample)
+			}
+		})
+	}
+
+	// Every question has the same chance of being picked, so later ones
+	// aren't favoured
+	late := 0
+	for seed := range int64(200) {
+		s := NewReservoir(10, rand.New(rand.NewSource(seed)))
+		for _, q := range benchQuestions(100) {
+			s.Add(q)
+		}
+		for _, q := range s.Sample() {
+			if q.Line > 50 {
+				late++
+			}
+		}
+	}
+	if late < 800 || late > 1200 {
+		t.Errorf("%v of 2000 questions picked were from the second half, want about 1000", late)
+	}
+}
@@ -0,0 +1,63 @@
+package quiz
+
+// QuestionSource yields the questions for a test one at a time, so
+// generators, APIs and adaptive engines can feed StartTest without making
+// every question up front.
+type QuestionSource interface {
+	HasNext() bool           //Whether there is another question
+	Next() (Question, error) //The next question
+}
+
+// SliceSource is a QuestionSource for questions that are already loaded.
+type SliceSource struct {
+	Questions []Question
+	next      int
+}
+
+// NewSliceSource returns a QuestionSource that yields questions in order.
+func NewSliceSource(questions []Question) *SliceSource {
+	return &SliceSource{Questions: questions}
+}
+
+// HasNext reports whether there are questions left.
+func (s *SliceSource) HasNext() bool {
+	return s.next < len(s.Questions)
+}
+
+// Next returns the next question.
+func (s *SliceSource) Next() (Question, error) {
+	q := s.Questions[s.next]
+	s.next++
+	return q, nil
+}
+
+// nextQuestion returns the question to ask as the i'th question of the
+// test, or nil when there are no more.  Questions from a Source are added
+// to Questions as they are asked.
+func (a *Assessment) nextQuestion(i int) (*Question, error) {
+	if a.Source == nil {
+		if i >= len(a.Questions) {
+			return nil, nil
+		}
+		return &a.Questions[i], nil
+	}
+
+	if (a.TotalQuestions > 0 && i >= a.TotalQuestions) || !a.Source.HasNext() {
+		return nil, nil
+	}
+	q, err := a.Source.Next()
+	if err != nil {
+		return nil, err
+	}
+	a.Questions = append(a.Questions, q)
+	return &a.Questions[len(a.Questions)-1], nil
+}
+
+// Total returns the number of questions in the test.  A test fed by a
+// Source without a TotalQuestions limit has as many questions as were asked.
+func (a *Assessment) Total() int {
+	if a.TotalQuestions == 0 {
+		return len(a.Questions)
+	}
+	return a.TotalQuestions
+}
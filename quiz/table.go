@@ -0,0 +1,163 @@
+package quiz
+
+import (
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// width returns the number of columns of text the score table has to fit
+// in: Width if it is set, or else the width of the terminal Out writes to,
+// or $COLUMNS, or 0 if it isn't known.
+func (a *Assessment) width() int {
+	if a.Width > 0 {
+		return a.Width
+	}
+	if a.isTerminal() {
+		if w, _, err := term.GetSize(int(a.output().(*os.File).Fd())); err == nil && w > 0 {
+			return w
+		}
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return 0
+}
+
+// isTerminal reports whether Out writes to a terminal.
+func (a *Assessment) isTerminal() bool {
+	f, ok := a.output().(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// fitTable fits a table with header and rows into width columns of text,
+// the way tablewriter draws it, by wrapping the text in the columns
+// fitted, or cutting it short if cut is set.  The columns are given the
+// width they need if they can be, and share what is left if they can't.
+// It reports which rows were cut short.
+func fitTable(header []string, rows [][]string, fitted []int, width int, cut bool) []bool {
+	natural := make([]int, len(header))
+	for i, h := range header {
+		natural[i] = textWidth(h)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			natural[i] = max(natural[i], textWidth(cell))
+		}
+	}
+
+	// Each column takes its text and " | ", and the table starts with "|"
+	avail := width - 1
+	for i, w := range natural {
+		avail -= 3
+		if !slices.Contains(fitted, i) {
+			avail -= w
+		}
+	}
+	need := 0
+	for _, i := range fitted {
+		need += natural[i]
+	}
+	wasCut := make([]bool, len(rows))
+	if need <= avail {
+		return wasCut
+	}
+
+	// The narrowest columns get what they need first, and the rest share
+	// what is left, but no column is narrower than its header
+	limit := make(map[int]int, len(fitted))
+	left := fitted
+	for len(left) > 0 {
+		share := avail / len(left)
+		var wide []int
+		for _, i := range left {
+			if natural[i] <= share {
+				limit[i] = natural[i]
+				avail -= natural[i]
+			} else {
+				wide = append(wide, i)
+			}
+		}
+		if len(wide) == len(left) {
+			for _, i := range wide {
+				limit[i] = max(share, textWidth(header[i]))
+			}
+			break
+		}
+		left = wide
+	}
+
+	for r, row := range rows {
+		for _, i := range fitted {
+			if textWidth(row[i]) <= limit[i] {
+				continue
+			}
+			if cut {
+				row[i] = runewidth.Truncate(strings.Join(strings.Fields(row[i]), " "), limit[i], "…")
+				wasCut[r] = true
+			} else {
+				row[i] = wrap(row[i], limit[i])
+			}
+		}
+		// tablewriter pads cells with fewer lines than the others in the row
+		// with two spaces, too wide for a narrow column, so they're padded here
+		lines := 0
+		for _, cell := range row {
+			lines = max(lines, strings.Count(cell, "\n"))
+		}
+		for i, cell := range row {
+			row[i] += strings.Repeat("\n", lines-strings.Count(cell, "\n"))
+		}
+	}
+	return wasCut
+}
+
+// textWidth returns the width of the widest line of text.
+func textWidth(text string) int {
+	w := 0
+	for _, line := range strings.Split(text, "\n") {
+		w = max(w, runewidth.StringWidth(line))
+	}
+	return w
+}
+
+// wrap breaks text into lines no wider than width, between words where it
+// can and inside words too long for a line.
+func wrap(text string, width int) string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for runewidth.StringWidth(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			head := runewidth.Truncate(word, width, "")
+			if head == "" {
+				_, size := utf8.DecodeRuneInString(word)
+				head = word[:size]
+			}
+			lines = append(lines, head)
+			word = word[len(head):]
+		}
+		switch {
+		case word == "":
+		case line == "":
+			line = word
+		case runewidth.StringWidth(line)+1+runewidth.StringWidth(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
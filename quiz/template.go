@@ -0,0 +1,309 @@
+package quiz
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ExpandTemplates replaces the {{...}} templates in the text, answer,
+// choices and hint of q with their values, so one question in a file can
+// be asked with different numbers or words each time.  A template can be:
+//
+//	{{rand 1 100}}             a random whole number from 1 to 100
+//	{{choose "cat" "dog"}}     one of the values, at random, in double or single quotes
+//	{{a = rand 1 10}}          sets the variable a and shows its value
+//	{{a}}, {{a + b * 2}}       a variable or arithmetic with + - * / % and brackets
+//
+// Variables are shared by the fields of the question, so a question of
+// "What is {{a = rand 1 9}} + {{b = rand 1 9}}?" can have an answer of
+// "{{a + b}}".  The fields are expanded in order: the question, answer,
+// choices and then hint.  Random values come from r.
+// A question with templates and no ID is given the ID of its template,
+// so its history is kept together whatever values it is asked with.
+func (q *Question) ExpandTemplates(r *rand.Rand) (err error) {
+	if q.ID == "" && strings.Contains(q.QText, "{{") {
+		q.ID = q.StableID()
+	}
+	t := &template{vars: make(map[string]value), rand: r}
+	fields := append([]*string{&q.QText, &q.Answer}, make([]*string, len(q.Choices))...)
+	for i := range q.Choices {
+		fields[2+i] = &q.Choices[i]
+	}
+	fields = append(fields, &q.Hint, &q.Explanation)
+
+	for _, f := range fields {
+		if *f, err = t.expand(*f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// value is the value of a template expression, a number or a string.
+type value struct {
+	num   int64
+	str   string
+	isStr bool
+}
+
+func (v value) String() string {
+	if v.isStr {
+		return v.str
+	}
+	return strconv.FormatInt(v.num, 10)
+}
+
+// template expands the templates of one question.
+type template struct {
+	vars map[string]value
+	rand *rand.Rand
+
+	tokens []string //Tokens of the template being evaluated
+	pos    int      //Index of the next token
+}
+
+// expand replaces each {{...}} in text with its value.
+func (t *template) expand(text string) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(text, "{{")
+		if start < 0 {
+			b.WriteString(text)
+			return b.String(), nil
+		}
+		end := strings.Index(text[start:], "}}")
+		if end < 0 {
+			return "", fmt.Errorf("the template in %q isn't closed with }}", text)
+		}
+		src := text[start+2 : start+end]
+		v, err := t.eval(src)
+		if err != nil {
+			return "", fmt.Errorf("{{%s}}: %w", src, err)
+		}
+		b.WriteString(text[:start])
+		b.WriteString(v.String())
+		text = text[start+end+2:]
+	}
+}
+
+// eval evaluates the source of one template.
+func (t *template) eval(src string) (value, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return value{}, err
+	}
+	t.tokens, t.pos = tokens, 0
+
+	name := ""
+	if len(tokens) > 2 && tokens[1] == "=" && isIdent(tokens[0]) {
+		name, t.pos = tokens[0], 2
+	}
+	v, err := t.expr()
+	if err != nil {
+		return value{}, err
+	}
+	if t.pos < len(t.tokens) {
+		return value{}, fmt.Errorf("unexpected %q", t.tokens[t.pos])
+	}
+	if name != "" {
+		t.vars[name] = v
+	}
+	return v, nil
+}
+
+// tokenize splits the source of a template into numbers, strings,
+// names and operators.
+func tokenize(src string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != src[i] {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("the string %s isn't closed", src[i:])
+			}
+			tokens = append(tokens, src[i:j+1])
+			i = j + 1
+		case unicode.IsDigit(c) || unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(src) && (unicode.IsDigit(rune(src[j])) || unicode.IsLetter(rune(src[j])) || src[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, src[i:j])
+			i = j
+		case strings.ContainsRune("+-*/%()=", c):
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+func isIdent(token string) bool {
+	r := rune(token[0])
+	return unicode.IsLetter(r) || r == '_'
+}
+
+// peek returns the next token, or "" at the end.
+func (t *template) peek() string {
+	if t.pos < len(t.tokens) {
+		return t.tokens[t.pos]
+	}
+	return ""
+}
+
+// expr evaluates terms added or subtracted together.
+func (t *template) expr() (value, error) {
+	v, err := t.term()
+	for err == nil && (t.peek() == "+" || t.peek() == "-") {
+		op := t.tokens[t.pos]
+		t.pos++
+		var w value
+		if w, err = t.term(); err == nil {
+			v, err = arith(op, v, w)
+		}
+	}
+	return v, err
+}
+
+// term evaluates factors multiplied or divided together.
+func (t *template) term() (value, error) {
+	v, err := t.unary()
+	for err == nil && (t.peek() == "*" || t.peek() == "/" || t.peek() == "%") {
+		op := t.tokens[t.pos]
+		t.pos++
+		var w value
+		if w, err = t.unary(); err == nil {
+			v, err = arith(op, v, w)
+		}
+	}
+	return v, err
+}
+
+// unary evaluates a negated factor or a primary.
+func (t *template) unary() (value, error) {
+	if t.peek() == "-" {
+		t.pos++
+		v, err := t.unary()
+		if err != nil {
+			return v, err
+		}
+		return arith("-", value{}, v)
+	}
+	return t.primary()
+}
+
+// primary evaluates a number, string, variable, function call or
+// expression in brackets.
+func (t *template) primary() (value, error) {
+	token := t.peek()
+	switch {
+	case token == "":
+		return value{}, fmt.Errorf("unexpected end")
+	case token == "(":
+		t.pos++
+		v, err := t.expr()
+		if err != nil {
+			return v, err
+		}
+		if t.peek() != ")" {
+			return v, fmt.Errorf("missing )")
+		}
+		t.pos++
+		return v, nil
+	case token[0] == '"' || token[0] == '\'':
+		t.pos++
+		var b strings.Builder
+		for i := 1; i < len(token)-1; i++ {
+			if token[i] == '\\' {
+				i++
+			}
+			b.WriteByte(token[i])
+		}
+		return value{str: b.String(), isStr: true}, nil
+	case unicode.IsDigit(rune(token[0])):
+		t.pos++
+		n, err := strconv.ParseInt(token, 10, 64)
+		return value{num: n}, err
+	case token == "rand" || token == "choose":
+		t.pos++
+		return t.call(token)
+	case isIdent(token):
+		t.pos++
+		v, ok := t.vars[token]
+		if !ok {
+			return v, fmt.Errorf("%s isn't set", token)
+		}
+		return v, nil
+	}
+	return value{}, fmt.Errorf("unexpected %q", token)
+}
+
+// call calls the function name with the primaries after it as arguments.
+func (t *template) call(name string) (value, error) {
+	var args []value
+	for t.peek() != "" && t.peek() != ")" && !strings.Contains("+-*/%", t.peek()) {
+		v, err := t.primary()
+		if err != nil {
+			return v, err
+		}
+		args = append(args, v)
+	}
+
+	switch name {
+	case "rand":
+		if len(args) != 2 || args[0].isStr || args[1].isStr {
+			return value{}, fmt.Errorf("rand needs two numbers")
+		}
+		lo, hi := args[0].num, args[1].num
+		if hi < lo {
+			return value{}, fmt.Errorf("rand has an empty range %d to %d", lo, hi)
+		}
+		return value{num: lo + t.rand.Int63n(hi-lo+1)}, nil
+	default:
+		if len(args) == 0 {
+			return value{}, fmt.Errorf("choose needs something to choose from")
+		}
+		return args[t.rand.Intn(len(args))], nil
+	}
+}
+
+// arith applies the operator op to v and w.  Strings can only be added,
+// which joins them.
+func arith(op string, v, w value) (value, error) {
+	if v.isStr || w.isStr {
+		if op != "+" {
+			return value{}, fmt.Errorf("can't use %s with text", op)
+		}
+		return value{str: v.String() + w.String(), isStr: true}, nil
+	}
+	switch op {
+	case "+":
+		return value{num: v.num + w.num}, nil
+	case "-":
+		return value{num: v.num - w.num}, nil
+	case "*":
+		return value{num: v.num * w.num}, nil
+	}
+	if w.num == 0 {
+		return value{}, fmt.Errorf("division by zero")
+	}
+	if op == "/" {
+		return value{num: v.num / w.num}, nil
+	}
+	return value{num: v.num % w.num}, nil
+}
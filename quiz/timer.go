@@ -0,0 +1,321 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errQuestionExpired is returned by readInput when the time for the
+// current question runs out.  The test carries on with the next question.
+var errQuestionExpired = errors.New("quiz: time's up for the question")
+
+// clock is a time limit that can be paused.  Its channel receives when
+// the time is up, so it can be waited on in a select with the input.
+type clock struct {
+	mu      sync.Mutex
+	timer   *time.Timer
+	warning *time.Timer   //Fires warn before the time is up, nil for no warning
+	warn    time.Duration //How long before the time is up the warning fires
+	left    time.Duration //Time left when the clock was last paused or started
+	resumed time.Time     //When the clock last started running
+	paused  bool
+}
+
+// newClock starts a clock that runs out after d, with a warning warn
+// before then if warn is shorter than d.
+func newClock(d, warn time.Duration) *clock {
+	c := &clock{timer: time.NewTimer(d), left: d, resumed: time.Now()}
+	if warn > 0 && warn < d {
+		c.warning, c.warn = time.NewTimer(d-warn), warn
+	}
+	return c
+}
+
+// C returns the channel that receives when the time is up.  A nil clock
+// never runs out.
+func (c *clock) C() <-chan time.Time {
+	if c == nil {
+		return nil
+	}
+	return c.timer.C
+}
+
+// W returns the channel that receives when the time is nearly up.  A nil
+// clock, or one without a warning, never warns.
+func (c *clock) W() <-chan time.Time {
+	if c == nil || c.warning == nil {
+		return nil
+	}
+	return c.warning.C
+}
+
+// remaining returns the time left before the clock runs out, to the
+// second.
+func (c *clock) remaining() time.Duration {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	left := c.left
+	if !c.paused {
+		left -= time.Since(c.resumed)
+	}
+	return max(left, 0).Round(time.Second)
+}
+
+// pause stops the clock until resume is called.
+func (c *clock) pause() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused && c.timer.Stop() {
+		c.left -= time.Since(c.resumed)
+		c.paused = true
+		if c.warning != nil {
+			c.warning.Stop()
+		}
+	}
+}
+
+// resume restarts a paused clock with the time it had left.
+func (c *clock) resume() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		c.paused = false
+		c.resumed = time.Now()
+		c.timer.Reset(c.left)
+		if c.warning != nil && c.left > c.warn {
+			c.warning.Reset(c.left - c.warn)
+		}
+	}
+}
+
+// stop stops the clock for good.
+func (c *clock) stop() {
+	if c != nil {
+		c.timer.Stop()
+		if c.warning != nil {
+			c.warning.Stop()
+		}
+	}
+}
+
+// Pause stops the clock for the test and the current question until
+// Resume is called.  It is safe to call from another goroutine while the
+// test is running, e.g. from a signal handler or a server.
+func (a *Assessment) Pause() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.paused = true
+	a.testClock.pause()
+	a.questionClock.pause()
+}
+
+// Resume restarts the clocks stopped by Pause.
+func (a *Assessment) Resume() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.paused = false
+	a.testClock.resume()
+	a.questionClock.resume()
+}
+
+// startClock starts a clock for the test, or for a question when question
+// is true, replacing the last one.  It starts paused if the test is paused.
+func (a *Assessment) startClock(d time.Duration, question bool) *clock {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	c := newClock(d, a.WarnBefore)
+	if a.paused {
+		c.pause()
+	}
+	if question {
+		a.questionClock.stop()
+		a.questionClock = c
+	} else {
+		a.testClock.stop()
+		a.testClock = c
+	}
+	return c
+}
+
+// stopQuestionClock stops the clock for the last question, for a question
+// with no time limit.
+func (a *Assessment) stopQuestionClock() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.questionClock.stop()
+	a.questionClock = nil
+}
+
+// TimeFor returns the time allowed to answer q: its own TimeLimit if it
+// has one, or else the test's QuestionLimit.  0 means there is no limit.
+func (a *Assessment) TimeFor(q *Question) time.Duration {
+	if q.TimeLimit > 0 {
+		return q.TimeLimit
+	}
+	return a.QuestionLimit
+}
+
+// stopClocks stops the clocks at the end of the test.
+func (a *Assessment) stopClocks() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.testClock.stop()
+	a.questionClock.stop()
+	a.testClock, a.questionClock = nil, nil
+}
+
+// readInput waits for a line of input.  It gives up with ErrTimeExpired
+// when the test's time is up, errQuestionExpired when the question's time
+// is up, or ctx's error when ctx is done.  The user is warned when either
+// time is nearly up, and what they have typed without pressing ENTER is
+// thrown away when it is up.
+func (a *Assessment) readInput(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	test, question := a.testClock.C(), a.questionClock.C()
+	testWarning, questionWarning := a.testClock.W(), a.questionClock.W()
+	a.mu.Unlock()
+
+	for {
+		select {
+		case l := <-a.userInput().lines():
+			return l.text, l.err
+		case <-testWarning:
+			a.warnTime("the test")
+			testWarning = nil
+		case <-questionWarning:
+			a.warnTime("this question")
+			questionWarning = nil
+		case <-test:
+			a.discardTyped()
+			return "", ErrTimeExpired
+		case <-question:
+			a.discardTyped()
+			return "", errQuestionExpired
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// warnTime warns the user that the time for what is nearly up, ringing
+// the bell with Bell.  In a terminal the warning is shown in reverse at
+// the end of the line, leaving the cursor where it was so it doesn't get
+// in the way of the answer being typed.
+func (a *Assessment) warnTime(what string) {
+	out := a.output()
+	if a.Bell {
+		fmt.Fprint(out, "\a")
+	}
+	warning := fmt.Sprintf("%s left for %s", a.WarnBefore, what)
+	if width := a.width(); a.isTerminal() && width > len(warning)+1 {
+		// Save the cursor, move to the column, show the warning in reverse video and restore the cursor
+		fmt.Fprintf(out, "\x1b7\x1b[%dG\x1b[7m%s\x1b[0m\x1b8", width-len(warning)+1, warning)
+		return
+	}
+	fmt.Fprintf(out, "(%s) ", warning)
+}
+
+// readAnswer reads the user's answer to a question.  With a Transcriber
+// an empty answer records a spoken one instead, which the user can accept
+// or correct by typing.  The answer is typed if the recording fails.
+func (a *Assessment) readAnswer(ctx context.Context) (string, error) {
+	answer, err := a.readInput(ctx)
+	if err != nil || a.Transcriber == nil || strings.TrimSpace(answer) != "" {
+		return answer, err
+	}
+
+	out := a.output()
+	fmt.Fprint(out, "Listening... ")
+	heard, err := a.listen(ctx)
+	switch {
+	case errors.Is(err, ErrTimeExpired) || errors.Is(err, errQuestionExpired) || ctx.Err() != nil:
+		return "", err
+	case err != nil:
+		fmt.Fprintf(out, "unable to hear your answer: %v\nType your answer: ", err)
+		return a.readInput(ctx)
+	}
+	fmt.Fprintf(out, "heard %q.  Press ENTER to answer with it or type your answer: ", heard)
+	typed, err := a.readInput(ctx)
+	if err != nil || strings.TrimSpace(typed) != "" {
+		return typed, err
+	}
+	return heard, nil
+}
+
+// listen records a spoken answer with the Transcriber.  Like readInput it
+// gives up when the time runs out or ctx is done.
+func (a *Assessment) listen(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	heard := make(chan line, 1)
+	go func() {
+		text, err := a.Transcriber(ctx)
+		heard <- line{text, err}
+	}()
+
+	a.mu.Lock()
+	test, question := a.testClock.C(), a.questionClock.C()
+	a.mu.Unlock()
+	select {
+	case l := <-heard:
+		return l.text, l.err
+	case <-test:
+		return "", ErrTimeExpired
+	case <-question:
+		return "", errQuestionExpired
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// grade grades answer to q like Grade, but like readInput gives up when
+// the time runs out or ctx is done, cancelling the Grader's ctx so a slow
+// script or model stops.  The Grader is given a copy of q so it doesn't
+// race with the test carrying on without it.
+func (a *Assessment) grade(ctx context.Context, q *Question, answer string) error {
+	q.record(answer)
+	if a.Grader == nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type verdict struct {
+		correct bool
+		err     error
+	}
+	graded := make(chan verdict, 1)
+	c := *q
+	go func() {
+		correct, err := a.Grader(ctx, &c, c.UserAnswer)
+		graded <- verdict{correct, err}
+	}()
+
+	a.mu.Lock()
+	test, question := a.testClock.C(), a.questionClock.C()
+	a.mu.Unlock()
+	select {
+	case v := <-graded:
+		q.Correct = v.correct
+		return v.err
+	case <-test:
+		return ErrTimeExpired
+	case <-question:
+		return errQuestionExpired
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,101 @@
+package quiz
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Translation is a question in another language.  The fields left empty
+// are the same as the question's.
+type Translation struct {
+	QText       string   //Question text
+	Answer      string   //Correct answer
+	Choices     []string //Choices, in the same order as the question's
+	Hint        string   //Hint to help the user answer
+	Explanation string   //Why the answer is right
+}
+
+// Translate returns the question in the language lang, e.g. "fr", and
+// whether it has a translation for it.  A regional language such as
+// "pt-BR" falls back to "pt".  The translated question keeps the
+// question's StableID as its ID, so results in every language are for the
+// same question.  Without a translation the question is returned as it is.
+func (q Question) Translate(lang string) (Question, bool) {
+	t, ok := q.translation(lang)
+	if !ok {
+		return q, false
+	}
+	q.ID = q.StableID()
+	if t.QText != "" {
+		q.QText = t.QText
+	}
+	if t.Answer != "" {
+		q.Answer = t.Answer
+	}
+	if len(t.Choices) > 0 {
+		q.Choices = t.Choices
+	}
+	if t.Hint != "" {
+		q.Hint = t.Hint
+	}
+	if t.Explanation != "" {
+		q.Explanation = t.Explanation
+	}
+	return q, true
+}
+
+// translation returns the question's translation into lang, ignoring case.
+func (q *Question) translation(lang string) (Translation, bool) {
+	base, _, regional := strings.Cut(lang, "-")
+	for _, want := range []string{lang, base} {
+		for code, t := range q.Translations {
+			if strings.EqualFold(code, want) {
+				return t, true
+			}
+		}
+		if !regional {
+			break
+		}
+	}
+	return Translation{}, false
+}
+
+// Languages returns the language codes the questions have translations
+// for, sorted.
+func Languages(questions []Question) []string {
+	var langs []string
+	seen := map[string]bool{}
+	for _, q := range questions {
+		for code := range q.Translations {
+			if !seen[code] {
+				seen[code] = true
+				langs = append(langs, code)
+			}
+		}
+	}
+	slices.Sort(langs)
+	return langs
+}
+
+// translate puts the questions into Language, warning about the ones
+// without a translation, which are asked in the language they are written
+// in.
+func (a *Assessment) translate() {
+	if a.Language == "" {
+		return
+	}
+	missing := 0
+	for i, q := range a.Questions {
+		var ok bool
+		if a.Questions[i], ok = q.Translate(a.Language); !ok {
+			missing++
+		}
+	}
+	switch {
+	case missing == len(a.Questions):
+		fmt.Fprintf(a.output(), "None of the questions in %s have a %q translation, so they are asked as they are written.\n", a.FilePath, a.Language)
+	case missing > 0:
+		fmt.Fprintf(a.output(), "%v of the questions in %s have no %q translation, so they are asked as they are written.\n", missing, a.FilePath, a.Language)
+	}
+}
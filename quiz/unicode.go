@@ -0,0 +1,55 @@
+package quiz
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize returns s as it is compared with an answer, so text typed in
+// different ways that looks the same is the same.  Invalid UTF-8 and the
+// invisible characters in invisible are taken out, and the rest is put in
+// Unicode's NFKC form: letters followed by combining accents, in any order,
+// become the accented letters, Korean jamo become syllables, as macOS and
+// some input methods type them apart, and the full width letters, digits
+// and spaces that Chinese and Japanese input methods type become ordinary
+// ones.  Spaces at either end are trimmed.
+func Normalize(s string) string {
+	if isPlain(s) {
+		return strings.TrimSpace(s)
+	}
+	s = strings.Map(func(r rune) rune {
+		if invisible(r) {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(s, ""))
+	return strings.TrimSpace(norm.NFKC.String(s))
+}
+
+// invisible reports whether r is a character with no width that gets into
+// answers by accident, such as a byte order mark at the start of a file or
+// a zero width space or direction mark pasted with the text.  Zero width
+// joiners and non-joiners are kept, since they change how Persian, Indic
+// and emoji text is written.
+func invisible(r rune) bool {
+	switch {
+	case r == '\u00ad', r == '\u180e', r == '\u200b', r == '\u200e', r == '\u200f', r == '\ufeff':
+		return true
+	case r >= '\u202a' && r <= '\u202e', r >= '\u2060' && r <= '\u2064', r >= '\u2066' && r <= '\u206f':
+		return true
+	}
+	return false
+}
+
+// isPlain reports whether s is all ASCII, which Normalize leaves alone
+// but for trimming it.
+func isPlain(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package quiz
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "  Paris ", "Paris"},
+		{"case is kept", "PARIS", "PARIS"},
+		{"byte order mark", "\ufeffParis", "Paris"},
+		{"zero width space", "Pa\u200bris", "Paris"},
+		{"direction marks", "\u200f\u05e9\u05dc\u05d5\u05dd\u200e", "\u05e9\u05dc\u05d5\u05dd"},
+		{"soft hyphen", "Mit\u00adtel", "Mittel"},
+		{"invalid UTF-8", "caf\xff\u00e9", "caf\u00e9"},
+		{"combining accent", "cafe\u0301", "caf\u00e9"},
+		{"combining marks in canonical order", "e\u0323\u0302", "\u1ec7"},
+		{"combining marks in the other order", "e\u0302\u0323", "\u1ec7"},
+		{"kana voicing mark", "\u304b\u3099", "\u304c"},
+		{"full width digits", "\uff11\uff10", "10"},
+		{"full width letters", "\uff21\uff42\uff43", "Abc"},
+		{"ideographic space", "\u6771\u3000\u4eac", "\u6771 \u4eac"},
+		{"Korean jamo", "\u1112\u1161\u11ab", "\ud55c"},
+		{"zero width non-joiner is kept", "\u0645\u06cc\u200c\u062e\u0648\u0627\u0647\u0645", "\u0645\u06cc\u200c\u062e\u0648\u0627\u0647\u0645"},
+		{"zero width joiner is kept", "\U0001f469\u200d\U0001f4bb", "\U0001f469\u200d\U0001f4bb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%+q) = %+q, want %+q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCorrect(t *testing.T) {
+	tests := []struct {
+		answer, typed string
+		want          bool
+	}{
+		{"caf\u00e9", "cafe\u0301", true},
+		{"\ufeffcaf\u00e9", " caf\u00e9\n", true},
+		{"\u1ec7", "e\u0302\u0323", true},
+		{"10", "\uff11\uff10", true},
+		{"\ud55c\uad6d", "\u1112\u1161\u11ab\u1100\u116e\u11a8", true},
+		{"Paris", "paris", false},
+		{"\u0645\u06cc\u200c\u062e\u0648\u0627\u0647\u0645", "\u0645\u06cc\u062e\u0648\u0627\u0647\u0645", false},
+	}
+	for _, tt := range tests {
+		q := Question{QText: "?", Answer: tt.answer}
+		if got := q.IsCorrect(tt.typed); got != tt.want {
+			t.Errorf("answer %+q: IsCorrect(%+q) = %v, want %v", tt.answer, tt.typed, got, tt.want)
+		}
+	}
+}
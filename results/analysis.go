@@ -0,0 +1,178 @@
+package results
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// Item is the analysis of how one question has been answered across tests.
+type Item struct {
+	ID             string   //Stable ID of the question
+	Question       string   //Text of the question
+	Answer         string   //Correct answer
+	Attempts       int      //Number of times the question was answered
+	Difficulty     float64  //Fraction of attempts that were correct, from 0 for the hardest to 1
+	Discrimination float64  //Correlation between answering correctly and the rest of the test's score, NaN if it can't be worked out
+	Flags          []string //Reasons the question may be broken or ambiguous
+}
+
+// Thresholds for flagging questions in ItemAnalysis.
+const (
+	TooHard          = 0.2  //Questions fewer than this fraction get right may have the wrong answer
+	TooEasy          = 0.95 //Questions more than this fraction get right don't tell people apart
+	LowDiscriminator = 0.2  //Questions with lower discrimination don't track how well people know the topic
+)
+
+// ItemAnalysis works out the difficulty and discrimination of each question
+// answered in results, and flags the questions that may be broken or
+// ambiguous.  Questions answered fewer than minAttempts times are analysed
+// but not flagged, since a few answers say little.  Items are returned in
+// order of discrimination, lowest first, so the most suspect come first.
+//
+// Discrimination is the corrected item-total correlation: the correlation
+// between getting the question right and the fraction of the other
+// questions in the same test that were right.
+func ItemAnalysis(results []Result, minAttempts int) []Item {
+	type sample struct{ item, rest []float64 }
+	samples := make(map[string]*sample)
+	items := make(map[string]*Item)
+	var order []string
+
+	for _, r := range results {
+		for _, a := range r.Answers {
+			// Questions are matched by ID so edits and reordering don't split
+			// their history, except in results saved before there were IDs
+			key := a.ID
+			if key == "" {
+				key = a.Question
+			}
+			it, ok := items[key]
+			if !ok {
+				it = &Item{ID: a.ID, Question: a.Question, Answer: a.Answer}
+				items[key], samples[key] = it, &sample{}
+				order = append(order, key)
+			}
+			// The latest wording of the question is shown
+			it.Question, it.Answer = a.Question, a.Answer
+			it.Attempts++
+
+			x := 0.0
+			if a.Correct {
+				x = 1
+				it.Difficulty++
+			}
+			if others := len(r.Answers) - 1; others > 0 {
+				s := samples[key]
+				s.item = append(s.item, x)
+				s.rest = append(s.rest, (float64(r.Correct)-x)/float64(others))
+			}
+		}
+	}
+
+	analysis := make([]Item, 0, len(order))
+	for _, key := range order {
+		it := items[key]
+		it.Difficulty /= float64(it.Attempts)
+		it.Discrimination = correlation(samples[key].item, samples[key].rest)
+
+		if it.Attempts >= minAttempts {
+			switch {
+			case it.Difficulty < TooHard:
+				it.Flags = append(it.Flags, "very few get it right, check the answer")
+			case it.Difficulty > TooEasy:
+				it.Flags = append(it.Flags, "almost everyone gets it right")
+			}
+			switch {
+			case it.Discrimination < 0:
+				it.Flags = append(it.Flags, "people who do well overall get it wrong more, it may be ambiguous or have the wrong answer")
+			case it.Discrimination < LowDiscriminator:
+				it.Flags = append(it.Flags, "it doesn't tell apart people who do well and badly")
+			}
+		}
+		analysis = append(analysis, *it)
+	}
+
+	sort.SliceStable(analysis, func(i, j int) bool {
+		a, b := analysis[i].Discrimination, analysis[j].Discrimination
+		if math.IsNaN(a) || math.IsNaN(b) {
+			return !math.IsNaN(a) && math.IsNaN(b)
+		}
+		return a < b
+	})
+	return analysis
+}
+
+// correlation returns the Pearson correlation of x and y, or NaN if either
+// doesn't vary.
+func correlation(x, y []float64) float64 {
+	n := float64(len(x))
+	if n < 2 {
+		return math.NaN()
+	}
+	var sx, sy float64
+	for i := range x {
+		sx += x[i]
+		sy += y[i]
+	}
+	mx, my := sx/n, sy/n
+
+	var cov, vx, vy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return math.NaN()
+	}
+	return cov / math.Sqrt(vx*vy)
+}
+
+// MistakeWeights returns the weights for quiz.WeightedSample that favour
+// the questions of the question file bank that name has got wrong most
+// often in results, and those they have gone longest without seeing, so
+// practice goes where it is needed most.  Questions they have never seen
+// count as not seen for a month.
+func MistakeWeights(results []Result, name, bank string, now time.Time) func(quiz.Question) float64 {
+	type history struct {
+		right, wrong int
+		seen         time.Time
+	}
+	seen := make(map[string]*history)
+	for _, r := range results {
+		if r.Bank != BankName(bank) || !strings.EqualFold(r.Name, name) {
+			continue
+		}
+		for _, a := range r.Answers {
+			h := seen[a.ID]
+			if h == nil {
+				h = &history{}
+				seen[a.ID] = h
+			}
+			if a.Correct {
+				h.right++
+			} else {
+				h.wrong++
+			}
+			if r.Finished.After(h.seen) {
+				h.seen = r.Finished
+			}
+		}
+	}
+
+	const month = 30
+	return func(q quiz.Question) float64 {
+		h := seen[q.StableID()]
+		if h == nil {
+			return 1 + month
+		}
+		days := math.Min(now.Sub(h.seen).Hours()/24, month)
+		// Each mistake counts for more than a right answer makes up for
+		return (1 + 2*float64(h.wrong)) / (1 + float64(h.right)) * (1 + days)
+	}
+}
@@ -0,0 +1,64 @@
+package results
+
+import (
+	"fmt"
+	"math"
+)
+
+// Thresholds for Suspicions.  A question takes at least readSeconds plus
+// secondsPerChar for each character of its text to read, and answer times
+// whose standard deviation is less than evenness of their mean are too
+// even for someone thinking about each question.
+const (
+	readSeconds    = 1.0
+	secondsPerChar = 0.02
+	evenness       = 0.15
+	minEvenAnswers = 5
+)
+
+// Suspicions returns the reasons the answers of an exam section may not
+// have been the candidate's own, for the instructor to look into, or nil
+// if there are none or the answer times weren't recorded.  Correct
+// answers given faster than the question could be read, and answer times
+// that hardly vary, as when answers are typed in from a list, are
+// suspicious.
+func Suspicions(r Result) []string {
+	var reasons []string
+	fast, firstFast := 0, 0
+	var times []float64
+	for i, a := range r.Answers {
+		if a.Seconds == 0 || a.UserAnswer == "" {
+			continue
+		}
+		times = append(times, a.Seconds)
+		if a.Correct && a.Seconds < readSeconds+secondsPerChar*float64(len([]rune(a.Question))) {
+			if fast == 0 {
+				firstFast = i + 1
+			}
+			fast++
+		}
+	}
+	switch {
+	case fast == 1:
+		reasons = append(reasons, fmt.Sprintf("question %v was answered correctly faster than it could be read", firstFast))
+	case fast > 1:
+		reasons = append(reasons, fmt.Sprintf("%v questions were answered correctly faster than they could be read, the first was question %v", fast, firstFast))
+	}
+
+	if len(times) >= minEvenAnswers {
+		mean := 0.0
+		for _, t := range times {
+			mean += t
+		}
+		mean /= float64(len(times))
+		variance := 0.0
+		for _, t := range times {
+			variance += (t - mean) * (t - mean)
+		}
+		sd := math.Sqrt(variance / float64(len(times)))
+		if sd < evenness*mean {
+			reasons = append(reasons, fmt.Sprintf("every answer took about as long, %.1fs ± %.1fs", mean, sd))
+		}
+	}
+	return reasons
+}
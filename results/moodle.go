@@ -0,0 +1,51 @@
+package results
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// moodleResults is the XML file Moodle's gradebook imports grades from.
+type moodleResults struct {
+	XMLName xml.Name       `xml:"results"`
+	Results []moodleResult `xml:"result"`
+}
+
+type moodleResult struct {
+	State      string `xml:"state"`
+	Assignment string `xml:"assignment"`
+	Student    string `xml:"student"`
+	Score      string `xml:"score"`
+	Feedback   string `xml:"feedback,omitempty"`
+}
+
+// WriteMoodleXML writes results as the XML that Moodle's gradebook
+// imports with "Import grades > XML file".  assignment is the ID number of
+// the grade item in Moodle and each result's Name is the student's ID
+// number.  The scores are percentages, so the grade item's maximum should
+// be 100.
+func WriteMoodleXML(w io.Writer, assignment string, results []Result) error {
+	doc := moodleResults{}
+	for _, r := range results {
+		doc.Results = append(doc.Results, moodleResult{
+			State:      "new",
+			Assignment: assignment,
+			Student:    r.Name,
+			Score:      strconv.FormatFloat(r.Score, 'f', 2, 64),
+			Feedback:   fmt.Sprintf("%v of %v questions correct", r.Correct, r.Total),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,273 @@
+// Package results keeps a history of finished tests: who took them, which
+// question bank they were from and how each question was answered.  The
+// history is a file of JSON lines, one for each test, which is appended to
+// so several quiz processes can share it.
+package results
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// Answer is how one question of a test was answered.
+type Answer struct {
+	ID         string  `json:"id,omitempty"`       //Stable ID of the question, see quiz.Question.StableID
+	Question   string  `json:"question"`           //Text of the question
+	Answer     string  `json:"answer"`             //Correct answer
+	UserAnswer string  `json:"user_answer"`        //Answer given, empty if the question ran out of time
+	Correct    bool    `json:"correct"`            //Whether the answer was right
+	Category   string  `json:"category,omitempty"` //Category of the question, if any
+	Seconds    float64 `json:"seconds,omitempty"`  //Seconds taken to answer, only recorded for exams
+}
+
+// Result is the record of one finished test.
+type Result struct {
+	Name      string    `json:"name"`            //Name of the user who took the test
+	Bank      string    `json:"bank"`            //Absolute path or URL of the question file
+	Started   time.Time `json:"started"`         //When the test started
+	Finished  time.Time `json:"finished"`        //When the test ended
+	Correct   int       `json:"correct"`         //Number of questions answered correctly
+	Incorrect int       `json:"incorrect"`       //Number of questions answered incorrectly
+	Total     int       `json:"total"`           //Number of questions in the test, including any not answered
+	Score     float64   `json:"score"`           //Percentage score, from the test's Scorer if it has one
+	Lives     int       `json:"lives,omitempty"` //Wrong answers that ended the test in survival mode, 0 otherwise
+	Daily     string    `json:"daily,omitempty"` //Date of the daily quiz, e.g. "2026-10-15", empty for other tests
+	Exam      string    `json:"exam,omitempty"`  //Title of the exam the test was a section of, empty for other tests
+	Answers   []Answer  `json:"answers"`         //The questions that were answered, in the order they were asked
+}
+
+// DefaultPath returns the results file used when none is given,
+// $XDG_DATA_HOME/quiz/results.jsonl or ~/.local/share/quiz/results.jsonl.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "quiz", "results.jsonl")
+}
+
+// BankName returns the name a question file is recorded under: the
+// absolute path of a file, or a URL as it is.
+func BankName(source string) string {
+	if u, err := url.Parse(source); err == nil && len(u.Scheme) > 1 {
+		return source
+	}
+	if abs, err := filepath.Abs(source); err == nil {
+		return abs
+	}
+	return source
+}
+
+// FromAssessment returns the result of a finished test.
+func FromAssessment(a *quiz.Assessment) Result {
+	r := Result{
+		Name:      a.Name,
+		Bank:      BankName(a.FilePath),
+		Started:   a.TimeStart,
+		Finished:  time.Now(),
+		Correct:   a.TotalCorrect,
+		Incorrect: a.TotalIncorrect,
+		Total:     a.Total(),
+		Lives:     a.Lives,
+	}
+	r.Score, _ = a.Score()
+	// The questions are answered in order, so the answered ones come first
+	for _, q := range a.Questions[:a.TotalCorrect+a.TotalIncorrect] {
+		r.Answers = append(r.Answers, Answer{ID: q.StableID(), Question: q.QText, Answer: q.Answer, UserAnswer: q.UserAnswer, Correct: q.Correct, Category: q.Category})
+	}
+	return r
+}
+
+// Append adds r to the results file at path, creating it if needed.
+func Append(path string, r Result) (err error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	// One write per result, so results appended at the same time don't interleave
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// Read returns the results in the file at path, oldest first.  A missing
+// file has no results.
+func Read(path string) ([]Result, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var results []Result
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var r Result
+		if err = json.Unmarshal(line, &r); err != nil {
+			return results, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		results = append(results, r)
+	}
+	return results, scanner.Err()
+}
+
+// Record registers a handler that appends the result of a to the results
+// file at path when the test finishes.  Tests stopped before any question
+// was answered aren't recorded, and neither are the rounds of a test
+// played again.
+func Record(a *quiz.Assessment, path string) {
+	a.OnSubmit(func(a *quiz.Assessment) {
+		if a.TotalCorrect+a.TotalIncorrect == 0 {
+			return
+		}
+		if err := Append(path, FromAssessment(a)); err != nil {
+			fmt.Fprintln(a.Out, "Unable to save your results:", err)
+		}
+	})
+}
+
+// RecordExam is like Record for a section of the exam called exam, and
+// also records how long each question took to answer, so Suspicions can
+// check the answers were the candidate's own.
+func RecordExam(a *quiz.Assessment, path, exam string) {
+	a.OnSubmit(func(a *quiz.Assessment) {
+		if a.TotalCorrect+a.TotalIncorrect == 0 {
+			return
+		}
+		r := FromAssessment(a)
+		r.Exam = exam
+		for i := range r.Answers {
+			r.Answers[i].Seconds = a.Questions[i].AnswerTime.Seconds()
+		}
+		if err := Append(path, r); err != nil {
+			fmt.Fprintln(a.Out, "Unable to save your results:", err)
+		}
+	})
+}
+
+// ForBanks returns the results of tests of the question files banks, or
+// all the results if no banks are given.
+func ForBanks(results []Result, banks []string) []Result {
+	if len(banks) == 0 {
+		return results
+	}
+	var picked []Result
+	for _, r := range results {
+		for _, b := range banks {
+			if r.Bank == BankName(b) {
+				picked = append(picked, r)
+				break
+			}
+		}
+	}
+	return picked
+}
+
+// BestRun returns the survival run of the question file bank with lives
+// that got the most questions right, the earliest if several did, and
+// false if there isn't one.
+func BestRun(results []Result, bank string, lives int) (Result, bool) {
+	var best Result
+	found := false
+	for _, r := range results {
+		if r.Lives == lives && r.Bank == BankName(bank) && (!found || r.Correct > best.Correct) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+// DailyResult returns name's result for the daily quiz of the question
+// file bank on date, and false if they haven't played it.
+func DailyResult(results []Result, name, bank, date string) (Result, bool) {
+	for _, r := range results {
+		if r.Daily == date && r.Bank == BankName(bank) && strings.EqualFold(r.Name, name) {
+			return r, true
+		}
+	}
+	return Result{}, false
+}
+
+// Streak returns the number of days in a row name has played the daily
+// quiz of the question file bank, up to today.  A streak isn't broken
+// until a whole day is missed, so it counts from yesterday if they
+// haven't played today yet.
+func Streak(results []Result, name, bank string, today time.Time) int {
+	played := make(map[string]bool)
+	for _, r := range results {
+		if r.Daily != "" && r.Bank == BankName(bank) && strings.EqualFold(r.Name, name) {
+			played[r.Daily] = true
+		}
+	}
+	day := today
+	if !played[day.Format(time.DateOnly)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	streak := 0
+	for played[day.Format(time.DateOnly)] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+// Attempts are the ways PickAttempt can pick one result for each person.
+var Attempts = []string{"latest", "best", "first"}
+
+// PickAttempt returns one result for each name in results, which are
+// oldest first: the "latest", "best" or "first" attempt.  The results are
+// in the order the names first appear.
+func PickAttempt(results []Result, attempt string) ([]Result, error) {
+	if !slices.Contains(Attempts, attempt) {
+		return nil, fmt.Errorf("unknown attempt %q, it should be one of %s", attempt, strings.Join(Attempts, ", "))
+	}
+	picked := make(map[string]int)
+	var out []Result
+	for _, r := range results {
+		i, ok := picked[r.Name]
+		switch {
+		case !ok:
+			picked[r.Name] = len(out)
+			out = append(out, r)
+		case attempt == "latest", attempt == "best" && r.Score > out[i].Score:
+			out[i] = r
+		}
+	}
+	return out, nil
+}
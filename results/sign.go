@@ -0,0 +1,153 @@
+package results
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrBadSignature is returned by Verify when a signed result was changed
+// after it was signed, or wasn't signed with the key.
+var ErrBadSignature = errors.New("the signature doesn't match, the result was changed or signed with another key")
+
+// Signed is a result signed with an Ed25519 key, so an instructor with the
+// public key can check it wasn't changed.  The signature is of Result as
+// compact JSON, so it still matches if the file is indented differently.
+type Signed struct {
+	Result    json.RawMessage `json:"result"`    //The Result as JSON
+	Signature []byte          `json:"signature"` //Ed25519 signature of Result
+}
+
+// Sign returns r signed with key.
+func Sign(r Result, key ed25519.PrivateKey) (Signed, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return Signed{}, err
+	}
+	return Signed{Result: data, Signature: ed25519.Sign(key, data)}, nil
+}
+
+// Verify checks s was signed with the private key of key and returns the
+// result, or ErrBadSignature if it wasn't.
+func (s Signed) Verify(key ed25519.PublicKey) (Result, error) {
+	var r Result
+	var data bytes.Buffer
+	if err := json.Compact(&data, s.Result); err != nil {
+		return r, err
+	}
+	if !ed25519.Verify(key, data.Bytes(), s.Signature) {
+		return r, ErrBadSignature
+	}
+	err := json.Unmarshal(data.Bytes(), &r)
+	return r, err
+}
+
+// WriteSigned writes r signed with key to the file at path.
+func WriteSigned(path string, r Result, key ed25519.PrivateKey) error {
+	s, err := Sign(r, key)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// ReadSigned reads a signed result from the file at path.
+func ReadSigned(path string) (Signed, error) {
+	var s Signed
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err = json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(s.Result) == 0 || len(s.Signature) == 0 {
+		return s, fmt.Errorf("%s isn't a signed result", path)
+	}
+	return s, nil
+}
+
+// GenerateSigningKey generates a new Ed25519 key and saves it in the PEM
+// file at path, with its public key in path with ".pub" added for checking
+// the results it signs.  An existing key isn't overwritten, since the
+// results it signed could then no longer be checked.
+func GenerateSigningKey(path string) error {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	priv, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	public, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: priv})); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path+".pub", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}), 0644)
+}
+
+// LoadSigningKey loads the Ed25519 private key in the PEM file at path,
+// made by GenerateSigningKey.  A missing key is an error rather than being
+// generated, since whoever generates the key can sign any result with it.
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s isn't a PEM file", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s isn't an Ed25519 key", path)
+	}
+	return key, nil
+}
+
+// LoadPublicKey loads the Ed25519 public key in the PEM file at path.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s isn't a PEM file", path)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	key, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s isn't an Ed25519 key", path)
+	}
+	return key, nil
+}
@@ -0,0 +1,137 @@
+package results
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// keyPair is a signing key and its public key.
+type keyPair struct {
+	Private ed25519.PrivateKey
+	Public  ed25519.PublicKey
+}
+
+// testKeys generates a signing key in dir and loads it and its public key.
+func testKeys(t *testing.T, dir string) (string, keyPair) {
+	t.Helper()
+	path := filepath.Join(dir, "sign.pem")
+	if err := GenerateSigningKey(path); err != nil {
+		t.Fatal(err)
+	}
+	key, err := LoadSigningKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := LoadPublicKey(path + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, keyPair{key, pub}
+}
+
+func TestSigned(t *testing.T) {
+	_, keys := testKeys(t, t.TempDir())
+	_, other := testKeys(t, t.TempDir())
+	r := Result{Name: "Alice", Bank: "/quizzes/unit3.json", Finished: time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC), Correct: 3, Incorrect: 1, Total: 4, Score: 75}
+
+	tests := []struct {
+		name    string
+		change  func(s *Signed)
+		key     keyPair
+		wantErr error
+	}{
+		{name: "valid", key: keys},
+		{name: "indented differently", key: keys, change: func(s *Signed) {
+			var b bytes.Buffer
+			json.Indent(&b, s.Result, "", "\t")
+			s.Result = b.Bytes()
+		}},
+		{name: "score changed", key: keys, change: func(s *Signed) {
+			s.Result = bytes.Replace(s.Result, []byte(`"score":75`), []byte(`"score":100`), 1)
+		}, wantErr: ErrBadSignature},
+		{name: "signature changed", key: keys, change: func(s *Signed) { s.Signature[0] ^= 1 }, wantErr: ErrBadSignature},
+		{name: "another key", key: other, wantErr: ErrBadSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Sign(r, keys.Private)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.change != nil {
+				tt.change(&s)
+			}
+			got, err := s.Verify(tt.key.Public)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (got.Name != r.Name || got.Score != r.Score || !got.Finished.Equal(r.Finished)) {
+				t.Errorf("Verify() = %+v, want %+v", got, r)
+			}
+		})
+	}
+}
+
+func TestWriteSigned(t *testing.T) {
+	dir := t.TempDir()
+	_, keys := testKeys(t, dir)
+	path := filepath.Join(dir, "alice.json")
+	if err := WriteSigned(path, Result{Name: "Alice", Correct: 1, Total: 1, Score: 100}, keys.Private); err != nil {
+		t.Fatal(err)
+	}
+	s, err := ReadSigned(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, err := s.Verify(keys.Public); err != nil || r.Name != "Alice" {
+		t.Errorf("Verify() = %+v, %v", r, err)
+	}
+
+	if err = os.WriteFile(path, []byte(`{"result": {"name": "Alice"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ReadSigned(path); err == nil {
+		t.Error("ReadSigned() read a result with no signature")
+	}
+}
+
+func TestSigningKey(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadSigningKey(filepath.Join(dir, "missing.pem")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadSigningKey() of a missing key = %v, want a not exist error", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "missing.pem")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("LoadSigningKey() made a key for a missing one")
+	}
+
+	path, _ := testKeys(t, dir)
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = GenerateSigningKey(path); err == nil {
+		t.Error("GenerateSigningKey() overwrote an existing key")
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(before, after) {
+		t.Error("the existing key was changed")
+	}
+	if _, err = LoadSigningKey(path + ".pub"); err == nil {
+		t.Error("LoadSigningKey() loaded a public key as a private one")
+	}
+	if _, err = LoadPublicKey(path); err == nil {
+		t.Error("LoadPublicKey() loaded a private key as a public one")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("the private key has the permissions %v, want -rw-------", info.Mode().Perm())
+	}
+}
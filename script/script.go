@@ -0,0 +1,262 @@
+// Package script lets a user provided Starlark script change how the quiz
+// works without recompiling it.  A script can define any of these
+// functions:
+//
+//	grade(question, answer, user_answer)       return True if user_answer is correct
+//	generate(n)                                return the n'th question, starting at 1, as dict(question=..., answer=..., choices=[...]) or None when there are no more
+//	score(correct, incorrect, total, seconds)  return the percentage score for the test
+//
+// Besides the Starlark built-ins, scripts can use randint(a, b), choice(list)
+// and the math module.  Global variables are frozen once the script has
+// loaded, so functions should work from their arguments rather than keep state.
+//
+// A call to a function that runs for more than maxSteps steps fails, so a
+// script stuck in a loop can't hang the test, and grade is stopped when
+// the time for the question or the test runs out.
+package script
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+	"go.starlark.net/lib/math"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// maxSteps is the most steps the script can take to load or in a call to
+// one of its functions.  It is far more than any reasonable function
+// needs, and a few seconds' work at most.
+const maxSteps = 10_000_000
+
+// Script is a loaded Starlark script.
+type Script struct {
+	Path string //Path to the script
+
+	mu      sync.Mutex //Calls share rand, and the script's values aren't safe for concurrent use
+	globals starlark.StringDict
+	rand    *rand.Rand
+}
+
+// Load runs the script at path so its functions can be called.
+func Load(path string) (*Script, error) {
+	s := &Script{
+		Path: path,
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	predeclared := starlark.StringDict{
+		"randint": starlark.NewBuiltin("randint", s.randint),
+		"choice":  starlark.NewBuiltin("choice", s.choice),
+		"math":    math.Module,
+	}
+	opts := &syntax.FileOptions{Set: true, While: true, TopLevelControl: true, GlobalReassign: true}
+
+	globals, err := starlark.ExecFileOptions(opts, s.newThread(), path, nil, predeclared)
+	if err != nil {
+		return nil, fmt.Errorf("unable to run script %s: %w", path, err)
+	}
+	s.globals = globals
+	return s, nil
+}
+
+// function returns the script's function called name, or nil if it
+// doesn't define one.
+func (s *Script) function(name string) starlark.Callable {
+	fn, _ := s.globals[name].(starlark.Callable)
+	return fn
+}
+
+// newThread returns a thread to run the script on that stops after
+// maxSteps steps.  Each call gets its own, since the steps add up over the
+// life of a thread and a cancelled thread stays cancelled.
+func (s *Script) newThread() *starlark.Thread {
+	thread := &starlark.Thread{Name: s.Path}
+	thread.SetMaxExecutionSteps(maxSteps)
+	return thread
+}
+
+// call calls the script's function called name, cancelling it if ctx is
+// done before it returns.
+func (s *Script) call(ctx context.Context, name string, args ...starlark.Value) (starlark.Value, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	thread := s.newThread()
+	stop := context.AfterFunc(ctx, func() { thread.Cancel(ctx.Err().Error()) })
+	defer stop()
+	v, err := starlark.Call(thread, s.function(name), args, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s(): %w", s.Path, name, err)
+	}
+	return v, nil
+}
+
+// Grader returns a quiz.Grader that calls the script's grade function,
+// or nil if the script doesn't define one.
+func (s *Script) Grader() quiz.Grader {
+	if s.function("grade") == nil {
+		return nil
+	}
+	return func(ctx context.Context, q *quiz.Question, answer string) (bool, error) {
+		v, err := s.call(ctx, "grade", starlark.String(q.QText), starlark.String(q.Answer), starlark.String(answer))
+		if err != nil {
+			return false, err
+		}
+		return bool(v.Truth()), nil
+	}
+}
+
+// Scorer returns a quiz.Scorer that calls the script's score function,
+// or nil if the script doesn't define one.
+func (s *Script) Scorer() quiz.Scorer {
+	if s.function("score") == nil {
+		return nil
+	}
+	return func(a *quiz.Assessment) (float64, error) {
+		v, err := s.call(context.Background(), "score",
+			starlark.MakeInt(a.TotalCorrect),
+			starlark.MakeInt(a.TotalIncorrect),
+			starlark.MakeInt(a.Total()),
+			starlark.Float(time.Since(a.TimeStart).Seconds()))
+		if err != nil {
+			return 0, err
+		}
+		f, ok := starlark.AsFloat(v)
+		if !ok {
+			return 0, fmt.Errorf("%s: score() returned %s, not a number", s.Path, v.Type())
+		}
+		return f, nil
+	}
+}
+
+// Seed makes the script's randint and choice return the same values
+// every time it is run with the same seed.
+func (s *Script) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rand = rand.New(rand.NewSource(seed))
+}
+
+// Source returns a quiz.QuestionSource that calls the script's generate
+// function for each question, or nil if the script doesn't define one.
+func (s *Script) Source() quiz.QuestionSource {
+	if s.function("generate") == nil {
+		return nil
+	}
+	return &source{script: s}
+}
+
+// source generates questions ahead by one so HasNext can tell when
+// generate returns None.  It is shared by every session of the test.
+type source struct {
+	mu     sync.Mutex
+	script *Script
+	count  int //Number of questions generated so far
+	next   *quiz.Question
+	err    error
+	done   bool
+}
+
+func (g *source) HasNext() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.hasNext()
+}
+
+func (g *source) hasNext() bool {
+	if g.next == nil && g.err == nil && !g.done {
+		g.count++
+		g.next, g.err = g.script.generate(g.count)
+		g.done = g.next == nil && g.err == nil
+	}
+	return g.next != nil || g.err != nil
+}
+
+func (g *source) Next() (quiz.Question, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.hasNext() {
+		return quiz.Question{}, fmt.Errorf("%s: generate() has no more questions", g.script.Path)
+	}
+	q, err := g.next, g.err
+	g.next, g.err = nil, nil
+	if err != nil {
+		return quiz.Question{}, err
+	}
+	return *q, nil
+}
+
+// generate calls the script's generate function for the n'th question and
+// converts the dict it returns into a question.  It returns nil when
+// generate returns None.
+func (s *Script) generate(n int) (*quiz.Question, error) {
+	v, err := s.call(context.Background(), "generate", starlark.MakeInt(n))
+	if err != nil || v == starlark.None {
+		return nil, err
+	}
+	d, ok := v.(*starlark.Dict)
+	if !ok {
+		return nil, fmt.Errorf("%s: generate() returned %s, not a dict", s.Path, v.Type())
+	}
+
+	field := func(name string) string {
+		v, found, _ := d.Get(starlark.String(name))
+		if !found || v == starlark.None {
+			return ""
+		}
+		if str, ok := starlark.AsString(v); ok {
+			return str
+		}
+		return v.String()
+	}
+	q := &quiz.Question{QText: field("question"), Answer: field("answer")}
+	if q.QText == "" {
+		return nil, fmt.Errorf("%s: generate() returned a question without any text", s.Path)
+	}
+
+	if choices, found, _ := d.Get(starlark.String("choices")); found && choices != starlark.None {
+		iter := starlark.Iterate(choices)
+		if iter == nil {
+			return nil, fmt.Errorf("%s: generate() returned choices that aren't a list", s.Path)
+		}
+		defer iter.Done()
+		var c starlark.Value
+		for iter.Next(&c) {
+			if str, ok := starlark.AsString(c); ok {
+				q.Choices = append(q.Choices, str)
+			} else {
+				q.Choices = append(q.Choices, c.String())
+			}
+		}
+	}
+	return q, nil
+}
+
+// randint(a, b) returns a random integer N such that a <= N <= b.
+func (s *Script) randint(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var lo, hi int
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &lo, &hi); err != nil {
+		return nil, err
+	}
+	if hi < lo {
+		return nil, fmt.Errorf("%s: empty range %d to %d", b.Name(), lo, hi)
+	}
+	return starlark.MakeInt(lo + s.rand.Intn(hi-lo+1)), nil
+}
+
+// choice(seq) returns a random element of seq.
+func (s *Script) choice(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var seq starlark.Indexable
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &seq); err != nil {
+		return nil, err
+	}
+	if seq.Len() == 0 {
+		return nil, fmt.Errorf("%s: empty sequence", b.Name())
+	}
+	return seq.Index(s.rand.Intn(seq.Len())), nil
+}
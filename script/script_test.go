@@ -0,0 +1,209 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// load writes src to a script and loads it.
+func load(t *testing.T, src string) *Script {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "quiz.star")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestGrader(t *testing.T) {
+	s := load(t, `
+def grade(question, answer, user_answer):
+    return user_answer.lower() == answer.lower()
+`)
+	grade := s.Grader()
+	if grade == nil {
+		t.Fatal("Grader() = nil for a script with grade()")
+	}
+	q := &quiz.Question{QText: "Capital of France?", Answer: "Paris"}
+	tests := []struct {
+		answer string
+		want   bool
+	}{
+		{"Paris", true},
+		{"PARIS", true},
+		{"Rome", false},
+	}
+	for _, tt := range tests {
+		got, err := grade(context.Background(), q, tt.answer)
+		if err != nil || got != tt.want {
+			t.Errorf("grade(%q) = %v, %v, want %v", tt.answer, got, err, tt.want)
+		}
+	}
+
+	if load(t, "x = 1").Grader() != nil {
+		t.Error("Grader() isn't nil for a script without grade()")
+	}
+}
+
+func TestGraderStopped(t *testing.T) {
+	s := load(t, `
+def grade(question, answer, user_answer):
+    while True:
+        pass
+`)
+	grade := s.Grader()
+	q := &quiz.Question{QText: "1+1", Answer: "2"}
+
+	// A function that never returns fails when it runs out of steps
+	done := make(chan error)
+	go func() {
+		_, err := grade(context.Background(), q, "2")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "too many steps") {
+			t.Errorf("grade() = %v, want too many steps", err)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("grade() didn't stop when it ran out of steps")
+	}
+
+	// And stops as soon as it is cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := grade(ctx, q, "2"); err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("grade() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("grade() took %s to stop after it was cancelled", elapsed)
+	}
+}
+
+func TestSource(t *testing.T) {
+	s := load(t, `
+def generate(n):
+    if n > 2:
+        return None
+    return dict(question="%d+%d" % (n, n), answer=2*n, choices=[2*n, 2*n+1])
+`)
+	source := s.Source()
+	if source == nil {
+		t.Fatal("Source() = nil for a script with generate()")
+	}
+	var got []quiz.Question
+	for source.HasNext() {
+		q, err := source.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, q)
+	}
+	if len(got) != 2 {
+		t.Fatalf("generated %v questions, want 2 before None", len(got))
+	}
+	if q := got[1]; q.QText != "2+2" || q.Answer != "4" || strings.Join(q.Choices, ",") != "4,5" {
+		t.Errorf("the second question is %+v", q)
+	}
+	if _, err := source.Next(); err == nil {
+		t.Error("Next() after None didn't fail")
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{name: "not a dict", src: "def generate(n):\n    return [n]\n", wantErr: "returned list, not a dict"},
+		{name: "no text", src: "def generate(n):\n    return dict(answer=n)\n", wantErr: "without any text"},
+		{name: "choices aren't a list", src: "def generate(n):\n    return dict(question='q', answer=1, choices=1)\n", wantErr: "choices that aren't a list"},
+		{name: "fails", src: "def generate(n):\n    fail('no questions')\n", wantErr: "no questions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := load(t, tt.src).Source()
+			if !source.HasNext() {
+				t.Fatal("HasNext() = false for a failed generate()")
+			}
+			if _, err := source.Next(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Next() = %v, want an error with %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSeed(t *testing.T) {
+	src := `
+def generate(n):
+    a = randint(1, 1000)
+    return dict(question="%d" % a, answer=choice(["x", "y", "z"]))
+`
+	questions := func(seed int64) []string {
+		s := load(t, src)
+		s.Seed(seed)
+		source := s.Source()
+		var texts []string
+		for range 5 {
+			q, err := source.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			texts = append(texts, q.QText+"="+q.Answer)
+		}
+		return texts
+	}
+	first, again, other := questions(1), questions(1), questions(2)
+	if strings.Join(first, " ") != strings.Join(again, " ") {
+		t.Errorf("seed 1 generated %v and then %v", first, again)
+	}
+	if strings.Join(first, " ") == strings.Join(other, " ") {
+		t.Errorf("seeds 1 and 2 both generated %v", first)
+	}
+}
+
+func TestRandintEmpty(t *testing.T) {
+	s := load(t, "def generate(n):\n    return dict(question='q', answer=randint(2, 1))\n")
+	if _, err := s.Source().Next(); err == nil || !strings.Contains(err.Error(), "empty range") {
+		t.Errorf("Next() = %v, want an empty range error", err)
+	}
+}
+
+func TestScorer(t *testing.T) {
+	s := load(t, "def score(correct, incorrect, total, seconds):\n    return 10 * correct\n")
+	a := &quiz.Assessment{TotalCorrect: 3, TotalIncorrect: 1, TimeStart: time.Now()}
+	if got, err := s.Scorer()(a); err != nil || got != 30 {
+		t.Errorf("score() = %v, %v, want 30", got, err)
+	}
+
+	s = load(t, "def score(correct, incorrect, total, seconds):\n    return 'full marks'\n")
+	if _, err := s.Scorer()(a); err == nil {
+		t.Error("score() returning a string didn't fail")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loop.star")
+	if err := os.WriteFile(path, []byte("while True:\n    pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() of a script that never finishes loading didn't fail")
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.star")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() of a missing script = %v, want %v", err, os.ErrNotExist)
+	}
+}
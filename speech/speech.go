@@ -0,0 +1,164 @@
+// Package speech reads the questions of a test aloud, with the speech
+// synthesizer that comes with the operating system or any command that
+// speaks the text it is given, such as the client of a cloud TTS API.
+// It also runs commands that record and transcribe spoken answers.
+package speech
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// Speaker reads text aloud, returning when it has finished or ctx is done.
+type Speaker interface {
+	Say(ctx context.Context, text string) error
+}
+
+// Command is a Speaker that runs a program for each text.  The text is
+// put in place of any {text} argument, or else given on the program's
+// standard input.  A Command can also listen for answers.
+type Command struct {
+	Name string
+	Args []string
+}
+
+// Say runs the command to read text aloud.  The command is killed if ctx
+// is done before it finishes.
+func (c Command) Say(ctx context.Context, text string) error {
+	args := make([]string, len(c.Args))
+	stdin := true
+	for i, arg := range c.Args {
+		if strings.Contains(arg, "{text}") {
+			arg, stdin = strings.ReplaceAll(arg, "{text}", text), false
+		}
+		args[i] = arg
+	}
+	cmd := exec.CommandContext(ctx, c.Name, args...)
+	if stdin {
+		cmd.Stdin = strings.NewReader(text)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("%s: %w: %s", c.Name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Listen runs the command to record and transcribe a spoken answer, and
+// returns the text it prints.  Transcribers usually write sentences, so
+// the punctuation at the end is removed, e.g. "Paris." becomes "Paris".
+func (c Command) Listen(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w: %s", c.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	text := strings.Join(strings.Fields(string(out)), " ")
+	text = strings.TrimRight(text, ".!?,;: ")
+	if text == "" {
+		return "", errors.New("nothing was heard")
+	}
+	return text, nil
+}
+
+// ParseCommand returns the Command for a command line, split on spaces,
+// e.g. "spd-say -w -l fr {text}" or "./transcribe.sh 5".
+func ParseCommand(line string) (Command, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return Command{}, errors.New("the speech command is empty")
+	}
+	return Command{Name: fields[0], Args: fields[1:]}, nil
+}
+
+// System returns the operating system's speech synthesizer: say on macOS,
+// the System.Speech synthesizer on Windows and espeak-ng, espeak or
+// spd-say on Linux.  voice picks the voice or language if it isn't empty,
+// e.g. "Amelie" on macOS or "fr" for espeak.
+func System(voice string) (Speaker, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		c := Command{Name: "say"}
+		if voice != "" {
+			c.Args = []string{"-v", voice}
+		}
+		return c, nil
+	case "windows":
+		script := "Add-Type -AssemblyName System.Speech; $s = New-Object System.Speech.Synthesis.SpeechSynthesizer; "
+		if voice != "" {
+			script += "$s.SelectVoice('" + strings.ReplaceAll(voice, "'", "''") + "'); "
+		}
+		script += "$s.Speak([Console]::In.ReadToEnd())"
+		return Command{Name: "powershell", Args: []string{"-NoProfile", "-Command", script}}, nil
+	}
+
+	for _, name := range []string{"espeak-ng", "espeak", "spd-say"} {
+		if _, err := exec.LookPath(name); err != nil {
+			continue
+		}
+		c := Command{Name: name}
+		switch {
+		case name == "spd-say":
+			// spd-say only takes the text as an argument, and -w waits for it to be spoken
+			c.Args = []string{"-w"}
+			if voice != "" {
+				c.Args = append(c.Args, "-l", voice)
+			}
+			c.Args = append(c.Args, "--", "{text}")
+		case voice != "":
+			c.Args = []string{"-v", voice}
+		}
+		return c, nil
+	}
+	return nil, errors.New("there is no speech synthesizer, install espeak-ng or give a command to speak with")
+}
+
+// ReadAloud registers handlers with a that read each question aloud with s
+// as it is asked.  Speaking is stopped when the question is answered, so
+// the next question isn't held up by a long one.  Errors are written to
+// the test's output once, and the test carries on without speech.
+func ReadAloud(a *quiz.Assessment, s Speaker) {
+	var (
+		mu     sync.Mutex
+		stop   context.CancelFunc
+		failed bool
+	)
+	quiet := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if stop != nil {
+			stop()
+			stop = nil
+		}
+	}
+
+	a.OnQuestionAsked(func(a *quiz.Assessment, qnum int, q *quiz.Question) {
+		quiet()
+		mu.Lock()
+		defer mu.Unlock()
+		if failed {
+			return
+		}
+		var ctx context.Context
+		ctx, stop = context.WithCancel(context.Background())
+		go func() {
+			err := s.Say(ctx, q.QText)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && !failed {
+				failed = true
+				fmt.Fprintln(a.Out, "\nUnable to read the question aloud:", err)
+			}
+		}()
+	})
+	a.OnAnswered(func(a *quiz.Assessment, qnum int, q *quiz.Question) { quiet() })
+	a.OnFinished(func(a *quiz.Assessment) { quiet() })
+}
@@ -0,0 +1,291 @@
+// Package sshserver serves the quiz over ssh.
+package sshserver
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/metrics"
+	"github.com/rastewart/go-quiz-game/observer"
+	"github.com/rastewart/go-quiz-game/quiz"
+	"golang.org/x/crypto/ssh"
+)
+
+// Defaults for the Server's limits.
+const (
+	DefaultMaxSessions = 100              //Sessions played at once
+	DefaultIdleTimeout = 30 * time.Minute //Time a player can go without typing
+)
+
+// errIdle ends a session whose player hasn't typed for the IdleTimeout.
+var errIdle = errors.New("sshserver: idle for too long")
+
+// Server lets remote users play the quiz by connecting with ssh.
+// Every connection takes its own session of the Test, so each player gets
+// their own questions, timer and score.  A session is stopped when the
+// player disconnects or doesn't type anything for the IdleTimeout, and
+// players who connect while MaxSessions are being played are turned away.
+type Server struct {
+	Addr        string                                                 //Address the server listens on, e.g. ":2222"
+	HostKey     string                                                 //Path to the server's private host key. It is created if it doesn't exist.
+	Test        *quiz.Assessment                                       //Test each connection takes a session of, see quiz.Assessment.NewSession
+	Play        func(ctx context.Context, test *quiz.Assessment) error //Runs each session's test, its StartTest if nil
+	Observer    *observer.View                                         //Tracks the progress of each session when not nil
+	Metrics     *metrics.Metrics                                       //Records metrics for each session when not nil
+	MaxSessions int                                                    //Most sessions played at once, DefaultMaxSessions if 0
+	IdleTimeout time.Duration                                          //How long a player can go without typing before they are disconnected, DefaultIdleTimeout if 0
+
+	sessions chan struct{} //Holds a value for each session being played
+}
+
+// ListenAndServe accepts ssh connections until the listener fails.
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("SSH server is listening on %s. Press Ctrl+C to stop.\n", listener.Addr())
+	if s.Observer != nil {
+		go func() {
+			log.Printf("observer view stopped: %v", s.Observer.ListenAndServe())
+		}()
+		fmt.Printf("Observer view is available at http://%s/\n", s.Observer.Addr)
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts ssh connections on listener until it fails, and closes it.
+func (s *Server) Serve(listener net.Listener) error {
+	defer listener.Close()
+	signer, err := s.hostKey()
+	if err != nil {
+		return err
+	}
+
+	// Anyone can play so there is no authentication
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+
+	limit := s.MaxSessions
+	if limit <= 0 {
+		limit = DefaultMaxSessions
+	}
+	s.sessions = make(chan struct{}, limit)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handleConn(conn, config)
+	}
+}
+
+// handleConn serves the sessions of a connection.  Their context is
+// cancelled when the connection is lost.
+func (s *Server) handleConn(conn net.Conn, config *ssh.ServerConfig) {
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		log.Printf("ssh handshake with %s failed: %v", conn.RemoteAddr(), err)
+		return
+	}
+	defer sconn.Close()
+	log.Printf("%s connected as %s", sconn.RemoteAddr(), sconn.User())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		sconn.Wait()
+		cancel()
+	}()
+	go ssh.DiscardRequests(reqs)
+
+	for newChan := range chans {
+		if newChan.ChannelType() != "session" {
+			newChan.Reject(ssh.UnknownChannelType, "only session channels are supported")
+			continue
+		}
+		channel, requests, err := newChan.Accept()
+		if err != nil {
+			log.Printf("unable to accept channel from %s: %v", sconn.RemoteAddr(), err)
+			continue
+		}
+		go s.handleSession(ctx, channel, requests, sconn.RemoteAddr().String())
+	}
+	log.Printf("%s disconnected", sconn.RemoteAddr())
+}
+
+// handleSession waits for the client to ask for a shell and then runs
+// the quiz connected to the channel, if there is room for another session.
+func (s *Server) handleSession(ctx context.Context, channel ssh.Channel, requests <-chan *ssh.Request, remote string) {
+	defer channel.Close()
+
+	for req := range requests {
+		if req.Type != "shell" {
+			// Refusing a pty leaves line editing and echo to the client's
+			// terminal, which is all the quiz prompts need.
+			req.Reply(false, nil)
+			continue
+		}
+		req.Reply(true, nil)
+
+		// The requests stop when the client closes the channel
+		ctx, cancel := context.WithCancelCause(ctx)
+		go func() {
+			for req := range requests {
+				req.Reply(false, nil)
+			}
+			cancel(nil)
+		}()
+
+		var status uint32
+		select {
+		case s.sessions <- struct{}{}:
+			status = s.runQuiz(ctx, cancel, channel, remote)
+			<-s.sessions
+		default:
+			fmt.Fprintln(channel.Stderr(), "The quiz is full, please try again later.")
+			status = 1
+		}
+		channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+		cancel(nil)
+		return
+	}
+}
+
+// runQuiz runs a session of the test using the channel for input and
+// output and returns its exit status.  The session is stopped when ctx is
+// done, and cancelled with errIdle when the player hasn't typed anything
+// for the IdleTimeout.
+func (s *Server) runQuiz(ctx context.Context, cancel context.CancelCauseFunc, channel ssh.Channel, remote string) uint32 {
+	var watchers []func(io.Reader)
+	if s.Observer != nil {
+		watchers = append(watchers, func(r io.Reader) { s.Observer.Watch(remote, r) })
+	}
+	if s.Metrics != nil {
+		watchers = append(watchers, func(r io.Reader) { s.Metrics.Watch("ssh", r) })
+	}
+
+	idle := s.IdleTimeout
+	if idle <= 0 {
+		idle = DefaultIdleTimeout
+	}
+	input := &idleReader{r: channel, idle: idle, timer: time.AfterFunc(idle, func() { cancel(errIdle) })}
+	defer input.timer.Stop()
+
+	test := s.Test.NewSession(input, channel)
+	if watch := fanOut(watchers); watch != nil {
+		progress, w := io.Pipe()
+		test.ReportProgressTo(w)
+		watched := make(chan bool)
+		go func() {
+			watch(progress)
+			io.Copy(io.Discard, progress)
+			close(watched)
+		}()
+		defer func() {
+			w.Close()
+			<-watched
+		}()
+	}
+
+	var err error
+	if s.Play != nil {
+		err = s.Play(ctx, test)
+	} else {
+		err = test.StartTest(ctx)
+	}
+	switch {
+	case errors.Is(context.Cause(ctx), errIdle):
+		fmt.Fprintf(channel.Stderr(), "Disconnected after %s without any input.\n", idle)
+		return 1
+	case err == nil || errors.Is(err, quiz.ErrTimeExpired) || errors.Is(err, quiz.ErrQuit) || ctx.Err() != nil:
+		return 0
+	}
+	fmt.Fprintln(channel.Stderr(), "Error occurred:", err)
+	return 1
+}
+
+// idleReader reads from r, restarting timer each time something is read,
+// so it only fires once nothing has been read for idle.
+type idleReader struct {
+	r     io.Reader
+	idle  time.Duration
+	timer *time.Timer
+}
+
+func (i *idleReader) Read(p []byte) (int, error) {
+	n, err := i.r.Read(p)
+	if n > 0 {
+		i.timer.Reset(i.idle)
+	}
+	return n, err
+}
+
+// fanOut returns a watch function that gives each of watchers its own copy
+// of the progress, or nil if there are no watchers.
+func fanOut(watchers []func(io.Reader)) func(io.Reader) {
+	switch len(watchers) {
+	case 0:
+		return nil
+	case 1:
+		return watchers[0]
+	}
+
+	return func(r io.Reader) {
+		var wg sync.WaitGroup
+		var pipes []*io.PipeWriter
+		var writers []io.Writer
+		for _, watch := range watchers {
+			pr, pw := io.Pipe()
+			pipes = append(pipes, pw)
+			writers = append(writers, pw)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				watch(pr)
+				io.Copy(io.Discard, pr) // don't block the others if watch stops early
+			}()
+		}
+		io.Copy(io.MultiWriter(writers...), r)
+		for _, pw := range pipes {
+			pw.Close()
+		}
+		wg.Wait()
+	}
+}
+
+// hostKey loads the server's host key, generating and saving a new
+// ed25519 key the first time the server is run.
+func (s *Server) hostKey() (ssh.Signer, error) {
+	data, err := os.ReadFile(s.HostKey)
+	if err == nil {
+		return ssh.ParsePrivateKey(data)
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	block, err := ssh.MarshalPrivateKey(key, "quiz host key")
+	if err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(s.HostKey, pem.EncodeToMemory(block), 0600); err != nil {
+		return nil, err
+	}
+	log.Printf("generated new host key %s", s.HostKey)
+	return ssh.NewSignerFromKey(key)
+}
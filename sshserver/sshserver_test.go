@@ -0,0 +1,159 @@
+package sshserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+	"golang.org/x/crypto/ssh"
+)
+
+var arithmetic = &quiz.Assessment{
+	Questions:      []quiz.Question{{QText: "1+1", Answer: "2"}, {QText: "2+2", Answer: "4"}},
+	TotalQuestions: 2,
+	NoGreeting:     true,
+}
+
+// serve starts s on a free port and returns its address.
+func serve(t *testing.T, s *Server) string {
+	t.Helper()
+	s.HostKey = filepath.Join(t.TempDir(), "host_key")
+	if s.Test == nil {
+		s.Test = arithmetic
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go s.Serve(l)
+	return l.Addr().String()
+}
+
+// session is a player's ssh session with the quiz.
+type session struct {
+	client *ssh.Client
+	*ssh.Session
+	stdout, stderr bytes.Buffer
+}
+
+// connect connects to the server at addr and starts the quiz, typing input.
+func connect(t *testing.T, addr string, input string) *session {
+	t.Helper()
+	client, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{User: "player", HostKeyCallback: ssh.InsecureIgnoreHostKey()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	sess, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &session{client: client, Session: sess}
+	sess.Stdin = strings.NewReader(input)
+	sess.Stdout, sess.Stderr = &s.stdout, &s.stderr
+	if err = sess.Shell(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// wait waits for the session to end and returns its exit status.
+func (s *session) wait(t *testing.T) int {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- s.Wait() }()
+	select {
+	case err := <-done:
+		var exit *ssh.ExitError
+		if errors.As(err, &exit) {
+			return exit.ExitStatus()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return 0
+	case <-time.After(5 * time.Second):
+		t.Fatal("the session didn't end")
+		return -1
+	}
+}
+
+func TestPlay(t *testing.T) {
+	addr := serve(t, &Server{})
+	s := connect(t, addr, "2\n5\n")
+	if status := s.wait(t); status != 0 {
+		t.Errorf("exit status %v, want 0:\n%s", status, s.stderr.String())
+	}
+	if !strings.Contains(s.stdout.String(), "You got 1 questions right and 1 questions wrong.") {
+		t.Errorf("the player was sent:\n%s", s.stdout.String())
+	}
+}
+
+// blockingPlay returns a Play function that waits until its session is
+// stopped, sending its context's error to stopped.
+func blockingPlay(started chan<- bool, stopped chan<- error) func(context.Context, *quiz.Assessment) error {
+	return func(ctx context.Context, test *quiz.Assessment) error {
+		started <- true
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return ctx.Err()
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	started, stopped := make(chan bool, 1), make(chan error, 1)
+	addr := serve(t, &Server{Play: blockingPlay(started, stopped)})
+	s := connect(t, addr, "")
+	<-started
+
+	s.client.Close()
+	select {
+	case err := <-stopped:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("the session was stopped with %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the session carried on after the player disconnected")
+	}
+}
+
+func TestMaxSessions(t *testing.T) {
+	started, stopped := make(chan bool, 2), make(chan error, 2)
+	addr := serve(t, &Server{Play: blockingPlay(started, stopped), MaxSessions: 1})
+	first := connect(t, addr, "")
+	<-started
+
+	second := connect(t, addr, "")
+	if status := second.wait(t); status != 1 || !strings.Contains(second.stderr.String(), "The quiz is full") {
+		t.Errorf("the second session ended with %v and %q, want it turned away", status, second.stderr.String())
+	}
+
+	// Once the first player leaves there is room again
+	first.client.Close()
+	<-stopped
+	third := connect(t, addr, "")
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the third session wasn't started: %s", third.stderr.String())
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	started, stopped := make(chan bool, 1), make(chan error, 1)
+	addr := serve(t, &Server{Play: blockingPlay(started, stopped), IdleTimeout: 50 * time.Millisecond})
+	s := connect(t, addr, "")
+	if status := s.wait(t); status != 1 {
+		t.Errorf("exit status %v, want 1", status)
+	}
+	if !strings.Contains(s.stderr.String(), "Disconnected after 50ms without any input.") {
+		t.Errorf("the player was sent %q", s.stderr.String())
+	}
+}
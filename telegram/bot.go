@@ -0,0 +1,598 @@
+// Package telegram runs the quiz as a Telegram bot.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/metrics"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// Bot runs the quiz inside Telegram chats.
+// Questions with choices are sent as native quiz polls and the rest are sent
+// as text messages that players answer by replying in the chat.
+// Every chat has its own leaderboard which lasts as long as the bot is running.
+//
+// Players in a group chat can join teams with /team.  The answers of a
+// team's members are pooled into a single team answer, either the first
+// answer given or the majority vote depending on TeamPool, and teams are
+// scored alongside the individual players.
+type Bot struct {
+	Token     string           //Bot API token issued by @BotFather
+	Questions []quiz.Question  //Questions asked in every chat
+	Window    time.Duration    //How long each question stays open for answers
+	TeamPool  string           //How a team's answers are pooled, "first" or "majority"
+	APIURL    string           //Base URL of the Bot API
+	Metrics   *metrics.Metrics //Records metrics for each quiz when not nil
+
+	client *http.Client
+	offset int64            //id of the next update to fetch
+	chats  map[int64]*chat  //chat state keyed by chat id
+	polls  map[string]int64 //chat id keyed by the id of the poll sent to it
+}
+
+// chat holds the quiz state and leaderboard for one chat.
+type chat struct {
+	ID       int64
+	Private  bool
+	Running  bool
+	Index    int              //Index of the current question
+	Asked    time.Time        //When the current question was asked
+	Deadline time.Time        //When the current question closes
+	PollID   string           //Poll id when the current question is a quiz poll
+	Answers  map[int64]string //Answers to the current question keyed by user id
+	Order    []int64          //Order in which users answered the current question
+	Names    map[int64]string //Display names keyed by user id
+	Scores   map[int64]int    //Leaderboard keyed by user id
+	Played   int              //Questions asked in every finished quiz, which the Scores are out of
+	Teams    map[int64]string //Team names keyed by user id
+	TeamWins map[string]int   //Team leaderboard keyed by team name
+}
+
+// user, message and friends mirror the parts of the
+// Bot API objects that the bot uses.
+type user struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	Username  string `json:"username"`
+}
+
+type message struct {
+	Chat struct {
+		ID   int64  `json:"id"`
+		Type string `json:"type"`
+	} `json:"chat"`
+	From *user  `json:"from"`
+	Text string `json:"text"`
+}
+
+type pollAnswer struct {
+	PollID    string `json:"poll_id"`
+	User      *user  `json:"user"`
+	OptionIDs []int  `json:"option_ids"`
+}
+
+type update struct {
+	UpdateID   int64       `json:"update_id"`
+	Message    *message    `json:"message"`
+	PollAnswer *pollAnswer `json:"poll_answer"`
+}
+
+// New creates a bot that asks questions in every chat it is added to.
+func New(token string, questions []quiz.Question) *Bot {
+	return &Bot{
+		Token:     token,
+		Questions: questions,
+		Window:    20 * time.Second,
+		TeamPool:  TeamPoolFirst,
+		APIURL:    "https://api.telegram.org",
+		client:    &http.Client{Timeout: 30 * time.Second},
+		chats:     make(map[int64]*chat),
+		polls:     make(map[string]int64),
+	}
+}
+
+// Run polls Telegram for updates and handles them until ctx is done, or
+// the Bot API refuses the Token.  A call that fails for one chat, such as
+// a player who blocked the bot, is logged and the other chats carry on.
+func (b *Bot) Run(ctx context.Context) (err error) {
+	if len(b.Questions) == 0 {
+		return errors.New("there are no questions to ask")
+	}
+	if b.TeamPool != TeamPoolFirst && b.TeamPool != TeamPoolMajority {
+		return fmt.Errorf("unknown team pooling %q, use %q or %q", b.TeamPool, TeamPoolFirst, TeamPoolMajority)
+	}
+
+	log.Println("Telegram bot is running. Press Ctrl+C to stop.")
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		var updates []update
+		err = b.call(ctx, "getUpdates", map[string]interface{}{
+			"offset":          b.offset,
+			"timeout":         1,
+			"allowed_updates": []string{"message", "poll_answer"},
+		}, &updates)
+		if ctx.Err() != nil {
+			return nil
+		}
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && (apiErr.Code == http.StatusUnauthorized || apiErr.Code == http.StatusNotFound) {
+			return err
+		}
+		if err != nil {
+			log.Printf("unable to get updates from Telegram: %v", err)
+			wait(ctx, 5*time.Second)
+			continue
+		}
+
+		for _, u := range updates {
+			b.offset = u.UpdateID + 1
+			switch {
+			case u.Message != nil:
+				b.failed(u.Message.Chat.ID, b.handleMessage(ctx, u.Message))
+			case u.PollAnswer != nil:
+				chatID := b.polls[u.PollAnswer.PollID]
+				b.failed(chatID, b.handlePollAnswer(ctx, u.PollAnswer))
+			}
+		}
+
+		// Close any questions whose answer window has run out
+		for _, c := range b.chats {
+			if c.Running && time.Now().After(c.Deadline) {
+				b.failed(c.ID, b.closeQuestion(ctx, c))
+			}
+		}
+	}
+}
+
+// failed logs err from handling an update for the chat chatID, if there
+// was one.  When the bot can't write to the chat, because it was blocked
+// or removed from it, the chat's quiz is stopped instead of carrying on
+// asking questions no one sees.
+func (b *Bot) failed(chatID int64, err error) {
+	if err == nil {
+		return
+	}
+	log.Printf("Telegram chat %d: %v", chatID, err)
+	var apiErr *apiError
+	if c, ok := b.chats[chatID]; ok && c.Running && errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden {
+		c.Running = false
+		delete(b.polls, c.PollID)
+		b.recordFinished(c)
+	}
+}
+
+// chatFor returns the state for a chat, creating it on first use.
+func (b *Bot) chatFor(m *message) *chat {
+	c, ok := b.chats[m.Chat.ID]
+	if !ok {
+		c = &chat{
+			ID:       m.Chat.ID,
+			Private:  m.Chat.Type == "private",
+			Names:    make(map[int64]string),
+			Scores:   make(map[int64]int),
+			Teams:    make(map[int64]string),
+			TeamWins: make(map[string]int),
+		}
+		b.chats[m.Chat.ID] = c
+	}
+	return c
+}
+
+func (b *Bot) handleMessage(ctx context.Context, m *message) error {
+	if m.From == nil {
+		return nil
+	}
+	c := b.chatFor(m)
+	c.Names[m.From.ID] = displayName(m.From)
+	text := strings.TrimSpace(m.Text)
+
+	if strings.HasPrefix(text, "/") {
+		// Commands in groups can be addressed to a bot like /quiz@QuizBot
+		command := strings.SplitN(strings.Fields(text)[0], "@", 2)[0]
+		switch command {
+		case "/quiz":
+			if c.Running {
+				return b.send(ctx, c.ID, "A quiz is already running in this chat.")
+			}
+			c.Running = true
+			c.Index = 0
+			if b.Metrics != nil {
+				b.Metrics.SessionStarted("telegram")
+			}
+			return b.askQuestion(ctx, c)
+		case "/stop":
+			if !c.Running {
+				return b.send(ctx, c.ID, "There is no quiz running.")
+			}
+			c.Running = false
+			b.recordFinished(c)
+			return b.send(ctx, c.ID, "Quiz stopped.\n\n"+c.leaderboard())
+		case "/leaderboard":
+			return b.send(ctx, c.ID, c.leaderboard())
+		case "/team":
+			team := strings.TrimSpace(strings.TrimPrefix(text, strings.Fields(text)[0]))
+			if team == "" {
+				return b.send(ctx, c.ID, "Use /team <name> to join a team.")
+			}
+			c.Teams[m.From.ID] = team
+			if _, ok := c.TeamWins[team]; !ok {
+				c.TeamWins[team] = 0
+			}
+			return b.send(ctx, c.ID, fmt.Sprintf("%s joined team %s.", c.Names[m.From.ID], team))
+		default:
+			return b.send(ctx, c.ID, "Commands:\n/quiz - start a quiz\n/stop - stop the quiz\n/team <name> - join a team\n/leaderboard - show the scores for this chat")
+		}
+	}
+
+	// Free answers are only taken for text questions while the question is open
+	if !c.Running || c.PollID != "" {
+		return nil
+	}
+	if _, answered := c.Answers[m.From.ID]; answered {
+		return nil
+	}
+	c.Answers[m.From.ID] = text
+	c.Order = append(c.Order, m.From.ID)
+	b.recordAnswer(c, text)
+
+	// There is only one player in a private chat so there is no need to wait
+	if c.Private {
+		return b.closeQuestion(ctx, c)
+	}
+	return nil
+}
+
+func (b *Bot) handlePollAnswer(ctx context.Context, pa *pollAnswer) error {
+	chatID, ok := b.polls[pa.PollID]
+	if !ok || pa.User == nil || len(pa.OptionIDs) == 0 {
+		return nil
+	}
+	c := b.chats[chatID]
+	if !c.Running || c.PollID != pa.PollID {
+		return nil
+	}
+	if _, answered := c.Answers[pa.User.ID]; answered {
+		return nil
+	}
+
+	options := b.Questions[c.Index].Options()
+	if pa.OptionIDs[0] >= len(options) {
+		return nil
+	}
+	c.Names[pa.User.ID] = displayName(pa.User)
+	c.Answers[pa.User.ID] = options[pa.OptionIDs[0]]
+	c.Order = append(c.Order, pa.User.ID)
+	b.recordAnswer(c, options[pa.OptionIDs[0]])
+
+	if c.Private {
+		return b.closeQuestion(ctx, c)
+	}
+	return nil
+}
+
+// recordAnswer records the metrics for an answer to the current question.
+func (b *Bot) recordAnswer(c *chat, answer string) {
+	if b.Metrics != nil {
+		b.Metrics.QuestionAnswered("telegram", b.Questions[c.Index].IsCorrect(answer), time.Since(c.Asked))
+	}
+}
+
+// recordFinished records the metrics for the end of a chat's quiz.  Each
+// player's score is out of the questions asked, including earlier quizzes
+// in the chat since the leaderboard covers them all.
+func (b *Bot) recordFinished(c *chat) {
+	c.Played += c.Index
+	if b.Metrics == nil {
+		return
+	}
+	b.Metrics.SessionFinished("telegram")
+	if c.Played == 0 {
+		return
+	}
+	for _, score := range c.Scores {
+		b.Metrics.Scored("telegram", float64(score)/float64(c.Played)*100)
+	}
+}
+
+// askQuestion sends the current question to the chat, or the final
+// results if there are no more questions.
+func (b *Bot) askQuestion(ctx context.Context, c *chat) error {
+	if c.Index >= len(b.Questions) {
+		c.Running = false
+		b.recordFinished(c)
+		return b.send(ctx, c.ID, "That's the end of the quiz!\n\n"+c.leaderboard())
+	}
+
+	q := b.Questions[c.Index]
+	c.Answers = make(map[int64]string)
+	c.Order = nil
+	c.PollID = ""
+	c.Asked = time.Now()
+	window := b.Window
+	if q.TimeLimit > 0 {
+		window = q.TimeLimit
+	}
+	c.Deadline = c.Asked.Add(window)
+	title := fmt.Sprintf("%v. %s", c.Index+1, q.QText)
+
+	options := q.Options()
+	if len(options) < 2 || len(options) > 10 {
+		return b.send(ctx, c.ID, title+" = ?")
+	}
+
+	correct := 0
+	for i, o := range options {
+		if o == q.Answer {
+			correct = i
+		}
+	}
+
+	// The Bot API only allows polls to be open for 5 to 600 seconds
+	period := int(window.Seconds())
+	if period < 5 {
+		period = 5
+	} else if period > 600 {
+		period = 600
+	}
+
+	var poll struct {
+		Poll struct {
+			ID string `json:"id"`
+		} `json:"poll"`
+	}
+	err := b.call(ctx, "sendPoll", map[string]interface{}{
+		"chat_id":           c.ID,
+		"question":          title,
+		"options":           options,
+		"type":              "quiz",
+		"correct_option_id": correct,
+		"is_anonymous":      false,
+		"open_period":       period,
+	}, &poll)
+	if err != nil {
+		return err
+	}
+	c.PollID = poll.Poll.ID
+	b.polls[c.PollID] = c.ID
+	return nil
+}
+
+// closeQuestion grades the answers to the current question, announces
+// who got it right and moves on to the next question.
+func (b *Bot) closeQuestion(ctx context.Context, c *chat) error {
+	q := b.Questions[c.Index]
+	delete(b.polls, c.PollID)
+
+	var right []string
+	for _, id := range c.Order {
+		// Everyone who answers is on the leaderboard, even with no points
+		if _, ok := c.Scores[id]; !ok {
+			c.Scores[id] = 0
+		}
+		if q.IsCorrect(c.Answers[id]) {
+			c.Scores[id]++
+			right = append(right, c.Names[id])
+		}
+	}
+
+	msg := fmt.Sprintf("The answer was %s.", q.Answer)
+	if len(right) > 0 {
+		msg += "\nCorrect: " + strings.Join(right, ", ")
+	} else {
+		msg += "\nNobody got it right."
+	}
+
+	var teamsRight []string
+	for _, team := range c.teamNames() {
+		if q.IsCorrect(c.teamAnswer(team, b.TeamPool)) {
+			c.TeamWins[team]++
+			teamsRight = append(teamsRight, team)
+		}
+	}
+	if len(teamsRight) > 0 {
+		msg += "\nTeams correct: " + strings.Join(teamsRight, ", ")
+	}
+
+	// The quiz moves on even if the answer can't be sent, so the question
+	// isn't graded twice
+	c.Index++
+	return errors.Join(b.send(ctx, c.ID, msg), b.askQuestion(ctx, c))
+}
+
+// Ways of pooling the answers of a team's members into the team's answer.
+const (
+	TeamPoolFirst    = "first"    //The first answer from any member counts
+	TeamPoolMajority = "majority" //The most common answer among the members counts
+)
+
+// teamAnswer pools the answers of a team's members to the current question.
+// Answers are normalized as they are for grading, so answers that only
+// differ in how they were typed count as the same vote.  Ties in a
+// majority vote go to the answer that was given first.
+func (c *chat) teamAnswer(team string, pool string) string {
+	votes := make(map[string]int)
+	var answers []string
+	for _, id := range c.Order {
+		if c.Teams[id] != team {
+			continue
+		}
+		answer := quiz.Normalize(c.Answers[id])
+		if pool == TeamPoolFirst {
+			return answer
+		}
+		if votes[answer] == 0 {
+			answers = append(answers, answer)
+		}
+		votes[answer]++
+	}
+
+	best := ""
+	for _, answer := range answers {
+		if best == "" || votes[answer] > votes[best] {
+			best = answer
+		}
+	}
+	return best
+}
+
+// teamNames returns the names of the teams in the chat, sorted.
+func (c *chat) teamNames() []string {
+	teams := make([]string, 0, len(c.TeamWins))
+	for team := range c.TeamWins {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	return teams
+}
+
+// leaderboard formats the scores for the chat, highest first.
+func (c *chat) leaderboard() string {
+	if len(c.Scores) == 0 {
+		return "No scores yet. Send /quiz to play."
+	}
+
+	var sb strings.Builder
+	if len(c.TeamWins) > 0 {
+		teams := c.teamNames()
+		sort.SliceStable(teams, func(i, j int) bool { return c.TeamWins[teams[i]] > c.TeamWins[teams[j]] })
+
+		sb.WriteString("Teams")
+		for i, team := range teams {
+			var members []string
+			for id, t := range c.Teams {
+				if t == team {
+					members = append(members, c.Names[id])
+				}
+			}
+			sort.Strings(members)
+			fmt.Fprintf(&sb, "\n%v. %s - %v (%s)", i+1, team, c.TeamWins[team], strings.Join(members, ", "))
+		}
+		sb.WriteString("\n\n")
+	}
+
+	ids := make([]int64, 0, len(c.Scores))
+	for id := range c.Scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if c.Scores[ids[i]] != c.Scores[ids[j]] {
+			return c.Scores[ids[i]] > c.Scores[ids[j]]
+		}
+		return c.Names[ids[i]] < c.Names[ids[j]]
+	})
+
+	sb.WriteString("Leaderboard")
+	for i, id := range ids {
+		fmt.Fprintf(&sb, "\n%v. %s - %v", i+1, c.Names[id], c.Scores[id])
+	}
+	return sb.String()
+}
+
+func (b *Bot) send(ctx context.Context, chatID int64, text string) error {
+	return b.call(ctx, "sendMessage", map[string]interface{}{"chat_id": chatID, "text": text}, nil)
+}
+
+// apiError is an error returned by the Bot API.
+type apiError struct {
+	Method      string //Bot API method that was called
+	Code        int    //HTTP status of the error, e.g. 403 when the bot was blocked
+	Description string //What went wrong
+	RetryAfter  int    //Seconds to wait before calling again when Code is 429, 0 otherwise
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("telegram %s: %s", e.Method, e.Description)
+}
+
+// maxRetries is the most times a call is retried after the Bot API asks
+// the bot to slow down.
+const maxRetries = 3
+
+// call invokes a Bot API method and decodes its result into result.  When
+// the Bot API says there have been too many requests the call is retried
+// after the time it asks for.
+func (b *Bot) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	for attempt := 0; ; attempt++ {
+		err := b.callOnce(ctx, method, params, result)
+		var apiErr *apiError
+		if !errors.As(err, &apiErr) || apiErr.RetryAfter == 0 || attempt == maxRetries {
+			return err
+		}
+		if !wait(ctx, time.Duration(apiErr.RetryAfter)*time.Second) {
+			return ctx.Err()
+		}
+	}
+}
+
+// callOnce invokes a Bot API method once, decoding its result into result.
+func (b *Bot) callOnce(ctx context.Context, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.APIURL+"/bot"+b.Token+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var reply struct {
+		OK          bool            `json:"ok"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return err
+	}
+	if !reply.OK {
+		return &apiError{Method: method, Code: reply.ErrorCode, Description: reply.Description, RetryAfter: reply.Parameters.RetryAfter}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(reply.Result, result)
+}
+
+// wait waits for d, or until ctx is done, and reports whether it waited
+// the whole time.
+func wait(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func displayName(u *user) string {
+	if u.Username != "" {
+		return "@" + u.Username
+	}
+	return u.FirstName
+}
@@ -0,0 +1,190 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// fakeAPI is a Bot API that keeps the messages sent to each chat.  Calls
+// for the chats in blocked fail as they do when a player blocks the bot.
+type fakeAPI struct {
+	blocked map[int64]bool
+
+	mu    sync.Mutex
+	sent  map[int64][]string
+	polls int
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		ChatID   int64  `json:"chat_id"`
+		Text     string `json:"text"`
+		Question string `json:"question"`
+	}
+	json.NewDecoder(r.Body).Decode(&params)
+	if f.blocked[params.ChatID] {
+		json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": http.StatusForbidden, "description": "Forbidden: bot was blocked by the user"})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := map[string]any{}
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+		f.sent[params.ChatID] = append(f.sent[params.ChatID], params.Text)
+	case strings.HasSuffix(r.URL.Path, "/sendPoll"):
+		f.polls++
+		f.sent[params.ChatID] = append(f.sent[params.ChatID], params.Question)
+		result["poll"] = map[string]any{"id": fmt.Sprint("poll", f.polls)}
+	}
+	json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+// last returns the last message sent to the chat.
+func (f *fakeAPI) last(chatID int64) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sent := f.sent[chatID]
+	if len(sent) == 0 {
+		return ""
+	}
+	return sent[len(sent)-1]
+}
+
+// newBot returns a bot asking questions that talks to a fakeAPI.
+func newBot(t *testing.T, questions []quiz.Question) (*Bot, *fakeAPI) {
+	t.Helper()
+	api := &fakeAPI{blocked: make(map[int64]bool), sent: make(map[int64][]string)}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	b := New("token", questions)
+	b.APIURL = srv.URL
+	return b, api
+}
+
+// say returns a message from the user to the chat.
+func say(chatID int64, chatType string, userID int64, name, text string) *message {
+	m := &message{From: &user{ID: userID, FirstName: name}, Text: text}
+	m.Chat.ID, m.Chat.Type = chatID, chatType
+	return m
+}
+
+var questions = []quiz.Question{
+	{QText: "2+2", Answer: "4"},
+	{QText: "Capital of France?", Answer: "Paris"},
+}
+
+func TestTeamAnswer(t *testing.T) {
+	tests := []struct {
+		name    string
+		pool    string
+		answers []string //Answers of the red team's members, in the order they were given
+		want    string
+	}{
+		{name: "first", pool: TeamPoolFirst, answers: []string{" 5 ", "4", "4"}, want: "5"},
+		{name: "majority", pool: TeamPoolMajority, answers: []string{"5", "4", "4"}, want: "4"},
+		{name: "tie goes to the first answer", pool: TeamPoolMajority, answers: []string{"5", "4"}, want: "5"},
+		{name: "typed differently", pool: TeamPoolMajority, answers: []string{"5", "4", "\uff14", " 4\u200b"}, want: "4"},
+		{name: "nobody answered", pool: TeamPoolMajority, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &chat{Answers: make(map[int64]string), Teams: map[int64]string{100: "blue"}}
+			c.Answers[100] = "4"
+			c.Order = append(c.Order, 100)
+			for i, answer := range tt.answers {
+				id := int64(i + 1)
+				c.Teams[id] = "red"
+				c.Answers[id] = answer
+				c.Order = append(c.Order, id)
+			}
+			if got := c.teamAnswer("red", tt.pool); got != tt.want {
+				t.Errorf("teamAnswer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChats(t *testing.T) {
+	b, api := newBot(t, questions)
+	b.TeamPool = TeamPoolMajority
+	ctx := context.Background()
+	const group, private = -1, 7
+
+	// A team in a group chat answers in different ways and the majority is right
+	for _, m := range []*message{
+		say(group, "group", 1, "Ann", "/team red"),
+		say(group, "group", 2, "Bob", "/team red"),
+		say(group, "group", 3, "Cat", "/team red"),
+		say(group, "group", 1, "Ann", "/quiz"),
+		say(group, "group", 1, "Ann", "5"),
+		say(group, "group", 2, "Bob", "\uff14"),
+		say(group, "group", 3, "Cat", "4 "),
+		say(group, "group", 3, "Cat", "5"),
+	} {
+		if err := b.handleMessage(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Meanwhile a quiz in a private chat moves on as soon as it is answered
+	if err := b.handleMessage(ctx, say(private, "private", 9, "Dan", "/quiz")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.handleMessage(ctx, say(private, "private", 9, "Dan", "4")); err != nil {
+		t.Fatal(err)
+	}
+	if got := api.last(private); got != "2. Capital of France? = ?" {
+		t.Errorf("the private chat was sent %q, want the second question", got)
+	}
+	if g := b.chats[group]; g.Index != 0 || len(g.Answers) != 3 {
+		t.Errorf("the group chat is on question %v with %v answers, want the first with 3", g.Index+1, len(g.Answers))
+	}
+
+	if err := b.closeQuestion(ctx, b.chats[group]); err != nil {
+		t.Fatal(err)
+	}
+	g := b.chats[group]
+	if g.Scores[1] != 0 || g.Scores[2] != 1 || g.Scores[3] != 1 {
+		t.Errorf("the group's scores are %v, want Bob and Cat right", g.Scores)
+	}
+	if g.TeamWins["red"] != 1 {
+		t.Errorf("team red has %v wins, want 1 for its majority answer", g.TeamWins["red"])
+	}
+	if p := b.chats[private]; p.Scores[9] != 1 || len(p.Scores) != 1 || len(p.TeamWins) != 0 {
+		t.Errorf("the private chat's scores are %v and %v, want only Dan's", p.Scores, p.TeamWins)
+	}
+	if !strings.Contains(api.last(group), "2. Capital of France?") {
+		t.Errorf("the group chat was sent %q, want the second question", api.last(group))
+	}
+
+	if err := b.handleMessage(ctx, say(private, "private", 9, "Dan", "/stop")); err != nil {
+		t.Fatal(err)
+	}
+	if b.chats[private].Running || !b.chats[group].Running {
+		t.Error("stopping the private chat's quiz didn't stop only that one")
+	}
+}
+
+func TestBlocked(t *testing.T) {
+	b, api := newBot(t, questions)
+	ctx := context.Background()
+	if err := b.handleMessage(ctx, say(7, "private", 9, "Dan", "/quiz")); err != nil {
+		t.Fatal(err)
+	}
+
+	api.blocked[7] = true
+	b.failed(7, b.handleMessage(ctx, say(7, "private", 9, "Dan", "4")))
+	if c := b.chats[7]; c.Running {
+		t.Error("the quiz carried on in a chat that blocked the bot")
+	}
+}
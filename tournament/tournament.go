@@ -0,0 +1,254 @@
+// Package tournament runs single elimination quiz tournaments.
+package tournament
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// Tournament is a single elimination bracket of head-to-head matches.
+// Both players in a match take the same quiz in turn and the winner goes
+// through to the next round.  The bracket is saved after every match so a
+// tournament can be played over as many sessions as needed.
+type Tournament struct {
+	File    string     `json:"-"`       //File the bracket is saved in
+	Players []string   `json:"players"` //Players in the order they were seeded
+	Rounds  [][]*Match `json:"rounds"`  //Matches in each round played so far
+}
+
+// Match is a head-to-head between two players.
+// A match without a second player is a bye and the first player goes through.
+type Match struct {
+	Player1 string      `json:"player1"`
+	Player2 string      `json:"player2,omitempty"`
+	Result1 *MatchScore `json:"result1,omitempty"`
+	Result2 *MatchScore `json:"result2,omitempty"`
+	Winner  string      `json:"winner,omitempty"`
+}
+
+// MatchScore is one player's result in a match.
+type MatchScore struct {
+	Correct int     `json:"correct"` //Questions answered correctly
+	Total   int     `json:"total"`   //Questions in the quiz
+	Seconds float64 `json:"seconds"` //How long the player took
+}
+
+// New creates a bracket for players, seeded in the order given.
+func New(file string, players []string) (*Tournament, error) {
+	if len(players) < 2 {
+		return nil, errors.New("a tournament needs at least two players")
+	}
+	seen := make(map[string]bool)
+	for _, p := range players {
+		if seen[p] {
+			return nil, fmt.Errorf("player %s is in the tournament twice", p)
+		}
+		seen[p] = true
+	}
+
+	t := &Tournament{File: file, Players: players}
+	t.addRound(players)
+	return t, t.Save()
+}
+
+// Load reads a saved bracket.
+func Load(file string) (*Tournament, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	t := &Tournament{File: file}
+	if err = json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("unable to read tournament %s: %v", file, err)
+	}
+	return t, nil
+}
+
+// Save writes the bracket to its file, writing it to a temporary file
+// first so the bracket is never left half written if the quiz stops.
+func (t *Tournament) Save() (err error) {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(t.File), filepath.Base(t.File)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), t.File)
+	}
+	return err
+}
+
+// addRound pairs up players for the next round.  With an odd number of
+// players the last one gets a bye.
+func (t *Tournament) addRound(players []string) {
+	var round []*Match
+	for i := 0; i < len(players); i += 2 {
+		m := &Match{Player1: players[i]}
+		if i+1 < len(players) {
+			m.Player2 = players[i+1]
+		} else {
+			m.Winner = m.Player1
+		}
+		round = append(round, m)
+	}
+	t.Rounds = append(t.Rounds, round)
+}
+
+// NextMatch returns the next match to be played, starting a new round when
+// the current one is finished.  It returns nil once there is a champion.
+func (t *Tournament) NextMatch() *Match {
+	round := t.Rounds[len(t.Rounds)-1]
+	var winners []string
+	for _, m := range round {
+		if m.Winner == "" {
+			return m
+		}
+		winners = append(winners, m.Winner)
+	}
+	if len(winners) == 1 {
+		return nil
+	}
+	t.addRound(winners)
+	return t.NextMatch()
+}
+
+// Champion returns the winner of the tournament, or "" if it isn't over.
+func (t *Tournament) Champion() string {
+	round := t.Rounds[len(t.Rounds)-1]
+	if len(round) == 1 {
+		return round[0].Winner
+	}
+	return ""
+}
+
+// decide picks the winner of a match with both results.  The player with
+// more correct answers wins, then the faster player.  If both are equal the
+// higher seed goes through.
+func (m *Match) decide() {
+	switch {
+	case m.Result1.Correct != m.Result2.Correct:
+		if m.Result1.Correct > m.Result2.Correct {
+			m.Winner = m.Player1
+		} else {
+			m.Winner = m.Player2
+		}
+	case m.Result2.Seconds < m.Result1.Seconds:
+		m.Winner = m.Player2
+	default:
+		m.Winner = m.Player1
+	}
+}
+
+// ShowBracket prints every match played or scheduled so far.
+func (t *Tournament) ShowBracket(w io.Writer) {
+	score := func(s *MatchScore) string {
+		if s == nil {
+			return ""
+		}
+		return fmt.Sprintf("%v/%v in %.2fs", s.Correct, s.Total, s.Seconds)
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Round", "Match", "Player 1", "Score", "Player 2", "Score", "Winner"})
+	for r, round := range t.Rounds {
+		for i, m := range round {
+			player2 := m.Player2
+			if player2 == "" {
+				player2 = "(bye)"
+			}
+			table.Append([]string{strconv.Itoa(r + 1), strconv.Itoa(i + 1),
+				m.Player1, score(m.Result1), player2, score(m.Result2), m.Winner})
+		}
+	}
+	table.Render()
+}
+
+// Play plays the matches in the tournament one after another, asking
+// before each one, until the user stops or there is a champion.
+// Each player takes their own session of test, see quiz.Assessment.NewSession.
+func (t *Tournament) Play(ctx context.Context, test *quiz.Assessment) error {
+	input := quiz.NewInput(os.Stdin)
+	defer input.Close()
+	for {
+		m := t.NextMatch()
+		t.ShowBracket(os.Stdout)
+		if m == nil {
+			fmt.Printf("%s is the champion!\n", t.Champion())
+			return t.Save()
+		}
+
+		fmt.Printf("Next match: %s vs %s. Play it now? [Y/n] ", m.Player1, m.Player2)
+		answer, err := input.ReadLine(ctx)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "n") {
+			fmt.Printf("The tournament has been saved to %s.\n", t.File)
+			return t.Save()
+		}
+
+		if m.Result1, err = playMatchTurn(ctx, test, input, m.Player1); err != nil {
+			return err
+		}
+		if m.Result2, err = playMatchTurn(ctx, test, input, m.Player2); err != nil {
+			return err
+		}
+		m.decide()
+		fmt.Printf("%s wins the match!\n", m.Winner)
+
+		if err = t.Save(); err != nil {
+			return err
+		}
+	}
+}
+
+// playMatchTurn runs a session of test for one player, reading their
+// answers from input, and returns their result.
+func playMatchTurn(ctx context.Context, test *quiz.Assessment, input *quiz.Input, player string) (*MatchScore, error) {
+	fmt.Printf("------------------------\n%s, it's your turn.\n", player)
+
+	s := test.NewSession(nil, os.Stdout)
+	quiz.WithSharedInput(input)(s)
+	s.Name = player
+	var finished time.Time
+	s.OnFinished(func(a *quiz.Assessment) { finished = time.Now() })
+
+	err := s.StartTest(ctx)
+	switch {
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case finished.IsZero():
+		return nil, fmt.Errorf("the quiz for %s did not finish: %w", player, err)
+	}
+	return &MatchScore{
+		Correct: s.TotalCorrect,
+		Total:   s.Total(),
+		Seconds: finished.Sub(s.TimeStart).Seconds(),
+	}, nil
+}
@@ -0,0 +1,124 @@
+package tournament
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// play finishes m with the given numbers of correct answers and times.
+func play(m *Match, correct1 int, seconds1 float64, correct2 int, seconds2 float64) {
+	m.Result1 = &MatchScore{Correct: correct1, Total: 5, Seconds: seconds1}
+	m.Result2 = &MatchScore{Correct: correct2, Total: 5, Seconds: seconds2}
+	m.decide()
+}
+
+func TestNew(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "bracket.json")
+	if _, err := New(file, []string{"Ann"}); err == nil {
+		t.Error("New() with one player didn't fail")
+	}
+	if _, err := New(file, []string{"Ann", "Bob", "Ann"}); err == nil {
+		t.Error("New() with a player twice didn't fail")
+	}
+	if _, err := os.Stat(file); err == nil {
+		t.Error("a bracket that couldn't be made was saved")
+	}
+}
+
+func TestBracket(t *testing.T) {
+	tr, err := New(filepath.Join(t.TempDir(), "bracket.json"), []string{"Ann", "Bob", "Cat", "Dan", "Eve"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Eve gets a bye in the first round
+	if got := len(tr.Rounds[0]); got != 3 {
+		t.Fatalf("the first round has %v matches, want 3", got)
+	}
+	if bye := tr.Rounds[0][2]; bye.Player1 != "Eve" || bye.Winner != "Eve" {
+		t.Errorf("the last match is %+v, want a bye for Eve", bye)
+	}
+
+	m := tr.NextMatch()
+	if m.Player1 != "Ann" || m.Player2 != "Bob" {
+		t.Fatalf("the first match is %s vs %s, want Ann vs Bob", m.Player1, m.Player2)
+	}
+	play(m, 3, 20, 4, 30) // Bob gets more right
+	m = tr.NextMatch()
+	play(m, 4, 25, 4, 20) // Dan is as good but faster than Cat
+
+	// The second round pairs the winners and the bye
+	m = tr.NextMatch()
+	if len(tr.Rounds) != 2 || m.Player1 != "Bob" || m.Player2 != "Dan" {
+		t.Fatalf("the second round starts with %s vs %s, want Bob vs Dan", m.Player1, m.Player2)
+	}
+	if bye := tr.Rounds[1][1]; bye.Player1 != "Eve" || bye.Winner != "Eve" {
+		t.Errorf("the second round ends with %+v, want a bye for Eve", bye)
+	}
+	if tr.Champion() != "" {
+		t.Errorf("Champion() = %s before the final", tr.Champion())
+	}
+	play(m, 2, 10, 2, 10) // A tie goes to the higher seed
+
+	m = tr.NextMatch()
+	if m.Player1 != "Bob" || m.Player2 != "Eve" {
+		t.Fatalf("the final is %s vs %s, want Bob vs Eve", m.Player1, m.Player2)
+	}
+	play(m, 1, 10, 5, 60)
+	if m = tr.NextMatch(); m != nil {
+		t.Errorf("NextMatch() = %+v after the final", m)
+	}
+	if tr.Champion() != "Eve" {
+		t.Errorf("Champion() = %q, want Eve", tr.Champion())
+	}
+	if len(tr.Rounds) != 3 {
+		t.Errorf("the tournament took %v rounds, want 3", len(tr.Rounds))
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "bracket.json")
+	tr, err := New(file, []string{"Ann", "Bob", "Cat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	play(tr.NextMatch(), 3, 20, 4, 30)
+	if err = tr.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, tr) {
+		t.Errorf("Load() = %+v, want %+v", loaded, tr)
+	}
+	if m := loaded.NextMatch(); m == nil || m.Player1 != "Bob" || m.Player2 != "Cat" {
+		t.Errorf("the loaded tournament carries on with %+v, want Bob vs Cat", m)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("%v files were left in the directory, want only the bracket", len(files))
+	}
+
+	if err = os.WriteFile(file, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Load(file); err == nil {
+		t.Error("Load() of a broken bracket didn't fail")
+	}
+	if _, err = Load(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("Load() of a missing bracket = %v, want it not to exist", err)
+	}
+}
+
+func TestSaveFails(t *testing.T) {
+	tr := &Tournament{File: filepath.Join(t.TempDir(), "missing", "bracket.json"), Players: []string{"Ann", "Bob"}}
+	tr.addRound(tr.Players)
+	if err := tr.Save(); err == nil {
+		t.Error("Save() into a missing directory didn't fail")
+	}
+}
@@ -0,0 +1,219 @@
+// Package wikidata makes quiz questions from facts in Wikidata, such as
+// the capitals of countries, with SPARQL queries to its query service.
+package wikidata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rastewart/go-quiz-game/quiz"
+)
+
+// DefaultURL is Wikidata's SPARQL query service.
+const DefaultURL = "https://query.wikidata.org/sparql"
+
+// userAgent identifies the quiz to the query service, as Wikidata's
+// policy asks of every client.
+const userAgent = "go-quiz-game (https://github.com/rastewart/go-quiz-game)"
+
+// Preset is a ready made query for a kind of fact.  The query selects a
+// ?subjectLabel and an ?answerLabel, and {lang} in it is replaced by the
+// language of the labels.
+type Preset struct {
+	Name     string //Name given to -wikidata, e.g. "capitals"
+	Summary  string //What the questions are about, for the help
+	Query    string //SPARQL query for the facts
+	Template string //Question with {subject} where the subject goes
+	Category string //Category of the questions
+}
+
+// labels is the service that gives the items in a query their names.
+const labels = `SERVICE wikibase:label { bd:serviceParam wikibase:language "{lang},en". }`
+
+// Presets are the queries that can be used by name.
+var Presets = []Preset{
+	{
+		Name:    "capitals",
+		Summary: "capital cities of countries",
+		Query: `SELECT ?subjectLabel ?answerLabel WHERE {
+  ?subject wdt:P31 wd:Q6256; wdt:P36 ?answer.
+  ` + labels + `
+}`,
+		Template: "What is the capital of {subject}?",
+		Category: "Capitals",
+	},
+	{
+		Name:    "elements",
+		Summary: "symbols of the chemical elements",
+		Query: `SELECT ?subjectLabel (?symbol AS ?answerLabel) WHERE {
+  ?subject wdt:P31 wd:Q11344; wdt:P246 ?symbol.
+  ` + labels + `
+}`,
+		Template: "What is the chemical symbol for {subject}?",
+		Category: "Chemistry",
+	},
+	{
+		Name:    "birthyears",
+		Summary: "birth years of Nobel Prize winners in physics",
+		Query: `SELECT ?subjectLabel (YEAR(?born) AS ?answerLabel) WHERE {
+  ?subject wdt:P166 wd:Q38104; wdt:P569 ?born.
+  ` + labels + `
+}`,
+		Template: "In what year was {subject} born?",
+		Category: "Physicists",
+	},
+	{
+		Name:    "currencies",
+		Summary: "currencies of countries",
+		Query: `SELECT ?subjectLabel ?answerLabel WHERE {
+  ?subject wdt:P31 wd:Q6256; wdt:P38 ?answer.
+  ` + labels + `
+}`,
+		Template: "What is the currency of {subject}?",
+		Category: "Currencies",
+	},
+}
+
+// LookupPreset returns the preset called name.
+func LookupPreset(name string) (Preset, error) {
+	var names []string
+	for _, p := range Presets {
+		if p.Name == name {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+	return Preset{}, fmt.Errorf("there is no %q Wikidata query, use one of %s or give a query file", name, strings.Join(names, ", "))
+}
+
+// Fact is a subject and its answer, e.g. France and Paris.
+type Fact struct {
+	Subject string
+	Answer  string
+}
+
+// Client runs queries on a SPARQL query service.
+type Client struct {
+	URL    string //URL of the query service
+	client *http.Client
+}
+
+// NewClient creates a client for the query service at endpoint.
+func NewClient(endpoint string) *Client {
+	return &Client{URL: endpoint, client: &http.Client{Timeout: 90 * time.Second}}
+}
+
+// itemID matches the ID the label service gives an item with no label in
+// the language, e.g. "Q12345".
+var itemID = regexp.MustCompile(`^Q\d+$`)
+
+// Facts runs query, with {lang} replaced by lang, and returns the facts
+// it finds.  The query selects ?subjectLabel and ?answerLabel.  Subjects
+// with more than one answer, such as countries with several capitals,
+// are left out since the answer would be ambiguous, as are items with no
+// label in the language.
+func (c *Client) Facts(ctx context.Context, query, lang string) ([]Fact, error) {
+	query = strings.ReplaceAll(query, "{lang}", lang)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, strings.NewReader(url.Values{"query": {query}}.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/sparql-results+json")
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return nil, fmt.Errorf("the query service returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var results struct {
+		Results struct {
+			Bindings []map[string]struct {
+				Value string `json:"value"`
+			} `json:"bindings"`
+		} `json:"results"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("unable to read the query results: %w", err)
+	}
+
+	answers := make(map[string]map[string]bool)
+	for _, b := range results.Results.Bindings {
+		subject, answer := strings.TrimSpace(b["subjectLabel"].Value), strings.TrimSpace(b["answerLabel"].Value)
+		if subject == "" || answer == "" || itemID.MatchString(subject) || itemID.MatchString(answer) {
+			continue
+		}
+		if answers[subject] == nil {
+			answers[subject] = make(map[string]bool)
+		}
+		answers[subject][answer] = true
+	}
+	if len(results.Results.Bindings) > 0 && len(answers) == 0 {
+		return nil, fmt.Errorf("the query results have no ?subjectLabel and ?answerLabel")
+	}
+
+	var facts []Fact
+	for subject, a := range answers {
+		if len(a) != 1 {
+			continue
+		}
+		for answer := range a {
+			facts = append(facts, Fact{Subject: subject, Answer: answer})
+		}
+	}
+	// The map is sorted so the same seed picks the same questions
+	sort.Slice(facts, func(i, j int) bool { return facts[i].Subject < facts[j].Subject })
+	return facts, nil
+}
+
+// Questions makes up to count questions from facts, picked at random
+// with r, or from all of them if count is 0.  {subject} in template is
+// replaced by each fact's subject.  Each question gets up to choices
+// wrong choices, which are the answers of other facts.
+func Questions(facts []Fact, template, category string, count, choices int, r *rand.Rand) []quiz.Question {
+	picked := make([]Fact, len(facts))
+	copy(picked, facts)
+	r.Shuffle(len(picked), func(i, j int) { picked[i], picked[j] = picked[j], picked[i] })
+	if count > 0 && count < len(picked) {
+		picked = picked[:count]
+	}
+
+	// Every distinct answer can be a wrong choice for another question
+	seen := make(map[string]bool)
+	var pool []string
+	for _, f := range facts {
+		if !seen[f.Answer] {
+			seen[f.Answer] = true
+			pool = append(pool, f.Answer)
+		}
+	}
+
+	questions := make([]quiz.Question, 0, len(picked))
+	for _, f := range picked {
+		q := quiz.Question{QText: strings.ReplaceAll(template, "{subject}", f.Subject), Answer: f.Answer, Category: category}
+		for _, i := range r.Perm(len(pool)) {
+			if len(q.Choices) == choices {
+				break
+			}
+			if pool[i] != f.Answer {
+				q.Choices = append(q.Choices, pool[i])
+			}
+		}
+		questions = append(questions, q)
+	}
+	return questions
+}